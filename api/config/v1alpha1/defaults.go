@@ -0,0 +1,409 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"net"
+	"path"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	componentconfig "k8s.io/component-base/config/v1alpha1"
+)
+
+const (
+	DefaultMetricsBindAddress     = ":8080"
+	DefaultHealthProbeBindAddress = ":8081"
+	DefaultWebhookPort            = 9443
+	DefaultLeaderElectionID       = "d4987a89.cokeos.io"
+
+	DefaultRegistry      = "ccr.ccs.tencentyun.com/njupt-isl"
+	DefaultGPUModel      = "GTX-1660"
+	DefaultGPUModelLabel = "cokeos.io/gpu-model"
+	DefaultShmSize       = "32Gi"
+	DefaultWorkspaceRoot = "/data"
+	DefaultLabelKey      = "cokeos.io/zero-managed"
+	DefaultUniqLabelKey  = "cokeos.io/zero-id"
+
+	DefaultNodePortMin = 30000
+	DefaultNodePortMax = 31999
+
+	DefaultAddressMode = "NodeExternalIP"
+
+	DefaultGatewayImage       = "ccr.ccs.tencentyun.com/njupt-isl/zero:latest"
+	DefaultGatewayServiceType = v1.ServiceTypeLoadBalancer
+	DefaultGatewayPort        = 22
+
+	DefaultIdleSidecarPort = 9100
+
+	DefaultSnapshotBuilderImage  = "gcr.io/kaniko-project/executor:v1.6.0"
+	DefaultSnapshotExporterImage = "bitnami/kubectl:1.22"
+
+	DefaultSuccessfulUnitsHistoryLimit int32 = 3
+	DefaultFailedUnitsHistoryLimit     int32 = 1
+
+	DefaultUsageTimezone = "Local"
+
+	// DefaultSystemNamespace operator 所在的命名空间
+	DefaultSystemNamespace = "zero-system"
+
+	// DefaultTrustedUser 控制器的服务账号，由 Tiny 创建 Unit 与 Tunnel 时沿用 Tiny 的创建者
+	DefaultTrustedUser = "system:serviceaccount:zero-system:zero-controller-manager"
+)
+
+// 各控制器默认的同步间隔
+const (
+	DefaultUnitSyncPeriod     = time.Second * 10
+	DefaultTunnelSyncPeriod   = time.Second * 10
+	DefaultTinySyncPeriod     = time.Minute
+	DefaultGatewaySyncPeriod  = time.Minute
+	DefaultIdleSyncPeriod     = time.Minute
+	DefaultProjectSyncPeriod  = time.Minute
+	DefaultSnapshotSyncPeriod = time.Second * 10
+)
+
+// NewDefault 返回全部使用默认值的配置，与未指定配置文件时的行为一致
+func NewDefault() *ZeroConfig {
+	c := &ZeroConfig{}
+	c.Default()
+	return c
+}
+
+// Default 为未设置的字段填充默认值
+func (c *ZeroConfig) Default() {
+	c.APIVersion = GroupVersion.String()
+	c.Kind = "ZeroConfig"
+
+	if c.Metrics.BindAddress == "" {
+		c.Metrics.BindAddress = DefaultMetricsBindAddress
+	}
+	if c.Health.HealthProbeBindAddress == "" {
+		c.Health.HealthProbeBindAddress = DefaultHealthProbeBindAddress
+	}
+	if c.Webhook.Port == nil {
+		port := DefaultWebhookPort
+		c.Webhook.Port = &port
+	}
+	if c.LeaderElection == nil {
+		c.LeaderElection = &componentconfig.LeaderElectionConfiguration{}
+	}
+	if c.LeaderElection.ResourceName == "" {
+		c.LeaderElection.ResourceName = DefaultLeaderElectionID
+	}
+
+	if c.Unit.Registry == "" {
+		c.Unit.Registry = DefaultRegistry
+	}
+	if len(c.Unit.GPUModels) == 0 {
+		c.Unit.GPUModels = []string{DefaultGPUModel}
+	}
+	if c.Unit.GPUModelLabel == "" {
+		c.Unit.GPUModelLabel = DefaultGPUModelLabel
+	}
+	if c.Unit.ShmSize == nil {
+		size := resource.MustParse(DefaultShmSize)
+		c.Unit.ShmSize = &size
+	}
+	if c.Unit.WorkspaceRoot == "" {
+		c.Unit.WorkspaceRoot = DefaultWorkspaceRoot
+	}
+	if c.Unit.LabelKey == "" {
+		c.Unit.LabelKey = DefaultLabelKey
+	}
+	if c.Unit.UniqLabelKey == "" {
+		c.Unit.UniqLabelKey = DefaultUniqLabelKey
+	}
+
+	if c.Tiny.NodePorts == nil {
+		c.Tiny.NodePorts = &PortRange{Min: DefaultNodePortMin, Max: DefaultNodePortMax}
+	}
+
+	if c.NetworkPolicy.AllowedNamespaces == nil {
+		c.NetworkPolicy.AllowedNamespaces = []string{DefaultSystemNamespace}
+	}
+	if c.Tunnel.AddressMode == "" {
+		c.Tunnel.AddressMode = DefaultAddressMode
+	}
+	if c.Gateway.Namespace == "" {
+		c.Gateway.Namespace = DefaultSystemNamespace
+	}
+	if c.Gateway.Image == "" {
+		c.Gateway.Image = DefaultGatewayImage
+	}
+	if c.Gateway.ServiceType == "" {
+		c.Gateway.ServiceType = DefaultGatewayServiceType
+	}
+	if c.Gateway.Port == 0 {
+		c.Gateway.Port = DefaultGatewayPort
+	}
+	if c.Idle.SidecarPort == 0 {
+		c.Idle.SidecarPort = DefaultIdleSidecarPort
+	}
+	if c.Snapshot.Registry == "" {
+		c.Snapshot.Registry = DefaultRegistry
+	}
+	if c.Snapshot.BuilderImage == "" {
+		c.Snapshot.BuilderImage = DefaultSnapshotBuilderImage
+	}
+	if c.Snapshot.ExporterImage == "" {
+		c.Snapshot.ExporterImage = DefaultSnapshotExporterImage
+	}
+	if c.Snapshot.LabelKey == "" {
+		c.Snapshot.LabelKey = DefaultLabelKey
+	}
+	if c.Project.LabelKey == "" {
+		c.Project.LabelKey = DefaultLabelKey
+	}
+	defaultInt32(&c.ScheduledUnit.SuccessfulUnitsHistoryLimit, DefaultSuccessfulUnitsHistoryLimit)
+	defaultInt32(&c.ScheduledUnit.FailedUnitsHistoryLimit, DefaultFailedUnitsHistoryLimit)
+	if c.Usage.Namespace == "" {
+		c.Usage.Namespace = DefaultSystemNamespace
+	}
+	if c.Usage.Timezone == "" {
+		c.Usage.Timezone = DefaultUsageTimezone
+	}
+	if c.LogArchive.Root == "" {
+		c.LogArchive.Root = c.Unit.WorkspaceRoot
+	}
+
+	if c.Admission.TrustedUsers == nil {
+		c.Admission.TrustedUsers = []string{DefaultTrustedUser}
+	}
+	if c.Admission.ReservedNamespaces == nil {
+		c.Admission.ReservedNamespaces = []string{"default", DefaultSystemNamespace}
+	}
+
+	defaultDuration(&c.SyncPeriods.Unit, DefaultUnitSyncPeriod)
+	defaultDuration(&c.SyncPeriods.Tunnel, DefaultTunnelSyncPeriod)
+	defaultDuration(&c.SyncPeriods.Tiny, DefaultTinySyncPeriod)
+	defaultDuration(&c.SyncPeriods.Gateway, DefaultGatewaySyncPeriod)
+	defaultDuration(&c.SyncPeriods.Idle, DefaultIdleSyncPeriod)
+	defaultDuration(&c.SyncPeriods.Project, DefaultProjectSyncPeriod)
+	defaultDuration(&c.SyncPeriods.Snapshot, DefaultSnapshotSyncPeriod)
+}
+
+func defaultDuration(d **metav1.Duration, value time.Duration) {
+	if *d == nil {
+		*d = &metav1.Duration{Duration: value}
+	}
+}
+
+func defaultInt32(i **int32, value int32) {
+	if *i == nil {
+		*i = &value
+	}
+}
+
+// Validate 校验填充默认值后的配置
+func (c *ZeroConfig) Validate() error {
+	var errs field.ErrorList
+
+	unit := field.NewPath("unit")
+	for i, model := range c.Unit.GPUModels {
+		if model == "" {
+			errs = append(errs, field.Required(unit.Child("gpuModels").Index(i), "must not be empty"))
+		}
+	}
+	for _, msg := range validation.IsQualifiedName(c.Unit.GPUModelLabel) {
+		errs = append(errs, field.Invalid(unit.Child("gpuModelLabel"), c.Unit.GPUModelLabel, msg))
+	}
+	for _, msg := range validation.IsQualifiedName(c.Unit.LabelKey) {
+		errs = append(errs, field.Invalid(unit.Child("labelKey"), c.Unit.LabelKey, msg))
+	}
+	for _, msg := range validation.IsQualifiedName(c.Unit.UniqLabelKey) {
+		errs = append(errs, field.Invalid(unit.Child("uniqLabelKey"), c.Unit.UniqLabelKey, msg))
+	}
+	if c.Unit.LabelKey == c.Unit.UniqLabelKey {
+		errs = append(errs, field.Invalid(unit.Child("uniqLabelKey"), c.Unit.UniqLabelKey, "must differ from labelKey"))
+	}
+	if c.Unit.ShmSize.Sign() <= 0 {
+		errs = append(errs, field.Invalid(unit.Child("shmSize"), c.Unit.ShmSize.String(), "must be positive"))
+	}
+	if !path.IsAbs(c.Unit.WorkspaceRoot) {
+		errs = append(errs, field.Invalid(unit.Child("workspaceRoot"), c.Unit.WorkspaceRoot, "must be an absolute path"))
+	}
+	errs = append(errs, validateSecurity(&c.Unit.Security, unit.Child("security"))...)
+
+	ports := field.NewPath("tiny", "nodePorts")
+	if r := c.Tiny.NodePorts; r.Min < 1 || r.Max > 65535 || r.Min > r.Max {
+		errs = append(errs, field.Invalid(ports, *r, "must be a non-empty range within 1-65535"))
+	}
+
+	policy := field.NewPath("networkPolicy")
+	for i, cidr := range c.NetworkPolicy.NodeCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, field.Invalid(policy.Child("nodeCIDRs").Index(i), cidr, "must be a CIDR"))
+		}
+	}
+
+	switch c.Tunnel.AddressMode {
+	case "NodeExternalIP", "LoadBalancer":
+	case "Gateway":
+		if c.Gateway.Host == "" {
+			errs = append(errs, field.Required(field.NewPath("gateway", "host"), "must be set in Gateway address mode"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(field.NewPath("tunnel", "addressMode"), c.Tunnel.AddressMode,
+			[]string{"NodeExternalIP", "Gateway", "LoadBalancer"}))
+	}
+
+	gateway := field.NewPath("gateway")
+	if c.Gateway.SSH && c.Gateway.Host == "" {
+		errs = append(errs, field.Required(gateway.Child("host"), "must be set when the SSH gateway is enabled"))
+	}
+	switch c.Gateway.ServiceType {
+	case v1.ServiceTypeClusterIP, v1.ServiceTypeNodePort, v1.ServiceTypeLoadBalancer:
+	default:
+		errs = append(errs, field.NotSupported(gateway.Child("serviceType"), c.Gateway.ServiceType,
+			[]string{string(v1.ServiceTypeClusterIP), string(v1.ServiceTypeNodePort), string(v1.ServiceTypeLoadBalancer)}))
+	}
+	if c.Gateway.Port < 1 || c.Gateway.Port > 65535 {
+		errs = append(errs, field.Invalid(gateway.Child("port"), c.Gateway.Port, "must be within 1-65535"))
+	}
+
+	idle := field.NewPath("idle")
+	switch c.Idle.MetricsSource {
+	case "", "sidecar":
+	case "prometheus":
+		if c.Idle.PrometheusAddress == "" {
+			errs = append(errs, field.Required(idle.Child("prometheusAddress"), "must be set for the prometheus source"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(idle.Child("metricsSource"), c.Idle.MetricsSource, []string{"prometheus", "sidecar"}))
+	}
+	if c.Idle.SidecarPort < 1 || c.Idle.SidecarPort > 65535 {
+		errs = append(errs, field.Invalid(idle.Child("sidecarPort"), c.Idle.SidecarPort, "must be within 1-65535"))
+	}
+
+	for _, key := range []struct {
+		path  *field.Path
+		value string
+	}{
+		{field.NewPath("snapshot", "labelKey"), c.Snapshot.LabelKey},
+		{field.NewPath("project", "labelKey"), c.Project.LabelKey},
+	} {
+		for _, msg := range validation.IsQualifiedName(key.value) {
+			errs = append(errs, field.Invalid(key.path, key.value, msg))
+		}
+	}
+
+	scheduled := field.NewPath("scheduledUnit")
+	if *c.ScheduledUnit.SuccessfulUnitsHistoryLimit < 0 {
+		errs = append(errs, field.Invalid(scheduled.Child("successfulUnitsHistoryLimit"),
+			*c.ScheduledUnit.SuccessfulUnitsHistoryLimit, "must not be negative"))
+	}
+	if *c.ScheduledUnit.FailedUnitsHistoryLimit < 0 {
+		errs = append(errs, field.Invalid(scheduled.Child("failedUnitsHistoryLimit"),
+			*c.ScheduledUnit.FailedUnitsHistoryLimit, "must not be negative"))
+	}
+
+	usage := field.NewPath("usage")
+	switch c.Usage.Sink {
+	case "", "configmap":
+	case "sqlite", "csv":
+		if c.Usage.Path == "" {
+			errs = append(errs, field.Required(usage.Child("path"), "must be set for the "+c.Usage.Sink+" sink"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(usage.Child("sink"), c.Usage.Sink, []string{"configmap", "sqlite", "csv"}))
+	}
+	if _, err := time.LoadLocation(c.Usage.Timezone); err != nil {
+		errs = append(errs, field.Invalid(usage.Child("timezone"), c.Usage.Timezone, err.Error()))
+	}
+
+	archive := field.NewPath("logArchive")
+	switch c.LogArchive.Store {
+	case "":
+	case "workspace":
+		if !path.IsAbs(c.LogArchive.Root) {
+			errs = append(errs, field.Invalid(archive.Child("root"), c.LogArchive.Root, "must be an absolute path"))
+		}
+	case "s3":
+		if c.LogArchive.S3.Endpoint == "" {
+			errs = append(errs, field.Required(archive.Child("s3", "endpoint"), "must be set for the s3 store"))
+		}
+		if c.LogArchive.S3.Bucket == "" {
+			errs = append(errs, field.Required(archive.Child("s3", "bucket"), "must be set for the s3 store"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(archive.Child("store"), c.LogArchive.Store, []string{"workspace", "s3"}))
+	}
+
+	for i, user := range c.Admission.TrustedUsers {
+		if user == "" {
+			errs = append(errs, field.Required(field.NewPath("admission", "trustedUsers").Index(i), "must not be empty"))
+		}
+	}
+
+	periods := field.NewPath("syncPeriods")
+	for _, period := range []struct {
+		name     string
+		duration *metav1.Duration
+	}{
+		{"unit", c.SyncPeriods.Unit},
+		{"tunnel", c.SyncPeriods.Tunnel},
+		{"tiny", c.SyncPeriods.Tiny},
+		{"gateway", c.SyncPeriods.Gateway},
+		{"idle", c.SyncPeriods.Idle},
+		{"project", c.SyncPeriods.Project},
+		{"snapshot", c.SyncPeriods.Snapshot},
+	} {
+		if period.duration.Duration < time.Second {
+			errs = append(errs, field.Invalid(periods.Child(period.name), period.duration.Duration.String(), "must be at least 1s"))
+		}
+	}
+	return errs.ToAggregate()
+}
+
+func validateSecurity(security *corev1.UnitSecurity, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, id := range []struct {
+		name  string
+		value *int64
+	}{
+		{"runAsUser", security.RunAsUser},
+		{"runAsGroup", security.RunAsGroup},
+		{"fsGroup", security.FSGroup},
+	} {
+		if id.value != nil && *id.value < 0 {
+			errs = append(errs, field.Invalid(path.Child(id.name), *id.value, "must not be negative"))
+		}
+	}
+	for i, capability := range security.DropCapabilities {
+		if capability == "" {
+			errs = append(errs, field.Required(path.Child("dropCapabilities").Index(i), "must not be empty"))
+		}
+	}
+	if profile := security.SeccompProfile; profile != nil {
+		switch profile.Type {
+		case v1.SeccompProfileTypeRuntimeDefault, v1.SeccompProfileTypeUnconfined:
+		case v1.SeccompProfileTypeLocalhost:
+			if profile.LocalhostProfile == nil || *profile.LocalhostProfile == "" {
+				errs = append(errs, field.Required(path.Child("seccompProfile", "localhostProfile"), "must be set for Localhost"))
+			}
+		default:
+			errs = append(errs, field.NotSupported(path.Child("seccompProfile", "type"), profile.Type,
+				[]string{string(v1.SeccompProfileTypeRuntimeDefault), string(v1.SeccompProfileTypeUnconfined), string(v1.SeccompProfileTypeLocalhost)}))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cfg "sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
+)
+
+// UnitConfig 生成 Unit Pod 使用的参数，修改后需要重启
+//
+// 新的参数只用于之后创建的 Pod，已运行的 Pod 保持不变，直到 Unit 的规格变化或被挂起后恢复。
+type UnitConfig struct {
+	// Registry 框架镜像所在的仓库，镜像为 <registry>/<framework>-gpu|cpu:<version>
+	Registry string `json:"registry,omitempty"`
+	// GPUModels 未指定 GPU 型号时可调度的型号
+	GPUModels []string `json:"gpuModels,omitempty"`
+	// GPUModelLabel 节点上标记 GPU 型号的标签
+	GPUModelLabel string `json:"gpuModelLabel,omitempty"`
+	// ShmSize 未指定时 /dev/shm 的大小
+	ShmSize *resource.Quantity `json:"shmSize,omitempty"`
+	// WorkspaceRoot 节点上共享工作区的根目录，每个命名空间使用其下的同名目录
+	WorkspaceRoot string `json:"workspaceRoot,omitempty"`
+	// LabelKey 标记由 Zero 管理的 Unit Pod 的标签，项目的网络策略据此跳过 Unit Pod
+	//
+	// 标签键只应在安装时设置，修改后已有的 Pod 不再被控制器与网络策略识别。
+	LabelKey string `json:"labelKey,omitempty"`
+	// UniqLabelKey 以 <namespace>.<name> 标识 Unit Pod 的标签，Tunnel 的 Service 与 Unit 的网络策略据此选择 Pod，
+	// 同样只应在安装时设置
+	UniqLabelKey string `json:"uniqLabelKey,omitempty"`
+	// Security Unit 未设置时使用的安全选项
+	Security corev1.UnitSecurity `json:"security,omitempty"`
+	// EnforceSecurity 为 true 时 Security 中设置的字段覆盖 Unit 的取值，移除的 capabilities 取并集
+	EnforceSecurity bool `json:"enforceSecurity,omitempty"`
+}
+
+// PortRange 端口范围，包含 Min 与 Max
+type PortRange struct {
+	Min int32 `json:"min"`
+	Max int32 `json:"max"`
+}
+
+// Size 范围内的端口数
+func (r PortRange) Size() int32 {
+	return r.Max - r.Min + 1
+}
+
+// TinyConfig Tiny 的参数，修改后需要重启
+type TinyConfig struct {
+	// NodePorts 分配给 Tiny SSH 的 NodePort 范围，需要在 kube-apiserver 的 --service-node-port-range 内
+	NodePorts *PortRange `json:"nodePorts,omitempty"`
+}
+
+// NetworkPolicyConfig Unit 网络策略的参数，修改后需要重启
+type NetworkPolicyConfig struct {
+	// Disabled 不为 Unit 生成网络策略
+	Disabled bool `json:"disabled,omitempty"`
+	// AllowedNamespaces 允许访问 Unit 的命名空间，例如 SSH 网关与 Ingress Controller 所在的命名空间
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+	// NodeCIDRs NodePort 与 LoadBalancer 流量经节点转发后的来源网段，为空时使用各节点的 InternalIP 与 PodCIDR，并随节点增删更新
+	NodeCIDRs []string `json:"nodeCIDRs,omitempty"`
+}
+
+// TunnelConfig Tunnel 对外地址的参数，修改后需要重启
+type TunnelConfig struct {
+	// AddressMode 对外地址的选取方式，NodeExternalIP、Gateway 或 LoadBalancer
+	AddressMode string `json:"addressMode,omitempty"`
+	// LoadBalancerVIP LoadBalancer 模式下的负载均衡 VIP，为空时使用 Service 的 LoadBalancer 地址
+	LoadBalancerVIP string `json:"loadBalancerVIP,omitempty"`
+}
+
+// GatewayConfig 网关的参数，修改后需要重启
+type GatewayConfig struct {
+	// Host 网关的主机名，Gateway 地址模式下作为 Tunnel 的地址，也是 SSH 网关的地址
+	Host string `json:"host,omitempty"`
+	// SSH 部署 SSH 网关，Tiny 经网关登录而不是各自占用一个 NodePort
+	SSH bool `json:"ssh,omitempty"`
+	// Namespace SSH 网关所在的命名空间
+	Namespace string `json:"namespace,omitempty"`
+	// Image SSH 网关的镜像
+	Image string `json:"image,omitempty"`
+	// ServiceType SSH 网关 Service 的类型
+	ServiceType v1.ServiceType `json:"serviceType,omitempty"`
+	// Port SSH 网关对外的端口
+	Port int32 `json:"port,omitempty"`
+}
+
+// IdleConfig 空闲检测的参数，修改后需要重启
+type IdleConfig struct {
+	// MetricsSource 活动指标的来源，prometheus 或 sidecar，为空时不检测空闲
+	MetricsSource string `json:"metricsSource,omitempty"`
+	// PrometheusAddress prometheus 来源的服务地址
+	PrometheusAddress string `json:"prometheusAddress,omitempty"`
+	// SidecarPort sidecar 来源的端口
+	SidecarPort int32 `json:"sidecarPort,omitempty"`
+}
+
+// SnapshotConfig 快照的参数，修改后需要重启
+type SnapshotConfig struct {
+	// Registry 未指定目标镜像时推送到的仓库
+	Registry string `json:"registry,omitempty"`
+	// BuilderImage 构建镜像使用的 kaniko 镜像
+	BuilderImage string `json:"builderImage,omitempty"`
+	// ExporterImage 导出 Unit 文件使用的 kubectl 镜像
+	ExporterImage string `json:"exporterImage,omitempty"`
+	// LabelKey 标记快照任务及其 ServiceAccount、Role 的标签，只应在安装时设置
+	LabelKey string `json:"labelKey,omitempty"`
+}
+
+// ProjectConfig Project 的参数，修改后需要重启
+type ProjectConfig struct {
+	// LabelKey 标记由 Project 管理的命名空间的标签，只应在安装时设置
+	LabelKey string `json:"labelKey,omitempty"`
+}
+
+// ScheduledUnitConfig ScheduledUnit 的参数，修改后需要重启
+type ScheduledUnitConfig struct {
+	// SuccessfulUnitsHistoryLimit ScheduledUnit 未设置时保留的成功 Unit 数
+	SuccessfulUnitsHistoryLimit *int32 `json:"successfulUnitsHistoryLimit,omitempty"`
+	// FailedUnitsHistoryLimit ScheduledUnit 未设置时保留的失败 Unit 数
+	FailedUnitsHistoryLimit *int32 `json:"failedUnitsHistoryLimit,omitempty"`
+}
+
+// UsageConfig 用量统计的参数，修改后需要重启
+type UsageConfig struct {
+	// Sink 用量区间的存储位置，configmap、sqlite 或 csv，为空时不统计用量
+	Sink string `json:"sink,omitempty"`
+	// Path sqlite 与 csv 存储使用的文件
+	Path string `json:"path,omitempty"`
+	// Namespace configmap 存储所在的命名空间
+	Namespace string `json:"namespace,omitempty"`
+	// Timezone 按日、按月汇总使用的时区，例如 Asia/Shanghai
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// AuditConfig 审计的参数，修改后需要重启
+type AuditConfig struct {
+	// Sink Unit、Tiny 与 Tunnel 变更记录的去向：stdout、JSON lines 文件或 http(s) webhook 地址，为空时不审计
+	Sink string `json:"sink,omitempty"`
+}
+
+// S3Config S3 兼容对象存储的参数，凭据从环境变量 AWS_ACCESS_KEY_ID 与 AWS_SECRET_ACCESS_KEY 读取
+type S3Config struct {
+	// Endpoint 对象存储的地址，例如 minio:9000
+	Endpoint string `json:"endpoint,omitempty"`
+	Bucket   string `json:"bucket,omitempty"`
+	Prefix   string `json:"prefix,omitempty"`
+	Region   string `json:"region,omitempty"`
+	// Insecure 使用 HTTP 连接
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// LogArchiveConfig 日志归档的参数，修改后需要重启
+type LogArchiveConfig struct {
+	// Store 删除 Unit Pod 前归档日志的位置，workspace 或 s3，为空时不归档
+	Store string `json:"store,omitempty"`
+	// Root 控制器中共享工作区根目录的挂载路径，workspace 归档使用，默认为 unit.workspaceRoot
+	Root string `json:"root,omitempty"`
+	// S3 s3 归档的对象存储
+	S3 S3Config `json:"s3,omitempty"`
+}
+
+// AdmissionConfig 准入 webhook 的参数，修改后热加载
+type AdmissionConfig struct {
+	// AllowedRegistries 自定义镜像允许的仓库或仓库前缀，为空时不限制
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+	// TrustedUsers 可以代替其他用户设置 cokeos.io/created-by 注解的用户
+	TrustedUsers []string `json:"trustedUsers,omitempty"`
+	// ReservedNamespaces Project 不能使用的命名空间，kube- 开头的命名空间总是保留
+	ReservedNamespaces []string `json:"reservedNamespaces,omitempty"`
+}
+
+// SyncPeriods 各控制器周期同步的间隔，修改后热加载，下一次同步起生效
+type SyncPeriods struct {
+	// Unit 同步 Pod 状态与重新入队的间隔
+	Unit *metav1.Duration `json:"unit,omitempty"`
+	// Tunnel 同步 Service 的间隔
+	Tunnel *metav1.Duration `json:"tunnel,omitempty"`
+	// Tiny 同步 Tiny 与 NodePort 占用的间隔
+	Tiny *metav1.Duration `json:"tiny,omitempty"`
+	// Gateway 同步 SSH 网关的间隔
+	Gateway *metav1.Duration `json:"gateway,omitempty"`
+	// Idle 检测空闲 Unit 的间隔
+	Idle *metav1.Duration `json:"idle,omitempty"`
+	// Project 刷新 Project 用量的间隔
+	Project *metav1.Duration `json:"project,omitempty"`
+	// Snapshot 等待快照任务完成时重新入队的间隔
+	Snapshot *metav1.Duration `json:"snapshot,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ZeroConfig is the Schema for the configuration file of the Zero controller manager
+//
+// 只有 Admission 与 SyncPeriods 热加载，其余字段修改后需要重启。
+type ZeroConfig struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// ControllerManagerConfigurationSpec 指标、健康检查、webhook 与选主等 Manager 参数
+	cfg.ControllerManagerConfigurationSpec `json:",inline"`
+
+	Unit          UnitConfig          `json:"unit,omitempty"`
+	Tiny          TinyConfig          `json:"tiny,omitempty"`
+	NetworkPolicy NetworkPolicyConfig `json:"networkPolicy,omitempty"`
+	Tunnel        TunnelConfig        `json:"tunnel,omitempty"`
+	Gateway       GatewayConfig       `json:"gateway,omitempty"`
+	Idle          IdleConfig          `json:"idle,omitempty"`
+	Snapshot      SnapshotConfig      `json:"snapshot,omitempty"`
+	Project       ProjectConfig       `json:"project,omitempty"`
+	ScheduledUnit ScheduledUnitConfig `json:"scheduledUnit,omitempty"`
+	Usage         UsageConfig         `json:"usage,omitempty"`
+	Audit         AuditConfig         `json:"audit,omitempty"`
+	LogArchive    LogArchiveConfig    `json:"logArchive,omitempty"`
+	Admission     AdmissionConfig     `json:"admission,omitempty"`
+	SyncPeriods   SyncPeriods         `json:"syncPeriods,omitempty"`
+}
+
+// Complete 实现 config.ControllerManagerConfiguration，供 ctrl.Options.AndFrom 读取 Manager 参数
+func (c *ZeroConfig) Complete() (cfg.ControllerManagerConfigurationSpec, error) {
+	return c.ControllerManagerConfigurationSpec, nil
+}
+
+func init() {
+	SchemeBuilder.Register(&ZeroConfig{})
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ProjectLabelKey 标记命名空间所属的 Project
+	ProjectLabelKey = "cokeos.io/project"
+	// ProjectAdoptAnnotation 管理员在已有命名空间上将其设为 Project 名称后，该 Project 才能使用此命名空间
+	ProjectAdoptAnnotation = "cokeos.io/adopt-by-project"
+
+	// ProjectReady 命名空间及其权限、配额等资源均已就绪
+	ProjectReady = "Ready"
+
+	// WorkspaceClaimName 项目工作区的 PVC，存在时 Unit 挂载它作为工作目录
+	WorkspaceClaimName = "workspace"
+)
+
+type ProjectRole string
+
+const (
+	// ProjectOwner 可管理命名空间内的全部资源
+	ProjectOwner ProjectRole = "Owner"
+	// ProjectMember 可创建和管理 Unit 与 Tiny
+	ProjectMember ProjectRole = "Member"
+	// ProjectViewer 只能查看 Unit 与 Tiny
+	ProjectViewer ProjectRole = "Viewer"
+)
+
+// ProjectStorage 项目的工作区存储
+type ProjectStorage struct {
+	// Size 工作区大小
+	Size resource.Quantity `json:"size"`
+	// StorageClassName 存储类，为空时使用集群默认存储类
+	StorageClassName *string `json:"storageClassName,omitempty"`
+}
+
+// ProjectSpec defines the desired state of Project
+type ProjectSpec struct {
+	// Namespace 项目使用的命名空间，默认与 Project 同名，创建后不能修改
+	//
+	// 命名空间不存在时由控制器创建并随 Project 删除；已存在的命名空间需要管理员设置
+	// cokeos.io/adopt-by-project 注解后才能使用，删除 Project 时只移除其中的权限、配额等资源。
+	//+optional
+	Namespace string `json:"namespace,omitempty"`
+	// Owners 项目所有者
+	Owners []rbacv1.Subject `json:"owners,omitempty"`
+	// Members 项目成员
+	Members []rbacv1.Subject `json:"members,omitempty"`
+	// Viewers 项目观察者
+	Viewers []rbacv1.Subject `json:"viewers,omitempty"`
+	// Quota 命名空间的资源配额，为空时使用控制器的默认配额
+	Quota v1.ResourceList `json:"quota,omitempty"`
+	// NetworkIsolation 是否只允许同一命名空间内的访问，默认开启
+	//+optional
+	NetworkIsolation *bool `json:"networkIsolation,omitempty"`
+	// Storage 工作区存储，为空时 Unit 使用节点上的共享目录
+	Storage *ProjectStorage `json:"storage,omitempty"`
+}
+
+type ProjectMemberStatus struct {
+	// Kind User、Group 或 ServiceAccount
+	Kind string `json:"kind"`
+	// Name 成员名称
+	Name string `json:"name"`
+	// Role 成员在项目中的角色
+	Role ProjectRole `json:"role"`
+}
+
+// ProjectStatus defines the observed state of Project
+type ProjectStatus struct {
+	// Namespace 项目使用的命名空间
+	Namespace string `json:"namespace,omitempty"`
+	// Members 项目成员及其角色
+	Members []ProjectMemberStatus `json:"members,omitempty"`
+	// Quota 命名空间的资源配额
+	Quota v1.ResourceList `json:"quota,omitempty"`
+	// Used 命名空间已使用的资源
+	Used v1.ResourceList `json:"used,omitempty"`
+	// Units 命名空间中的 Unit 数量
+	Units int32 `json:"units"`
+	// Tinies 命名空间中的 Tiny 数量
+	Tinies int32 `json:"tinies"`
+	// Conditions 项目状态
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// Project is the Schema for the projects API
+type Project struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProjectSpec   `json:"spec,omitempty"`
+	Status ProjectStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ProjectList contains a list of Project
+type ProjectList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Project `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Project{}, &ProjectList{})
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConcurrencyPolicy 上一次创建的 Unit 仍在运行时如何处理新的调度
+// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent 同时运行多个 Unit
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+	// ForbidConcurrent 跳过本次调度
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+	// ReplaceConcurrent 删除仍在运行的 Unit 后创建新的 Unit
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+const (
+	// ScheduledUnitLabel 标记由 ScheduledUnit 创建的 Unit，值为 ScheduledUnit 的名称
+	ScheduledUnitLabel = "cokeos.io/scheduled-unit"
+	// ScheduledTimeAnnotation Unit 对应的调度时间，RFC3339 格式
+	ScheduledTimeAnnotation = "cokeos.io/scheduled-time"
+)
+
+// UnitTemplateSpec 创建 Unit 使用的模板
+type UnitTemplateSpec struct {
+	// Labels 复制到创建的 Unit
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations 复制到创建的 Unit
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	Spec UnitSpec `json:"spec"`
+}
+
+// ScheduledUnitSpec defines the desired state of ScheduledUnit
+type ScheduledUnitSpec struct {
+	// Schedule cron 表达式，例如 "0 2 * * *"，也支持 @daily 等描述符
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+	// TimeZone 解释 Schedule 使用的 IANA 时区，例如 Asia/Shanghai，为空时使用 UTC
+	TimeZone string `json:"timeZone,omitempty"`
+	// StartingDeadlineSeconds 错过调度时间超过该秒数后不再补建，为空时不限
+	// +kubebuilder:validation:Minimum=0
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// ConcurrencyPolicy 上一次的 Unit 仍在运行时的处理方式
+	// +kubebuilder:default=Allow
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// Suspend 暂停调度，不影响已创建的 Unit
+	Suspend bool `json:"suspend,omitempty"`
+	// UnitTemplate 每次调度创建的 Unit
+	UnitTemplate UnitTemplateSpec `json:"unitTemplate"`
+	// SuccessfulUnitsHistoryLimit 保留的成功 Unit 数
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=3
+	SuccessfulUnitsHistoryLimit *int32 `json:"successfulUnitsHistoryLimit,omitempty"`
+	// FailedUnitsHistoryLimit 保留的失败 Unit 数
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	FailedUnitsHistoryLimit *int32 `json:"failedUnitsHistoryLimit,omitempty"`
+}
+
+// ScheduledUnitStatus defines the observed state of ScheduledUnit
+type ScheduledUnitStatus struct {
+	// Active 仍在运行的 Unit
+	Active []string `json:"active,omitempty"`
+	// LastScheduleTime 最近一次创建 Unit 的调度时间
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// LastSuccessfulTime 最近一次成功结束的 Unit 的调度时间
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+}
+
+//+genclient
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// ScheduledUnit is the Schema for the scheduledunits API
+//
+// 按 cron 表达式定期创建 Unit，名称为 <name>-<调度时间 200601021504>。
+type ScheduledUnit struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScheduledUnitSpec   `json:"spec,omitempty"`
+	Status ScheduledUnitStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ScheduledUnitList contains a list of ScheduledUnit
+type ScheduledUnitList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ScheduledUnit `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ScheduledUnit{}, &ScheduledUnitList{})
+}
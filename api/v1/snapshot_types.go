@@ -0,0 +1,80 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type SnapshotPhase string
+
+const (
+	SnapshotPending   SnapshotPhase = "Pending"
+	SnapshotRunning   SnapshotPhase = "Running"
+	SnapshotSucceeded SnapshotPhase = "Succeeded"
+	SnapshotFailed    SnapshotPhase = "Failed"
+)
+
+// SnapshotSpec defines the desired state of Snapshot
+type SnapshotSpec struct {
+	// UnitName 要提交的 Unit，与 Snapshot 位于同一命名空间
+	UnitName string `json:"unitName"`
+	// Image 目标镜像，为空时使用 <registry>/<namespace>/<unitName>:<snapshotName>
+	Image string `json:"image,omitempty"`
+	// Path 只提交该路径下的文件，为空时提交整个容器文件系统
+	Path string `json:"path,omitempty"`
+	// PushSecretName 推送镜像使用的 dockerconfigjson Secret
+	PushSecretName string `json:"pushSecretName,omitempty"`
+}
+
+// SnapshotStatus defines the observed state of Snapshot
+type SnapshotStatus struct {
+	Phase SnapshotPhase `json:"phase,omitempty"`
+	// Image 构建完成的镜像，推送成功时带 digest，可作为新 Unit 的镜像
+	Image string `json:"image,omitempty"`
+	// BaseImage 被提交的 Unit 容器镜像
+	BaseImage string `json:"baseImage,omitempty"`
+	// JobName 构建任务
+	JobName        string             `json:"jobName,omitempty"`
+	CompletionTime *metav1.Time       `json:"completionTime,omitempty"`
+	Conditions     []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// Snapshot is the Schema for the snapshots API
+type Snapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SnapshotSpec   `json:"spec,omitempty"`
+	Status SnapshotStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SnapshotList contains a list of Snapshot
+type SnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Snapshot `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Snapshot{}, &SnapshotList{})
+}
@@ -23,18 +23,50 @@ import (
 
 // TinySpec defines the desired state of Tiny
 type TinySpec struct {
-	GPU       bool      `json:"gpu"`
+	GPU bool `json:"gpu"`
+	// GPUNumber GPU 为 true 时覆盖 Profile 中的 GPU 数量
+	GPUNumber int32 `json:"gpuNumber,omitempty"`
+	// GPUModel GPU 为 true 时覆盖 Profile 中的 GPU 型号
+	GPUModel  string    `json:"gpuModel,omitempty"`
 	Framework Framework `json:"framework"`
+	// Profile 使用的 TinyProfile，为空时使用默认 Profile
+	Profile string `json:"profile,omitempty"`
+	// IdlePolicy 空闲检测策略，透传给 Unit
+	IdlePolicy *IdlePolicy `json:"idlePolicy,omitempty"`
 }
 
+const (
+	// TinyUnitReady Unit 已运行
+	TinyUnitReady = "UnitReady"
+	// TinyTunnelReady Tunnel 的 Service 已创建
+	TinyTunnelReady = "TunnelReady"
+	// TinyReady Unit 与 Tunnel 均就绪，可以连接
+	TinyReady = "Ready"
+	// TinyProfileReady 引用的 TinyProfile 存在
+	TinyProfileReady = "ProfileReady"
+)
+
 // TinyStatus defines the observed state of Tiny
 type TinyStatus struct {
 	Phase    v1.PodPhase `json:"phase,omitempty"`
 	NodePort int32       `json:"nodePort,omitempty"`
+	// Profile 实际使用的 TinyProfile
+	Profile    string             `json:"profile,omitempty"`
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Host SSH 连接的地址
+	Host string `json:"host,omitempty"`
+	// Endpoint SSH 连接的 host:port
+	Endpoint string `json:"endpoint,omitempty"`
+	// SSHCommand 可直接使用的 SSH 连接命令
+	SSHCommand string `json:"sshCommand,omitempty"`
+	// CreatedBy 创建 Tiny 的用户，来自 cokeos.io/created-by 注解
+	CreatedBy string `json:"createdBy,omitempty"`
 }
 
+//+genclient
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 
 // Tiny is the Schema for the tinies API
 type Tiny struct {
@@ -0,0 +1,66 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultTinyProfileAnnotation 标记未指定 Profile 的 Tiny 使用的默认规格
+	DefaultTinyProfileAnnotation = "cokeos.io/default-profile"
+)
+
+// TinyProfileSpec defines the desired state of TinyProfile
+type TinyProfileSpec struct {
+	// ResourceList 资源配额
+	ResourceList v1.ResourceList `json:"resourceList"`
+	// GPUModel GPU 型号，为空时使用默认型号
+	GPUModel string `json:"gpuModel,omitempty"`
+	// GPUNumber 启用 GPU 时的 GPU 数量，默认 1
+	GPUNumber int `json:"gpuNumber,omitempty"`
+	// ShmSize 共享内存大小
+	ShmSize *resource.Quantity `json:"shmSize,omitempty"`
+	// LifeCycle 生命周期
+	LifeCycle *LifeCycle `json:"lifeCycle,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+
+// TinyProfile is the Schema for the tinyprofiles API
+type TinyProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TinyProfileSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// TinyProfileList contains a list of TinyProfile
+type TinyProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TinyProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TinyProfile{}, &TinyProfileList{})
+}
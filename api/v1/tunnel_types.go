@@ -23,17 +23,42 @@ import (
 
 // TunnelSpec defines the desired state of Tunnel
 type TunnelSpec struct {
-	Ports    []v1.ServicePort `json:"ports,omitempty"`
-	UnitName string           `json:"unitName"`
+	Ports []v1.ServicePort `json:"ports,omitempty"`
+	// UnitName 目标 Unit，格式为 <namespace>.<name>
+	UnitName string `json:"unitName"`
+	// Type Service 类型，默认为 NodePort，启用 SSH 网关时为 ClusterIP
+	//+kubebuilder:validation:Enum=NodePort;ClusterIP;LoadBalancer
+	//+optional
+	Type v1.ServiceType `json:"type,omitempty"`
+}
+
+const (
+	// TunnelReady Tunnel 的 Service 已创建
+	TunnelReady = "Ready"
+)
+
+type TunnelEndpoint struct {
+	// Name 端口名称
+	Name string `json:"name,omitempty"`
+	// Host 对外连接的地址
+	Host string `json:"host"`
+	// Port 对外连接的端口
+	Port int32 `json:"port"`
+	// Address host:port
+	Address string `json:"address"`
 }
 
 // TunnelStatus defines the observed state of Tunnel
 type TunnelStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Endpoints 对外连接的地址
+	Endpoints []TunnelEndpoint `json:"endpoints,omitempty"`
 }
 
+//+genclient
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 
 // Tunnel is the Schema for the tunnels API
 type Tunnel struct {
@@ -18,11 +18,20 @@ package v1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 const (
 	ResourceNvidiaGPU v1.ResourceName = "nvidia.com/gpu"
+
+	// UnitSuspended Unit 已挂起
+	UnitSuspended v1.PodPhase = "Suspended"
+
+	// CreatedByAnnotation 创建 Unit、Tiny 或 Tunnel 的用户，由准入 webhook 根据请求者设置，
+	// 由 Tiny 创建的 Unit 与 Tunnel 沿用 Tiny 的创建者，用量按该用户统计
+	CreatedByAnnotation = "cokeos.io/created-by"
 )
 
 // UnitSpec defines the desired state of Unit
@@ -31,14 +40,32 @@ type UnitSpec struct {
 	GPUPolicy GPUPolicy `json:"gpuPolicy"`
 	// Framework 机器学习框架
 	Framework Framework `json:"framework"`
-	// ResourceList 资源配额
+	// Image 自定义镜像，设置后替代由 Framework 生成的镜像，仓库须在管理员配置的白名单内
+	Image string `json:"image,omitempty"`
+	// ImagePullPolicy 镜像拉取策略
+	ImagePullPolicy v1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// ImagePullSecrets 拉取镜像使用的 Secret
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// ResourceList 资源配额，即容器的 limits
 	ResourceList v1.ResourceList `json:"resourceList"`
-	// LifeCycle 生命周期
-	// LifeCycle LifeCycle `json:"lifeCycle"`
+	// ResourceRequests 容器的 CPU 与内存 requests，为空时与 ResourceList 相同
+	ResourceRequests v1.ResourceList `json:"resourceRequests,omitempty"`
+	// LifeCycle 生命周期，为空时永久运行
+	LifeCycle *LifeCycle `json:"lifeCycle,omitempty"`
+	// ShmSize 共享内存大小
+	ShmSize *resource.Quantity `json:"shmSize,omitempty"`
 	// Ports 端口映射
 	Ports []v1.ContainerPort `json:"ports,omitempty"`
 	// Execution 执行参数
 	Execution Execution `json:"execution"`
+	// Suspend 挂起，删除 Pod 但保留工作目录与端口
+	Suspend bool `json:"suspend,omitempty"`
+	// IdlePolicy 空闲检测策略
+	IdlePolicy *IdlePolicy `json:"idlePolicy,omitempty"`
+	// NetworkPolicy 网络隔离策略，默认只允许同一命名空间及 Tunnel 的访问
+	NetworkPolicy *UnitNetworkPolicy `json:"networkPolicy,omitempty"`
+	// Security 容器的安全选项，未设置的字段使用控制器配置的默认值
+	Security *UnitSecurity `json:"security,omitempty"`
 }
 
 type LifeCycle struct {
@@ -66,6 +93,48 @@ type Execution struct {
 	Command []string `json:"command,omitempty"`
 	// Args 命令参数
 	Args []string `json:"args,omitempty"`
+	// EnvFrom 从 Secret/ConfigMap 导入环境变量
+	EnvFrom []v1.EnvFromSource `json:"envFrom,omitempty"`
+	// Files 以文件形式挂载的 Secret/ConfigMap，每项必须且只能指定其中一个
+	Files []FileProjection `json:"files,omitempty"`
+}
+
+type FileProjection struct {
+	// MountPath 挂载路径
+	MountPath string `json:"mountPath"`
+	// SubPath 只挂载投影中的单个文件，例如 .netrc
+	SubPath string `json:"subPath,omitempty"`
+	// Secret 来源 Secret
+	Secret *v1.SecretProjection `json:"secret,omitempty"`
+	// ConfigMap 来源 ConfigMap
+	ConfigMap *v1.ConfigMapProjection `json:"configMap,omitempty"`
+	// DefaultMode 文件权限
+	DefaultMode *int32 `json:"defaultMode,omitempty"`
+}
+
+type IdleAction string
+
+const (
+	IdleActionSuspend IdleAction = "Suspend"
+	IdleActionDelete  IdleAction = "Delete"
+)
+
+type IdlePolicy struct {
+	// Timeout 持续空闲多久后执行 Action
+	Timeout metav1.Duration `json:"timeout"`
+	// WarningPeriod 执行 Action 前多久发出告警事件，Action 总在告警后至少经过该时长才执行
+	WarningPeriod *metav1.Duration `json:"warningPeriod,omitempty"`
+	// Action 空闲后的动作，默认挂起
+	// +kubebuilder:validation:Enum=Suspend;Delete
+	Action IdleAction `json:"action,omitempty"`
+	// GPUUtilizationThreshold GPU 利用率（百分比）低于该值视为空闲，为空时不检测
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	GPUUtilizationThreshold *int32 `json:"gpuUtilizationThreshold,omitempty"`
+	// SSHSessions 检测 SSH 会话，没有会话视为空闲
+	SSHSessions bool `json:"sshSessions,omitempty"`
+	// JupyterKernels 检测 Jupyter 内核的最近活动时间
+	JupyterKernels bool `json:"jupyterKernels,omitempty"`
 }
 
 type Framework struct {
@@ -75,13 +144,103 @@ type Framework struct {
 	Version string `json:"version"`
 }
 
+const (
+	// ConditionReferencesResolved Execution 引用的 Secret/ConfigMap 是否都存在
+	ConditionReferencesResolved = "ReferencesResolved"
+	// ConditionPodCreated Pod 是否创建成功，超出命名空间配额时原因为 QuotaExceeded
+	ConditionPodCreated = "PodCreated"
+	// ConditionPodScheduled Pod 是否已调度，集群资源不足时原因为 Unschedulable
+	ConditionPodScheduled = "PodScheduled"
+	// ConditionImagePulled 镜像是否拉取成功，失败时原因为 ImagePullFailed
+	ConditionImagePulled = "ImagePulled"
+
+	// ReasonQuotaExceeded 超出命名空间的 ResourceQuota
+	ReasonQuotaExceeded = "QuotaExceeded"
+	// ReasonUnschedulable 没有满足资源要求的节点
+	ReasonUnschedulable = "Unschedulable"
+	// ReasonImagePullFailed 镜像不存在、无权限或名称非法
+	ReasonImagePullFailed = "ImagePullFailed"
+)
+
+// UnitNetworkPolicy Unit 的网络隔离策略
+type UnitNetworkPolicy struct {
+	// Disabled 不生成网络策略，Unit 可被任意来源访问
+	Disabled bool `json:"disabled,omitempty"`
+	// RestrictEgress 限制出站流量，只允许访问同一命名空间、集群 DNS 及 AllowedEgressCIDRs
+	RestrictEgress bool `json:"restrictEgress,omitempty"`
+	// AllowedEgressCIDRs 限制出站流量时额外允许访问的网段
+	AllowedEgressCIDRs []string `json:"allowedEgressCIDRs,omitempty"`
+}
+
+// UnitSecurity Unit 容器的安全选项
+type UnitSecurity struct {
+	// RunAsUser 运行容器的 UID，工作目录不调整属主，非 root 时 FSGroup 默认取 RunAsGroup
+	RunAsUser *int64 `json:"runAsUser,omitempty"`
+	// RunAsGroup 运行容器的 GID
+	RunAsGroup *int64 `json:"runAsGroup,omitempty"`
+	// FSGroup 挂载卷的属组
+	FSGroup *int64 `json:"fsGroup,omitempty"`
+	// RunAsNonRoot 禁止以 root 运行
+	RunAsNonRoot *bool `json:"runAsNonRoot,omitempty"`
+	// DropCapabilities 移除的 Linux capabilities，例如 ALL
+	DropCapabilities []v1.Capability `json:"dropCapabilities,omitempty"`
+	// AllowPrivilegeEscalation 是否允许提权
+	AllowPrivilegeEscalation *bool `json:"allowPrivilegeEscalation,omitempty"`
+	// ReadOnlyRootFilesystem 只读根文件系统，/tmp 改为挂载临时目录
+	ReadOnlyRootFilesystem *bool `json:"readOnlyRootFilesystem,omitempty"`
+	// SeccompProfile seccomp 配置
+	SeccompProfile *v1.SeccompProfile `json:"seccompProfile,omitempty"`
+}
+
 // UnitStatus defines the observed state of Unit
 type UnitStatus struct {
+	Phase      v1.PodPhase        `json:"phase,omitempty"`
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Image 实际使用的镜像
+	Image string `json:"image,omitempty"`
+	// ImageDigest 运行中容器镜像的 digest
+	ImageDigest string `json:"imageDigest,omitempty"`
+	// NodeName Pod 所在节点
+	NodeName string `json:"nodeName,omitempty"`
+	// HostIP Pod 所在节点的 IP
+	HostIP string `json:"hostIP,omitempty"`
+	// ActiveDuration 截至 ActiveDurationTime 的累计运行时长，不含 Pending 时间
+	//
+	// 运行中的 Pod 至少每分钟计入一次，Pod 结束、重建或 Unit 挂起时立即计入。
+	ActiveDuration metav1.Duration `json:"activeDuration,omitempty"`
+	// ActiveDurationTime ActiveDuration 统计截止的时间，Unit 运行中时实时运行时长为
+	// ActiveDuration 加上此后经过的时间
+	ActiveDurationTime *metav1.Time `json:"activeDurationTime,omitempty"`
+	// SuspendedDuration 截至上次挂起/恢复的累计挂起时长
+	SuspendedDuration metav1.Duration `json:"suspendedDuration,omitempty"`
+	// LastSuspendTransitionTime 上次挂起/恢复的时间
+	LastSuspendTransitionTime *metav1.Time `json:"lastSuspendTransitionTime,omitempty"`
+	// LastActivityTime 空闲检测观察到的最近活动时间
+	LastActivityTime *metav1.Time `json:"lastActivityTime,omitempty"`
+	// IdleWarningTime 发出空闲告警的时间
+	IdleWarningTime *metav1.Time `json:"idleWarningTime,omitempty"`
+	// CreatedBy 创建 Unit 的用户，来自 cokeos.io/created-by 注解
+	CreatedBy string `json:"createdBy,omitempty"`
+	// LogArchives Pod 结束或被删除前归档的容器日志，最多保留最近 10 条
+	LogArchives []LogArchive `json:"logArchives,omitempty"`
+}
+
+// LogArchive 一个 Pod 的容器日志归档
+type LogArchive struct {
+	// Location 日志位置，工作区中的路径（例如 /data/.zero/logs/train/20211019-090000-1a2b3c4d.log）或 s3://bucket/key
+	Location string `json:"location"`
+	// PodUID 日志所属 Pod 的 UID
+	PodUID types.UID `json:"podUID"`
+	// Phase 归档时 Pod 的阶段
 	Phase v1.PodPhase `json:"phase,omitempty"`
+	// ArchiveTime 归档时间
+	ArchiveTime metav1.Time `json:"archiveTime"`
 }
 
+//+genclient
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 
 // Unit is the Schema for the units API
 type Unit struct {
@@ -0,0 +1,102 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UsagePeriod 用量报表的统计周期
+// +kubebuilder:validation:Enum=Daily;Monthly
+type UsagePeriod string
+
+const (
+	UsageDaily   UsagePeriod = "Daily"
+	UsageMonthly UsagePeriod = "Monthly"
+)
+
+// UsageReportSpec defines the desired state of UsageReport
+type UsageReportSpec struct {
+	// Period 统计周期
+	Period UsagePeriod `json:"period"`
+	// Start 统计周期的开始时间（含）
+	Start metav1.Time `json:"start"`
+	// End 统计周期的结束时间（不含）
+	End metav1.Time `json:"end"`
+}
+
+// UsageEntry 一个用户使用一种 GPU 型号的用量，小时数保留两位小数
+type UsageEntry struct {
+	// User 创建 Unit 的用户，未知时为空
+	User string `json:"user,omitempty"`
+	// GPUModel GPU 型号，未使用 GPU 时为空
+	GPUModel string `json:"gpuModel,omitempty"`
+	// Units 统计周期内运行过的 Unit 数
+	Units int32 `json:"units"`
+	// RunningHours Unit 运行的小时数
+	RunningHours string `json:"runningHours"`
+	// GPUHours GPU 卡时
+	GPUHours string `json:"gpuHours"`
+	// CPUCoreHours CPU 核时
+	CPUCoreHours string `json:"cpuCoreHours"`
+	// MemoryGiBHours 内存 GiB 小时
+	MemoryGiBHours string `json:"memoryGiBHours"`
+}
+
+// UsageReportStatus defines the observed state of UsageReport
+type UsageReportStatus struct {
+	// Project 命名空间所属的 Project
+	Project string `json:"project,omitempty"`
+	// Entries 按用户与 GPU 型号汇总的用量
+	Entries []UsageEntry `json:"entries,omitempty"`
+	// GPUHours 命名空间的 GPU 卡时合计
+	GPUHours string `json:"gpuHours,omitempty"`
+	// CPUCoreHours 命名空间的 CPU 核时合计
+	CPUCoreHours string `json:"cpuCoreHours,omitempty"`
+	// MemoryGiBHours 命名空间的内存 GiB 小时合计
+	MemoryGiBHours string `json:"memoryGiBHours,omitempty"`
+	// LastUpdateTime 最近一次汇总的时间，统计周期结束后不再更新
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+//+genclient
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// UsageReport is the Schema for the usagereports API
+//
+// 由用量统计每天与每月为每个命名空间生成，名称为 daily-2006-01-02 或 monthly-2006-01。
+type UsageReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   UsageReportSpec   `json:"spec,omitempty"`
+	Status UsageReportStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// UsageReportList contains a list of UsageReport
+type UsageReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []UsageReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&UsageReport{}, &UsageReportList{})
+}
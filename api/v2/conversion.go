@@ -0,0 +1,60 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// 一个版本无法表示的字段保存在转换后对象的注解中，转换回原版本时恢复并移除注解
+const (
+	// TinyGPUAnnotation 保存 v1 Tiny 中 GPU 为 false 时的 GPUNumber 与 GPUModel，写在 v2 对象上
+	TinyGPUAnnotation = "v1.core.cokeos.io/tiny-gpu"
+	// TunnelUnitNameAnnotation 保存不能拆分为 UnitRef 再拼接回来的 v1 UnitName，写在 v2 对象上
+	TunnelUnitNameAnnotation = "v1.core.cokeos.io/tunnel-unit-name"
+	// TunnelUnitRefAnnotation 保存不能拼接为 UnitName 再拆分回来的 v2 UnitRef，写在 v1 对象上
+	TunnelUnitRefAnnotation = "v2.core.cokeos.io/tunnel-unit-ref"
+)
+
+// setAnnotation 在注解的副本上设置 key，转换不能修改源对象
+func setAnnotation(meta *metav1.ObjectMeta, key, value string) {
+	annotations := make(map[string]string, len(meta.Annotations)+1)
+	for k, v := range meta.Annotations {
+		annotations[k] = v
+	}
+	annotations[key] = value
+	meta.Annotations = annotations
+}
+
+// popAnnotation 返回 key 的取值，并在注解的副本上移除 key
+func popAnnotation(meta *metav1.ObjectMeta, key string) (string, bool) {
+	value, ok := meta.Annotations[key]
+	if !ok {
+		return "", false
+	}
+	annotations := make(map[string]string, len(meta.Annotations))
+	for k, v := range meta.Annotations {
+		if k != key {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	meta.Annotations = annotations
+	return value, true
+}
@@ -0,0 +1,129 @@
+package v2
+
+import (
+	"math/rand"
+	"testing"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+const fuzzIterations = 1000
+
+func TestFuzzyConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	seed := rand.Int63()
+	f := fuzzer.FuzzerFor(metafuzzer.Funcs, rand.NewSource(seed), serializer.NewCodecFactory(scheme))
+	t.Logf("seed %d", seed)
+
+	for _, tc := range []struct {
+		name  string
+		hub   conversion.Hub
+		spoke conversion.Convertible
+	}{
+		{"Unit", &corev1.Unit{}, &Unit{}},
+		{"Tiny", &corev1.Tiny{}, &Tiny{}},
+		{"Tunnel", &corev1.Tunnel{}, &Tunnel{}},
+	} {
+		t.Run(tc.name+"/hub-spoke-hub", func(t *testing.T) {
+			for i := 0; i < fuzzIterations; i++ {
+				hub := tc.hub.DeepCopyObject().(conversion.Hub)
+				f.Fuzz(hub)
+				spoke := tc.spoke.DeepCopyObject().(conversion.Convertible)
+				if err := spoke.ConvertFrom(hub.DeepCopyObject().(conversion.Hub)); err != nil {
+					t.Fatal(err)
+				}
+				got := tc.hub.DeepCopyObject().(conversion.Hub)
+				if err := spoke.ConvertTo(got); err != nil {
+					t.Fatal(err)
+				}
+				if !apiequality.Semantic.DeepEqual(hub, got) {
+					t.Fatalf("round trip changed the object:\n%s", diff.ObjectReflectDiff(hub, got))
+				}
+			}
+		})
+		t.Run(tc.name+"/spoke-hub-spoke", func(t *testing.T) {
+			for i := 0; i < fuzzIterations; i++ {
+				spoke := tc.spoke.DeepCopyObject().(conversion.Convertible)
+				f.Fuzz(spoke)
+				hub := tc.hub.DeepCopyObject().(conversion.Hub)
+				if err := spoke.DeepCopyObject().(conversion.Convertible).ConvertTo(hub); err != nil {
+					t.Fatal(err)
+				}
+				got := tc.spoke.DeepCopyObject().(conversion.Convertible)
+				if err := got.ConvertFrom(hub); err != nil {
+					t.Fatal(err)
+				}
+				if !apiequality.Semantic.DeepEqual(spoke, got) {
+					t.Fatalf("round trip changed the object:\n%s", diff.ObjectReflectDiff(spoke, got))
+				}
+			}
+		})
+	}
+}
+
+func TestTunnelConversion(t *testing.T) {
+	hub := &corev1.Tunnel{Spec: corev1.TunnelSpec{UnitName: "alice.train.v2"}}
+	tunnel := &Tunnel{}
+	if err := tunnel.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+	if tunnel.Spec.UnitRef != (UnitReference{Namespace: "alice", Name: "train.v2"}) || len(tunnel.Annotations) != 0 {
+		t.Errorf("unexpected unitRef %+v", tunnel.Spec.UnitRef)
+	}
+
+	// 没有命名空间的 UnitName 在 v2 中使用 Tunnel 的命名空间，转换回 v1 时恢复原值
+	hub = &corev1.Tunnel{ObjectMeta: metav1.ObjectMeta{Namespace: "alice"}, Spec: corev1.TunnelSpec{UnitName: "train"}}
+	if err := tunnel.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+	if tunnel.Spec.UnitRef != (UnitReference{Namespace: "alice", Name: "train"}) || tunnel.Annotations[TunnelUnitNameAnnotation] != "train" {
+		t.Errorf("unexpected Tunnel %+v", tunnel)
+	}
+	if len(hub.Annotations) != 0 {
+		t.Errorf("expected the hub to be unchanged, got %v", hub.Annotations)
+	}
+	// 在 v2 中修改 UnitRef 后不再恢复
+	tunnel.Spec.UnitRef.Name = "eval"
+	got := &corev1.Tunnel{}
+	if err := tunnel.ConvertTo(got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Spec.UnitName != "alice.eval" || len(got.Annotations) != 0 {
+		t.Errorf("unexpected hub %+v", got)
+	}
+}
+
+func TestTinyConversion(t *testing.T) {
+	hub := &corev1.Tiny{Spec: corev1.TinySpec{GPUNumber: 2, GPUModel: "RTX-3090"}}
+	tiny := &Tiny{}
+	if err := tiny.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+	if tiny.Spec.GPU != nil || tiny.Annotations[TinyGPUAnnotation] == "" {
+		t.Fatalf("unexpected Tiny %+v", tiny)
+	}
+
+	// 在 v2 中启用 GPU 后以 v2 的取值为准
+	tiny.Spec.GPU = &TinyGPU{Count: 1}
+	got := &corev1.Tiny{}
+	if err := tiny.ConvertTo(got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Spec.GPU || got.Spec.GPUNumber != 1 || got.Spec.GPUModel != "" || len(got.Annotations) != 0 {
+		t.Errorf("unexpected hub %+v", got)
+	}
+}
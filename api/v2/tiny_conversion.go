@@ -0,0 +1,74 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"encoding/json"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConvertTo converts this Tiny to the Hub version (v1).
+func (src *Tiny) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*corev1.Tiny)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = corev1.TinySpec{
+		GPU:        src.Spec.GPU != nil,
+		Framework:  corev1.Framework(src.Spec.Framework),
+		Profile:    src.Spec.Profile,
+		IdlePolicy: convertIdlePolicyTo(src.Spec.IdlePolicy),
+	}
+	if src.Spec.GPU != nil {
+		dst.Spec.GPUNumber = src.Spec.GPU.Count
+		dst.Spec.GPUModel = src.Spec.GPU.Model
+	}
+	// v2 中重新设置了 GPU 时注解已过期
+	if value, ok := popAnnotation(&dst.ObjectMeta, TinyGPUAnnotation); ok && src.Spec.GPU == nil {
+		gpu := TinyGPU{}
+		if err := json.Unmarshal([]byte(value), &gpu); err == nil {
+			dst.Spec.GPUNumber = gpu.Count
+			dst.Spec.GPUModel = gpu.Model
+		}
+	}
+	dst.Status = corev1.TinyStatus(src.Status)
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this version.
+//
+// v1 中 GPU 为 false 时 GPUNumber 与 GPUModel 不生效，保存在 TinyGPUAnnotation 中。
+func (dst *Tiny) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*corev1.Tiny)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = TinySpec{
+		Framework:  Framework(src.Spec.Framework),
+		Profile:    src.Spec.Profile,
+		IdlePolicy: convertIdlePolicyFrom(src.Spec.IdlePolicy),
+	}
+	if src.Spec.GPU {
+		dst.Spec.GPU = &TinyGPU{Count: src.Spec.GPUNumber, Model: src.Spec.GPUModel}
+	} else if src.Spec.GPUNumber != 0 || src.Spec.GPUModel != "" {
+		value, err := json.Marshal(TinyGPU{Count: src.Spec.GPUNumber, Model: src.Spec.GPUModel})
+		if err != nil {
+			return err
+		}
+		setAnnotation(&dst.ObjectMeta, TinyGPUAnnotation, string(value))
+	}
+	dst.Status = TinyStatus(src.Status)
+	return nil
+}
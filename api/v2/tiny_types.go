@@ -0,0 +1,84 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TinyGPU Tiny 使用的 GPU
+type TinyGPU struct {
+	// Count GPU 数量，为 0 时使用 Profile 中的数量
+	// +kubebuilder:validation:Minimum=0
+	Count int32 `json:"count,omitempty"`
+	// Model GPU 型号，为空时使用 Profile 中的型号
+	Model string `json:"model,omitempty"`
+}
+
+// TinySpec defines the desired state of Tiny
+type TinySpec struct {
+	// GPU 使用的 GPU，为空时不使用 GPU
+	GPU       *TinyGPU  `json:"gpu,omitempty"`
+	Framework Framework `json:"framework"`
+	// Profile 使用的 TinyProfile，为空时使用默认 Profile
+	Profile string `json:"profile,omitempty"`
+	// IdlePolicy 空闲检测策略，透传给 Unit
+	IdlePolicy *IdlePolicy `json:"idlePolicy,omitempty"`
+}
+
+// TinyStatus defines the observed state of Tiny
+type TinyStatus struct {
+	Phase    v1.PodPhase `json:"phase,omitempty"`
+	NodePort int32       `json:"nodePort,omitempty"`
+	// Profile 实际使用的 TinyProfile
+	Profile    string             `json:"profile,omitempty"`
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Host SSH 连接的地址
+	Host string `json:"host,omitempty"`
+	// Endpoint SSH 连接的 host:port
+	Endpoint string `json:"endpoint,omitempty"`
+	// SSHCommand 可直接使用的 SSH 连接命令
+	SSHCommand string `json:"sshCommand,omitempty"`
+	// CreatedBy 创建 Tiny 的用户，来自 cokeos.io/created-by 注解
+	CreatedBy string `json:"createdBy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// Tiny is the Schema for the tinies API
+type Tiny struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TinySpec   `json:"spec,omitempty"`
+	Status TinyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// TinyList contains a list of Tiny
+type TinyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Tiny `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Tiny{}, &TinyList{})
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"encoding/json"
+	"strings"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConvertTo converts this Tunnel to the Hub version (v1).
+func (src *Tunnel) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*corev1.Tunnel)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = corev1.TunnelSpec{
+		Ports:    src.Spec.Ports,
+		UnitName: unitName(src.Spec.UnitRef),
+		Type:     src.Spec.Type,
+	}
+	// v2 中修改了 UnitRef 时注解已过期
+	if name, ok := popAnnotation(&dst.ObjectMeta, TunnelUnitNameAnnotation); ok && unitReference(name, src.Namespace) == src.Spec.UnitRef {
+		dst.Spec.UnitName = name
+	}
+	if unitReference(dst.Spec.UnitName, src.Namespace) != src.Spec.UnitRef {
+		value, err := json.Marshal(src.Spec.UnitRef)
+		if err != nil {
+			return err
+		}
+		setAnnotation(&dst.ObjectMeta, TunnelUnitRefAnnotation, string(value))
+	}
+	dst.Status = corev1.TunnelStatus{Conditions: src.Status.Conditions}
+	if src.Status.Endpoints != nil {
+		dst.Status.Endpoints = make([]corev1.TunnelEndpoint, len(src.Status.Endpoints))
+		for i, endpoint := range src.Status.Endpoints {
+			dst.Status.Endpoints[i] = corev1.TunnelEndpoint(endpoint)
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this version.
+//
+// 命名空间不含 "."，v1 的 UnitName 按第一个 "." 拆分为命名空间与名称；
+// 拼接不回原值的 UnitName 保存在 TunnelUnitNameAnnotation 中。
+func (dst *Tunnel) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*corev1.Tunnel)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = TunnelSpec{
+		Ports:   src.Spec.Ports,
+		UnitRef: unitReference(src.Spec.UnitName, src.Namespace),
+		Type:    src.Spec.Type,
+	}
+	// v1 中修改了 UnitName 时注解已过期
+	if value, ok := popAnnotation(&dst.ObjectMeta, TunnelUnitRefAnnotation); ok {
+		ref := UnitReference{}
+		if err := json.Unmarshal([]byte(value), &ref); err == nil && unitName(ref) == src.Spec.UnitName {
+			dst.Spec.UnitRef = ref
+		}
+	}
+	if unitName(dst.Spec.UnitRef) != src.Spec.UnitName {
+		setAnnotation(&dst.ObjectMeta, TunnelUnitNameAnnotation, src.Spec.UnitName)
+	}
+	dst.Status = TunnelStatus{Conditions: src.Status.Conditions}
+	if src.Status.Endpoints != nil {
+		dst.Status.Endpoints = make([]TunnelEndpoint, len(src.Status.Endpoints))
+		for i, endpoint := range src.Status.Endpoints {
+			dst.Status.Endpoints[i] = TunnelEndpoint(endpoint)
+		}
+	}
+	return nil
+}
+
+// unitName 拼接 v1 的 UnitName <namespace>.<name>
+func unitName(ref UnitReference) string {
+	if ref.Namespace == "" {
+		return ref.Name
+	}
+	return ref.Namespace + "." + ref.Name
+}
+
+// unitReference 按第一个 "." 拆分 v1 的 UnitName，没有命名空间时使用 Tunnel 所在的命名空间
+func unitReference(name, namespace string) UnitReference {
+	if parts := strings.SplitN(name, ".", 2); len(parts) == 2 && parts[0] != "" {
+		return UnitReference{Namespace: parts[0], Name: parts[1]}
+	}
+	return UnitReference{Namespace: namespace, Name: name}
+}
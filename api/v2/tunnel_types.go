@@ -0,0 +1,86 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UnitReference Tunnel 指向的 Unit
+type UnitReference struct {
+	// Namespace Unit 所在的命名空间
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// Name Unit 的名称
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// TunnelSpec defines the desired state of Tunnel
+type TunnelSpec struct {
+	Ports []v1.ServicePort `json:"ports,omitempty"`
+	// UnitRef 目标 Unit
+	UnitRef UnitReference `json:"unitRef"`
+	// Type Service 类型，默认为 NodePort，启用 SSH 网关时为 ClusterIP
+	//+kubebuilder:validation:Enum=NodePort;ClusterIP;LoadBalancer
+	//+optional
+	Type v1.ServiceType `json:"type,omitempty"`
+}
+
+type TunnelEndpoint struct {
+	// Name 端口名称
+	Name string `json:"name,omitempty"`
+	// Host 对外连接的地址
+	Host string `json:"host"`
+	// Port 对外连接的端口
+	Port int32 `json:"port"`
+	// Address host:port
+	Address string `json:"address"`
+}
+
+// TunnelStatus defines the observed state of Tunnel
+type TunnelStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Endpoints 对外连接的地址
+	Endpoints []TunnelEndpoint `json:"endpoints,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// Tunnel is the Schema for the tunnels API
+type Tunnel struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TunnelSpec   `json:"spec,omitempty"`
+	Status TunnelStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// TunnelList contains a list of Tunnel
+type TunnelList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Tunnel `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Tunnel{}, &TunnelList{})
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConvertTo converts this Unit to the Hub version (v1).
+func (src *Unit) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*corev1.Unit)
+	dst.ObjectMeta = src.ObjectMeta
+
+	spec := &src.Spec
+	dst.Spec = corev1.UnitSpec{
+		GPUPolicy:        corev1.GPUPolicy(spec.GPUPolicy),
+		Framework:        corev1.Framework(spec.Framework),
+		Image:            spec.Image,
+		ImagePullPolicy:  spec.ImagePullPolicy,
+		ImagePullSecrets: spec.ImagePullSecrets,
+		// v1 的 ResourceList 即 limits
+		ResourceList:     spec.Resources.Limits,
+		ResourceRequests: spec.Resources.Requests,
+		LifeCycle:        (*corev1.LifeCycle)(spec.LifeCycle),
+		ShmSize:          spec.ShmSize,
+		Ports:            spec.Ports,
+		Execution: corev1.Execution{
+			SSH:     spec.Execution.SSH,
+			Env:     spec.Execution.Env,
+			Command: spec.Execution.Command,
+			Args:    spec.Execution.Args,
+			EnvFrom: spec.Execution.EnvFrom,
+		},
+		Suspend:       spec.Suspend,
+		IdlePolicy:    convertIdlePolicyTo(spec.IdlePolicy),
+		NetworkPolicy: (*corev1.UnitNetworkPolicy)(spec.NetworkPolicy),
+		Security:      (*corev1.UnitSecurity)(spec.Security),
+	}
+	if spec.Execution.Files != nil {
+		dst.Spec.Execution.Files = make([]corev1.FileProjection, len(spec.Execution.Files))
+		for i, file := range spec.Execution.Files {
+			dst.Spec.Execution.Files[i] = corev1.FileProjection(file)
+		}
+	}
+
+	status := &src.Status
+	dst.Status = corev1.UnitStatus{
+		Phase:                     status.Phase,
+		Conditions:                status.Conditions,
+		Image:                     status.Image,
+		ImageDigest:               status.ImageDigest,
+		NodeName:                  status.NodeName,
+		HostIP:                    status.HostIP,
+		ActiveDuration:            status.ActiveDuration,
+		ActiveDurationTime:        status.ActiveDurationTime,
+		SuspendedDuration:         status.SuspendedDuration,
+		LastSuspendTransitionTime: status.LastSuspendTransitionTime,
+		LastActivityTime:          status.LastActivityTime,
+		IdleWarningTime:           status.IdleWarningTime,
+		CreatedBy:                 status.CreatedBy,
+	}
+	if status.LogArchives != nil {
+		dst.Status.LogArchives = make([]corev1.LogArchive, len(status.LogArchives))
+		for i, archive := range status.LogArchives {
+			dst.Status.LogArchives[i] = corev1.LogArchive(archive)
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this version.
+func (dst *Unit) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*corev1.Unit)
+	dst.ObjectMeta = src.ObjectMeta
+
+	spec := &src.Spec
+	dst.Spec = UnitSpec{
+		GPUPolicy:        GPUPolicy(spec.GPUPolicy),
+		Framework:        Framework(spec.Framework),
+		Image:            spec.Image,
+		ImagePullPolicy:  spec.ImagePullPolicy,
+		ImagePullSecrets: spec.ImagePullSecrets,
+		Resources: v1.ResourceRequirements{
+			Limits:   spec.ResourceList,
+			Requests: spec.ResourceRequests,
+		},
+		LifeCycle: (*LifeCycle)(spec.LifeCycle),
+		ShmSize:   spec.ShmSize,
+		Ports:     spec.Ports,
+		Execution: Execution{
+			SSH:     spec.Execution.SSH,
+			Env:     spec.Execution.Env,
+			Command: spec.Execution.Command,
+			Args:    spec.Execution.Args,
+			EnvFrom: spec.Execution.EnvFrom,
+		},
+		Suspend:       spec.Suspend,
+		IdlePolicy:    convertIdlePolicyFrom(spec.IdlePolicy),
+		NetworkPolicy: (*UnitNetworkPolicy)(spec.NetworkPolicy),
+		Security:      (*UnitSecurity)(spec.Security),
+	}
+	if spec.Execution.Files != nil {
+		dst.Spec.Execution.Files = make([]FileProjection, len(spec.Execution.Files))
+		for i, file := range spec.Execution.Files {
+			dst.Spec.Execution.Files[i] = FileProjection(file)
+		}
+	}
+
+	status := &src.Status
+	dst.Status = UnitStatus{
+		Phase:                     status.Phase,
+		Conditions:                status.Conditions,
+		Image:                     status.Image,
+		ImageDigest:               status.ImageDigest,
+		NodeName:                  status.NodeName,
+		HostIP:                    status.HostIP,
+		ActiveDuration:            status.ActiveDuration,
+		ActiveDurationTime:        status.ActiveDurationTime,
+		SuspendedDuration:         status.SuspendedDuration,
+		LastSuspendTransitionTime: status.LastSuspendTransitionTime,
+		LastActivityTime:          status.LastActivityTime,
+		IdleWarningTime:           status.IdleWarningTime,
+		CreatedBy:                 status.CreatedBy,
+	}
+	if status.LogArchives != nil {
+		dst.Status.LogArchives = make([]LogArchive, len(status.LogArchives))
+		for i, archive := range status.LogArchives {
+			dst.Status.LogArchives[i] = LogArchive(archive)
+		}
+	}
+	return nil
+}
+
+func convertIdlePolicyTo(src *IdlePolicy) *corev1.IdlePolicy {
+	if src == nil {
+		return nil
+	}
+	return &corev1.IdlePolicy{
+		Timeout:                 src.Timeout,
+		WarningPeriod:           src.WarningPeriod,
+		Action:                  corev1.IdleAction(src.Action),
+		GPUUtilizationThreshold: src.GPUUtilizationThreshold,
+		SSHSessions:             src.SSHSessions,
+		JupyterKernels:          src.JupyterKernels,
+	}
+}
+
+func convertIdlePolicyFrom(src *corev1.IdlePolicy) *IdlePolicy {
+	if src == nil {
+		return nil
+	}
+	return &IdlePolicy{
+		Timeout:                 src.Timeout,
+		WarningPeriod:           src.WarningPeriod,
+		Action:                  IdleAction(src.Action),
+		GPUUtilizationThreshold: src.GPUUtilizationThreshold,
+		SSHSessions:             src.SSHSessions,
+		JupyterKernels:          src.JupyterKernels,
+	}
+}
@@ -0,0 +1,114 @@
+package apiserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var errUnauthenticated = errors.New("unauthenticated")
+
+// impersonatedExtras 转发给 Kubernetes 的身份扩展字段，需与 apiserver-role 中 userextras 的授权保持一致
+//
+// ServiceAccount Token 携带的 authentication.kubernetes.io/pod-name 等字段不参与 RBAC 鉴权，
+// 转发时会因缺少 impersonate 授权使请求被拒绝。
+var impersonatedExtras = []string{"scopes"}
+
+// UserInfo 请求者的身份，以此模拟调用者访问 Kubernetes
+type UserInfo struct {
+	Username string              `json:"username"`
+	Groups   []string            `json:"groups,omitempty"`
+	Extra    map[string][]string `json:"extra,omitempty"`
+}
+
+// Authenticator 校验 Bearer Token 并返回请求者的身份
+type Authenticator interface {
+	AuthenticateToken(ctx context.Context, token string) (*UserInfo, error)
+}
+
+// AuthenticatorFunc 将函数适配为 Authenticator，便于测试
+type AuthenticatorFunc func(ctx context.Context, token string) (*UserInfo, error)
+
+func (f AuthenticatorFunc) AuthenticateToken(ctx context.Context, token string) (*UserInfo, error) {
+	return f(ctx, token)
+}
+
+// TokenReviewAuthenticator 通过 TokenReview 校验 Token
+//
+// ServiceAccount Token 与 API Server 配置的 OIDC 身份提供方签发的 ID Token 均可通过校验。
+type TokenReviewAuthenticator struct {
+	Client kubernetes.Interface
+	// Audiences Token 需要包含的受众，为空时使用 API Server 的默认受众
+	Audiences []string
+}
+
+// NewTokenReviewAuthenticator 使用 config 的凭据创建 TokenReview 客户端
+func NewTokenReviewAuthenticator(config *rest.Config, audiences []string) (*TokenReviewAuthenticator, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenReviewAuthenticator{Client: clientset, Audiences: audiences}, nil
+}
+
+func (a *TokenReviewAuthenticator) AuthenticateToken(ctx context.Context, token string) (*UserInfo, error) {
+	review, err := a.Client.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     token,
+			Audiences: a.Audiences,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return nil, errors.New(review.Status.Error)
+		}
+		return nil, errUnauthenticated
+	}
+	user := &UserInfo{
+		Username: review.Status.User.Username,
+		Groups:   review.Status.User.Groups,
+	}
+	if len(review.Status.User.Extra) > 0 {
+		user.Extra = make(map[string][]string, len(review.Status.User.Extra))
+		for k, v := range review.Status.User.Extra {
+			user.Extra[k] = v
+		}
+	}
+	return user, nil
+}
+
+// bearerToken 读取 Authorization: Bearer <token>
+func bearerToken(r *http.Request) string {
+	auth := strings.TrimSpace(r.Header.Get("Authorization"))
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// impersonate 以请求者身份访问 Kubernetes，使 RBAC 对其生效，只转发 impersonatedExtras 中的扩展字段
+func impersonate(config *rest.Config, user *UserInfo) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.Impersonate = rest.ImpersonationConfig{
+		UserName: user.Username,
+		Groups:   user.Groups,
+	}
+	for _, key := range impersonatedExtras {
+		if values, ok := user.Extra[key]; ok {
+			if config.Impersonate.Extra == nil {
+				config.Impersonate.Extra = make(map[string][]string)
+			}
+			config.Impersonate.Extra[key] = values
+		}
+	}
+	return config
+}
@@ -0,0 +1,118 @@
+package apiserver
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/internal/zerotest"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/yaml"
+)
+
+// serviceAccountUser TokenReview 为 Pod 绑定的 ServiceAccount Token 返回的身份
+func serviceAccountUser() *UserInfo {
+	return &UserInfo{
+		Username: "system:serviceaccount:default:notebook",
+		Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:default", "system:authenticated"},
+		Extra: map[string][]string{
+			"authentication.kubernetes.io/pod-name": {"notebook-0"},
+			"authentication.kubernetes.io/pod-uid":  {"0b4d5f9c-4b0a-4a43-9d2c-2b7c1f0e9a61"},
+			"scopes":                                {"user:info"},
+		},
+	}
+}
+
+func TestImpersonate(t *testing.T) {
+	user := serviceAccountUser()
+	config := impersonate(&rest.Config{Host: "https://127.0.0.1:6443"}, user)
+	if config.Impersonate.UserName != user.Username || !reflect.DeepEqual(config.Impersonate.Groups, user.Groups) {
+		t.Errorf("unexpected impersonation %+v", config.Impersonate)
+	}
+	if expected := map[string][]string{"scopes": {"user:info"}}; !reflect.DeepEqual(config.Impersonate.Extra, expected) {
+		t.Errorf("expected only the allowed extras, got %v", config.Impersonate.Extra)
+	}
+	if config = impersonate(config, &UserInfo{Username: "alice"}); config.Impersonate.Extra != nil {
+		t.Errorf("expected no extras, got %v", config.Impersonate.Extra)
+	}
+}
+
+// TestImpersonationWithServiceAccountToken 以只有 apiserver-role 权限的身份模拟 ServiceAccount 访问
+func TestImpersonationWithServiceAccountToken(t *testing.T) {
+	env, config := startEnv(t)
+	defer func() {
+		_ = env.Stop()
+	}()
+
+	scheme := zerotest.NewScheme()
+	admin, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile("../config/rbac/apiserver_role.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	role := &rbacv1.ClusterRole{}
+	if err := yaml.Unmarshal(data, role); err != nil {
+		t.Fatal(err)
+	}
+	reader := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "tiny-reader"},
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{corev1.GroupVersion.Group}, Resources: []string{"tinies"}, Verbs: []string{"get", "list"}},
+		},
+	}
+	user := serviceAccountUser()
+	ctx := context.TODO()
+	for _, obj := range []client.Object{
+		role,
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "zero-apiserver"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: role.Name},
+			Subjects:   []rbacv1.Subject{{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "zero-apiserver"}},
+		},
+		reader,
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "notebook"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: reader.Name},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: "default", Name: "notebook"}},
+		},
+	} {
+		if err := admin.Create(ctx, obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	apiserver, err := env.AddUser(envtest.User{Name: "zero-apiserver"}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := New(apiserver.Config(), scheme, AuthenticatorFunc(func(ctx context.Context, token string) (*UserInfo, error) {
+		return serviceAccountUser(), nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// RBAC 的变更异步生效
+	var code int
+	var body string
+	_ = wait.PollImmediate(100*time.Millisecond, 10*time.Second, func() (bool, error) {
+		w := do(t, server, http.MethodGet, "/api/v1/namespaces/default/tinies", testToken, "")
+		code, body = w.Code, w.Body.String()
+		return code == http.StatusOK, nil
+	})
+	if code != http.StatusOK {
+		t.Fatalf("expected the ServiceAccount %s to list tinies, got %d: %s", user.Username, code, body)
+	}
+}
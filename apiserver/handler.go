@@ -0,0 +1,204 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// kind 接口支持的资源
+type kind struct {
+	name      string
+	newObject func() client.Object
+	newList   func() client.ObjectList
+	status    func(obj client.Object) interface{}
+	// readOnly 只支持查询，例如由控制器生成的 UsageReport
+	readOnly bool
+	// csv 以 CSV 导出对象的行，为空时不支持 format=csv
+	csv       func(obj client.Object) [][]string
+	csvHeader []string
+}
+
+func (k kind) resource() schema.GroupResource {
+	return corev1.GroupVersion.WithResource(k.name).GroupResource()
+}
+
+var kinds = map[string]kind{
+	"tinies": {
+		name:      "tinies",
+		newObject: func() client.Object { return &corev1.Tiny{} },
+		newList:   func() client.ObjectList { return &corev1.TinyList{} },
+		status:    func(obj client.Object) interface{} { return obj.(*corev1.Tiny).Status },
+	},
+	"units": {
+		name:      "units",
+		newObject: func() client.Object { return &corev1.Unit{} },
+		newList:   func() client.ObjectList { return &corev1.UnitList{} },
+		status:    func(obj client.Object) interface{} { return obj.(*corev1.Unit).Status },
+	},
+	"usagereports": {
+		name:      "usagereports",
+		newObject: func() client.Object { return &corev1.UsageReport{} },
+		newList:   func() client.ObjectList { return &corev1.UsageReportList{} },
+		status:    func(obj client.Object) interface{} { return obj.(*corev1.UsageReport).Status },
+		readOnly:  true,
+		csv:       usageRows,
+		csvHeader: usageHeader,
+	},
+}
+
+type handler struct {
+	clients   *Clients
+	kind      kind
+	namespace string
+}
+
+func (h *handler) list(w http.ResponseWriter, r *http.Request) {
+	list := h.kind.newList()
+	if err := h.clients.Client.List(r.Context(), list, client.InNamespace(h.namespace)); err != nil {
+		writeError(w, err)
+		return
+	}
+	if h.wantCSV(r) {
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		var rows [][]string
+		for _, item := range items {
+			rows = append(rows, h.kind.csv(item.(client.Object))...)
+		}
+		writeCSV(w, h.kind.csvHeader, rows)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (h *handler) create(w http.ResponseWriter, r *http.Request) {
+	obj := h.kind.newObject()
+	if err := json.NewDecoder(r.Body).Decode(obj); err != nil {
+		writeError(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+	obj.SetNamespace(h.namespace)
+	if obj.GetName() == "" && obj.GetGenerateName() == "" {
+		writeError(w, apierrors.NewBadRequest("metadata.name or metadata.generateName is required"))
+		return
+	}
+	if err := h.clients.Client.Create(r.Context(), obj); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, obj)
+}
+
+func (h *handler) get(w http.ResponseWriter, r *http.Request, name string) {
+	obj := h.kind.newObject()
+	if err := h.clients.Client.Get(r.Context(), types.NamespacedName{Namespace: h.namespace, Name: name}, obj); err != nil {
+		writeError(w, err)
+		return
+	}
+	if h.wantCSV(r) {
+		writeCSV(w, h.kind.csvHeader, h.kind.csv(obj))
+		return
+	}
+	writeJSON(w, http.StatusOK, obj)
+}
+
+func (h *handler) delete(w http.ResponseWriter, r *http.Request, name string) {
+	obj := h.kind.newObject()
+	obj.SetNamespace(h.namespace)
+	obj.SetName(name)
+	if err := h.clients.Client.Delete(r.Context(), obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, metav1.Status{Status: metav1.StatusSuccess, Code: http.StatusOK})
+}
+
+func (h *handler) status(w http.ResponseWriter, r *http.Request, name string) {
+	obj := h.kind.newObject()
+	if err := h.clients.Client.Get(r.Context(), types.NamespacedName{Namespace: h.namespace, Name: name}, obj); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.kind.status(obj))
+}
+
+// suspend 挂起或恢复 Unit，Tiny 作用于其同名 Unit
+func (h *handler) suspend(w http.ResponseWriter, r *http.Request, name string, suspend bool) {
+	key := types.NamespacedName{Namespace: h.namespace, Name: name}
+	if err := h.clients.Client.Get(r.Context(), key, h.kind.newObject()); err != nil {
+		writeError(w, err)
+		return
+	}
+	unit := &corev1.Unit{}
+	if err := h.clients.Client.Get(r.Context(), key, unit); err != nil {
+		writeError(w, err)
+		return
+	}
+	patch := client.MergeFrom(unit.DeepCopy())
+	unit.Spec.Suspend = suspend
+	if err := h.clients.Client.Patch(r.Context(), unit, patch); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, unit)
+}
+
+// logs 输出 Unit Pod 的日志，follow=true 时持续推送
+func (h *handler) logs(w http.ResponseWriter, r *http.Request, name string) {
+	key := types.NamespacedName{Namespace: h.namespace, Name: name}
+	if err := h.clients.Client.Get(r.Context(), key, h.kind.newObject()); err != nil {
+		writeError(w, err)
+		return
+	}
+	options := &v1.PodLogOptions{}
+	query := r.URL.Query()
+	if follow, err := strconv.ParseBool(query.Get("follow")); err == nil {
+		options.Follow = follow
+	}
+	if tail, err := strconv.ParseInt(query.Get("tailLines"), 10, 64); err == nil {
+		options.TailLines = &tail
+	}
+	if since, err := strconv.ParseInt(query.Get("sinceSeconds"), 10, 64); err == nil {
+		options.SinceSeconds = &since
+	}
+
+	stream, err := h.clients.Kubernetes.CoreV1().Pods(h.namespace).GetLogs(name, options).Stream(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer stream.Close()
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(flushWriter{w}, stream); err != nil && r.Context().Err() == nil {
+		klog.Error(err)
+	}
+}
+
+// flushWriter 每次写入后立即推送给客户端
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if flusher, ok := f.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// APIPrefix REST 接口的版本前缀
+const APIPrefix = "/api/v1/"
+
+// Clients 以请求者身份访问 Kubernetes 的客户端
+type Clients struct {
+	Client     client.Client
+	Kubernetes kubernetes.Interface
+}
+
+// ClientFactory 为请求者创建客户端
+type ClientFactory func(user *UserInfo) (*Clients, error)
+
+// Server 供 Web 前端使用的 REST 接口
+//
+//	GET    /api/v1/namespaces/{namespace}/{tinies|units}
+//	POST   /api/v1/namespaces/{namespace}/{tinies|units}
+//	GET    /api/v1/namespaces/{namespace}/{tinies|units}/{name}
+//	DELETE /api/v1/namespaces/{namespace}/{tinies|units}/{name}
+//	GET    /api/v1/namespaces/{namespace}/{tinies|units}/{name}/status
+//	POST   /api/v1/namespaces/{namespace}/{tinies|units}/{name}/suspend
+//	POST   /api/v1/namespaces/{namespace}/{tinies|units}/{name}/resume
+//	GET    /api/v1/namespaces/{namespace}/{tinies|units}/{name}/logs
+//	GET    /api/v1/namespaces/{namespace}/usagereports[/{name}[/status]][?format=csv]
+type Server struct {
+	Authenticator Authenticator
+	NewClients    ClientFactory
+}
+
+// New 创建以请求者身份模拟访问 Kubernetes 的 Server，config 需要有 impersonate 权限
+func New(config *rest.Config, scheme *runtime.Scheme, authenticator Authenticator) (*Server, error) {
+	// RESTMapper 在各请求间共享，避免每次请求都做一次发现
+	mapper, err := apiutil.NewDynamicRESTMapper(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		Authenticator: authenticator,
+		NewClients: func(user *UserInfo) (*Clients, error) {
+			config := impersonate(config, user)
+			c, err := client.New(config, client.Options{Scheme: scheme, Mapper: mapper})
+			if err != nil {
+				return nil, err
+			}
+			clientset, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return nil, err
+			}
+			return &Clients{Client: c, Kubernetes: clientset}, nil
+		},
+	}, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/healthz" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, APIPrefix) {
+		writeError(w, apierrors.NewNotFound(corev1.GroupVersion.WithResource("").GroupResource(), r.URL.Path))
+		return
+	}
+
+	token := bearerToken(r)
+	if token == "" {
+		writeError(w, apierrors.NewUnauthorized("missing bearer token"))
+		return
+	}
+	user, err := s.Authenticator.AuthenticateToken(r.Context(), token)
+	if err != nil {
+		writeError(w, apierrors.NewUnauthorized(err.Error()))
+		return
+	}
+
+	// namespaces/{namespace}/{resource}[/{name}[/{action}]]
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, APIPrefix), "/"), "/")
+	if len(parts) < 3 || len(parts) > 5 || parts[0] != "namespaces" {
+		writeError(w, apierrors.NewNotFound(corev1.GroupVersion.WithResource("").GroupResource(), r.URL.Path))
+		return
+	}
+	kind, ok := kinds[parts[2]]
+	if !ok {
+		writeError(w, apierrors.NewNotFound(corev1.GroupVersion.WithResource(parts[2]).GroupResource(), ""))
+		return
+	}
+	clients, err := s.NewClients(user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	h := &handler{
+		clients:   clients,
+		kind:      kind,
+		namespace: parts[1],
+	}
+
+	if kind.readOnly && (r.Method != http.MethodGet || len(parts) == 5 && parts[4] != "status") {
+		writeError(w, apierrors.NewMethodNotSupported(kind.resource(), r.Method))
+		return
+	}
+
+	switch {
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		h.list(w, r)
+	case len(parts) == 3 && r.Method == http.MethodPost:
+		h.create(w, r)
+	case len(parts) == 4 && r.Method == http.MethodGet:
+		h.get(w, r, parts[3])
+	case len(parts) == 4 && r.Method == http.MethodDelete:
+		h.delete(w, r, parts[3])
+	case len(parts) == 5 && parts[4] == "status" && r.Method == http.MethodGet:
+		h.status(w, r, parts[3])
+	case len(parts) == 5 && parts[4] == "suspend" && r.Method == http.MethodPost:
+		h.suspend(w, r, parts[3], true)
+	case len(parts) == 5 && parts[4] == "resume" && r.Method == http.MethodPost:
+		h.suspend(w, r, parts[3], false)
+	case len(parts) == 5 && parts[4] == "logs" && r.Method == http.MethodGet:
+		h.logs(w, r, parts[3])
+	default:
+		writeError(w, apierrors.NewMethodNotSupported(kind.resource(), r.Method))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		klog.Error(err)
+	}
+}
+
+// writeError 以 metav1.Status 返回错误，与 Kubernetes API 的错误格式一致
+func writeError(w http.ResponseWriter, err error) {
+	if status, ok := err.(apierrors.APIStatus); ok {
+		writeJSON(w, int(status.Status().Code), status.Status())
+		return
+	}
+	writeJSON(w, http.StatusInternalServerError, metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusInternalServerError,
+		Reason:  metav1.StatusReasonInternalError,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,265 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/internal/zerotest"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+const testToken = "token"
+
+func testAuthenticator(t *testing.T) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, token string) (*UserInfo, error) {
+		if token != testToken {
+			return nil, errors.New("invalid token")
+		}
+		return &UserInfo{Username: "alice", Groups: []string{"students"}}, nil
+	})
+}
+
+func newFakeServer(t *testing.T, objs ...client.Object) (*Server, client.Client) {
+	c := zerotest.NewClient(objs...)
+	return &Server{
+		Authenticator: testAuthenticator(t),
+		NewClients: func(user *UserInfo) (*Clients, error) {
+			if user.Username != "alice" || len(user.Groups) != 1 {
+				t.Errorf("unexpected user %+v", user)
+			}
+			return &Clients{Client: c, Kubernetes: kubefake.NewSimpleClientset()}, nil
+		},
+	}, c
+}
+
+func do(t *testing.T, server http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+func TestUnauthenticated(t *testing.T) {
+	server, _ := newFakeServer(t)
+	for _, token := range []string{"", "wrong"} {
+		w := do(t, server, http.MethodGet, "/api/v1/namespaces/default/tinies", token, "")
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: expected 401, got %d", token, w.Code)
+		}
+	}
+}
+
+func TestUnknownResource(t *testing.T) {
+	server, _ := newFakeServer(t)
+	w := do(t, server, http.MethodGet, "/api/v1/namespaces/default/pods", testToken, "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+	w = do(t, server, http.MethodPut, "/api/v1/namespaces/default/tinies/a", testToken, "")
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
+
+func TestCreateListDeleteTiny(t *testing.T) {
+	server, _ := newFakeServer(t)
+	body := `{"metadata":{"name":"a"},"spec":{"framework":{"name":"pytorch","version":"1.9"}}}`
+	w := do(t, server, http.MethodPost, "/api/v1/namespaces/default/tinies", testToken, body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body)
+	}
+
+	w = do(t, server, http.MethodGet, "/api/v1/namespaces/default/tinies", testToken, "")
+	list := &corev1.TinyList{}
+	if err := json.Unmarshal(w.Body.Bytes(), list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "a" || list.Items[0].Spec.Framework.Name != "pytorch" {
+		t.Fatalf("unexpected list %+v", list.Items)
+	}
+
+	w = do(t, server, http.MethodDelete, "/api/v1/namespaces/default/tinies/a", testToken, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete: expected 200, got %d", w.Code)
+	}
+	w = do(t, server, http.MethodGet, "/api/v1/namespaces/default/tinies/a", testToken, "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("get: expected 404, got %d", w.Code)
+	}
+}
+
+func TestCreateRequiresName(t *testing.T) {
+	server, _ := newFakeServer(t)
+	w := do(t, server, http.MethodPost, "/api/v1/namespaces/default/units", testToken, `{"spec":{}}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestSuspendTiny(t *testing.T) {
+	server, c := newFakeServer(t,
+		&corev1.Tiny{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"}},
+		&corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"}},
+	)
+	w := do(t, server, http.MethodPost, "/api/v1/namespaces/default/tinies/a/suspend", testToken, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("suspend: expected 200, got %d: %s", w.Code, w.Body)
+	}
+	unit := &corev1.Unit{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "a"}, unit); err != nil {
+		t.Fatal(err)
+	}
+	if !unit.Spec.Suspend {
+		t.Fatal("expected unit to be suspended")
+	}
+
+	do(t, server, http.MethodPost, "/api/v1/namespaces/default/tinies/a/resume", testToken, "")
+	unit = &corev1.Unit{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "a"}, unit); err != nil {
+		t.Fatal(err)
+	}
+	if unit.Spec.Suspend {
+		t.Fatal("expected unit to be resumed")
+	}
+}
+
+func TestStatusAndLogs(t *testing.T) {
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"}}
+	unit.Status.Phase = "Running"
+	server, _ := newFakeServer(t, unit)
+
+	w := do(t, server, http.MethodGet, "/api/v1/namespaces/default/units/a/status", testToken, "")
+	status := &corev1.UnitStatus{}
+	if err := json.Unmarshal(w.Body.Bytes(), status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Phase != "Running" {
+		t.Errorf("expected Running, got %q", status.Phase)
+	}
+
+	w = do(t, server, http.MethodGet, "/api/v1/namespaces/default/units/a/logs?follow=true&tailLines=10", testToken, "")
+	if w.Code != http.StatusOK || w.Body.String() != "fake logs" {
+		t.Errorf("unexpected logs %d %q", w.Code, w.Body)
+	}
+}
+
+func TestUsageReports(t *testing.T) {
+	report := &corev1.UsageReport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "daily-2021-10-19"},
+		Spec: corev1.UsageReportSpec{
+			Period: corev1.UsageDaily,
+			Start:  metav1.NewTime(time.Date(2021, 10, 19, 0, 0, 0, 0, time.UTC)),
+			End:    metav1.NewTime(time.Date(2021, 10, 20, 0, 0, 0, 0, time.UTC)),
+		},
+	}
+	report.Status.Entries = []corev1.UsageEntry{{
+		User: "alice", GPUModel: "RTX-3090", Units: 1,
+		RunningHours: "2.00", GPUHours: "4.00", CPUCoreHours: "8.00", MemoryGiBHours: "32.00",
+	}}
+	server, _ := newFakeServer(t, report)
+
+	w := do(t, server, http.MethodGet, "/api/v1/namespaces/default/usagereports?format=csv", testToken, "")
+	expected := "namespace,report,period,start,end,project,user,gpu_model,units,running_hours,gpu_hours,cpu_core_hours,memory_gib_hours\n" +
+		"default,daily-2021-10-19,Daily,2021-10-19T00:00:00Z,2021-10-20T00:00:00Z,,alice,RTX-3090,1,2.00,4.00,8.00,32.00\n"
+	if w.Code != http.StatusOK || w.Body.String() != expected {
+		t.Errorf("unexpected csv %d %q", w.Code, w.Body)
+	}
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/namespaces/default/usagereports"},
+		{http.MethodDelete, "/api/v1/namespaces/default/usagereports/daily-2021-10-19"},
+		{http.MethodPost, "/api/v1/namespaces/default/usagereports/daily-2021-10-19/suspend"},
+	} {
+		if w := do(t, server, req.method, req.path, testToken, "{}"); w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: expected 405, got %d", req.method, req.path, w.Code)
+		}
+	}
+}
+
+// startEnv 启动 envtest，缺少 envtest 二进制文件时跳过测试
+func startEnv(t *testing.T) (*envtest.Environment, *rest.Config) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		if _, err := os.Stat("/usr/local/kubebuilder/bin/kube-apiserver"); err != nil {
+			t.Skip("envtest binaries are not available")
+		}
+	}
+	env := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "config", "crd", "bases")},
+		ErrorIfCRDPathMissing: true,
+	}
+	config, err := env.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env, config
+}
+
+// TestImpersonation 在 envtest 中验证请求以调用者身份执行，RBAC 对其生效
+func TestImpersonation(t *testing.T) {
+	env, config := startEnv(t)
+	defer func() {
+		_ = env.Stop()
+	}()
+
+	scheme := zerotest.NewScheme()
+	server, err := New(config, scheme, testAuthenticator(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := do(t, server, http.MethodGet, "/api/v1/namespaces/default/tinies", testToken, "")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 before binding, got %d: %s", w.Code, w.Body)
+	}
+
+	ctx := context.TODO()
+	role := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "tiny-reader"},
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{corev1.GroupVersion.Group}, Resources: []string{"tinies"}, Verbs: []string{"get", "list"}},
+		},
+	}
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "students"},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: role.Name},
+		Subjects:   []rbacv1.Subject{{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "students"}},
+	}
+	for _, obj := range []client.Object{role, binding} {
+		if err := admin.Create(ctx, obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w = do(t, server, http.MethodGet, "/api/v1/namespaces/default/tinies", testToken, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 after binding, got %d: %s", w.Code, w.Body)
+	}
+	w = do(t, server, http.MethodPost, "/api/v1/namespaces/default/tinies", testToken, `{"metadata":{"name":"a"}}`)
+	if w.Code != http.StatusForbidden {
+		body, _ := ioutil.ReadAll(w.Body)
+		t.Fatalf("expected 403 on create, got %d: %s", w.Code, body)
+	}
+}
@@ -0,0 +1,58 @@
+package apiserver
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// usageHeader UsageReport 导出为 CSV 时的列，每个用户与 GPU 型号一行
+var usageHeader = []string{
+	"namespace", "report", "period", "start", "end", "project", "user", "gpu_model",
+	"units", "running_hours", "gpu_hours", "cpu_core_hours", "memory_gib_hours",
+}
+
+func usageRows(obj client.Object) [][]string {
+	report := obj.(*corev1.UsageReport)
+	rows := make([][]string, 0, len(report.Status.Entries))
+	for _, entry := range report.Status.Entries {
+		rows = append(rows, []string{
+			report.Namespace,
+			report.Name,
+			string(report.Spec.Period),
+			report.Spec.Start.Format(time.RFC3339),
+			report.Spec.End.Format(time.RFC3339),
+			report.Status.Project,
+			entry.User,
+			entry.GPUModel,
+			strconv.Itoa(int(entry.Units)),
+			entry.RunningHours,
+			entry.GPUHours,
+			entry.CPUCoreHours,
+			entry.MemoryGiBHours,
+		})
+	}
+	return rows
+}
+
+func (h *handler) wantCSV(r *http.Request) bool {
+	return h.kind.csv != nil && r.URL.Query().Get("format") == "csv"
+}
+
+func writeCSV(w http.ResponseWriter, header []string, rows [][]string) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		klog.Error(err)
+		return
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		klog.Error(err)
+	}
+}
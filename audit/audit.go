@@ -0,0 +1,222 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit 记录用户对 Unit、Tiny 与 Tunnel 的操作，写入 JSON Lines 文件、标准输出或 webhook
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var log = ctrl.Log.WithName("audit")
+
+// Action 审计的操作
+type Action string
+
+const (
+	ActionCreate  Action = "create"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	ActionSuspend Action = "suspend"
+	ActionResume  Action = "resume"
+)
+
+// Outcome 请求的结果
+type Outcome string
+
+const (
+	// OutcomeCommitted 控制器观察到了请求对应的变更
+	OutcomeCommitted Outcome = "committed"
+	// OutcomeNotCommitted 超时仍未观察到变更：请求被之后的准入校验或 API Server 拒绝，或没有改变对象
+	OutcomeNotCommitted Outcome = "notCommitted"
+	// OutcomeUnknown 控制器退出时仍在等待结果
+	OutcomeUnknown Outcome = "unknown"
+)
+
+const (
+	// DefaultBufferSize 等待写入的记录数，超过后丢弃新的记录，避免拖慢准入请求
+	DefaultBufferSize = 1024
+	// DefaultCommitTimeout 等待观察到变更的时间，超过后记为 notCommitted
+	DefaultCommitTimeout = 30 * time.Second
+
+	expirePeriod = time.Second
+)
+
+// Record 一条审计记录，序列化为一行 JSON
+type Record struct {
+	// Time 准入请求的时间
+	Time time.Time `json:"time"`
+	// Action 请求的操作，是否生效见 Outcome
+	Action    Action    `json:"action"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	UID       types.UID `json:"uid,omitempty"`
+	// User 发起请求的用户，控制器代替用户的操作为控制器的服务账号
+	User   string   `json:"user"`
+	Groups []string `json:"groups,omitempty"`
+	// CreatedBy 对象的创建者，来自 cokeos.io/created-by 注解
+	CreatedBy string `json:"createdBy,omitempty"`
+	// RequestUID 准入请求的 UID，可与 kube-apiserver 的审计日志关联
+	RequestUID types.UID `json:"requestUID,omitempty"`
+	// Outcome 请求的结果
+	Outcome Outcome `json:"outcome,omitempty"`
+}
+
+// Logger 异步将记录写入 Sink，作为 Runnable 随 Manager 启动
+//
+// 准入 webhook 只能看到请求，之后的校验仍可能拒绝它。请求先经 Attempt 暂存，
+// 观察到对象的变更后由 Commit 写入，超过 Timeout 仍未观察到时记为 notCommitted。
+type Logger struct {
+	Sink Sink
+	// Timeout 等待观察到变更的时间
+	Timeout time.Duration
+	Clock   clock.Clock
+
+	records chan Record
+	mu      sync.Mutex
+	pending map[types.UID][]Record
+}
+
+func NewLogger(sink Sink, size int) *Logger {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	return &Logger{
+		Sink:    sink,
+		Timeout: DefaultCommitTimeout,
+		Clock:   clock.RealClock{},
+		records: make(chan Record, size),
+		pending: make(map[types.UID][]Record),
+	}
+}
+
+// Attempt 暂存准入 webhook 放行的请求，等待 Commit 或超时
+func (l *Logger) Attempt(record Record) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending[record.UID] = append(l.pending[record.UID], record)
+}
+
+// Commit 对象 uid 的 action 变更已写入，按请求顺序确认最早的一个同类请求；没有等待中的请求时忽略
+func (l *Logger) Commit(uid types.UID, action Action) {
+	l.mu.Lock()
+	records := l.pending[uid]
+	var record *Record
+	for i := range records {
+		if records[i].Action == action {
+			committed := records[i]
+			record = &committed
+			records = append(records[:i:i], records[i+1:]...)
+			break
+		}
+	}
+	if len(records) == 0 {
+		delete(l.pending, uid)
+	} else {
+		l.pending[uid] = records
+	}
+	l.mu.Unlock()
+
+	if record != nil {
+		record.Outcome = OutcomeCommitted
+		l.Log(*record)
+	}
+}
+
+// expire 将等待超过 Timeout 的请求记为 notCommitted，all 为 true 时以 unknown 写入全部请求
+func (l *Logger) expire(all bool) {
+	deadline := l.Clock.Now().Add(-l.Timeout)
+	var expired []Record
+	l.mu.Lock()
+	for uid, records := range l.pending {
+		kept := records[:0]
+		for _, record := range records {
+			if all || !record.Time.After(deadline) {
+				expired = append(expired, record)
+			} else {
+				kept = append(kept, record)
+			}
+		}
+		if len(kept) == 0 {
+			delete(l.pending, uid)
+		} else {
+			l.pending[uid] = kept
+		}
+	}
+	l.mu.Unlock()
+
+	for _, record := range expired {
+		record.Outcome = OutcomeNotCommitted
+		if all {
+			record.Outcome = OutcomeUnknown
+		}
+		l.Log(record)
+	}
+}
+
+// Log 不阻塞，缓冲区已满时丢弃记录
+func (l *Logger) Log(record Record) {
+	select {
+	case l.records <- record:
+	default:
+		log.Info("Audit buffer full, dropped record", "action", record.Action, "kind", record.Kind,
+			"namespace", record.Namespace, "name", record.Name, "user", record.User)
+	}
+}
+
+// Start 写入记录直到 ctx 结束，结束前写完等待中与缓冲区中的记录
+func (l *Logger) Start(ctx context.Context) error {
+	defer l.Sink.Close()
+	ticker := l.Clock.NewTicker(expirePeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case record := <-l.records:
+			l.write(record)
+		case <-ticker.C():
+			l.expire(false)
+		case <-ctx.Done():
+			l.expire(true)
+			for {
+				select {
+				case record := <-l.records:
+					l.write(record)
+				default:
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// NeedLeaderElection 每个副本都会处理准入请求，都需要写入审计记录
+func (l *Logger) NeedLeaderElection() bool {
+	return false
+}
+
+func (l *Logger) write(record Record) {
+	if err := l.Sink.Write(record); err != nil {
+		log.Error(err, "Failed to write audit record", "action", record.Action, "kind", record.Kind,
+			"namespace", record.Namespace, "name", record.Name)
+	}
+}
@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewLogger(sink, 2)
+	now := time.Date(2021, 10, 19, 9, 0, 0, 0, time.UTC)
+	logger.Log(Record{Time: now, Action: ActionCreate, Kind: "Unit", Namespace: "alice", Name: "train", User: "alice"})
+	logger.Log(Record{Time: now, Action: ActionSuspend, Kind: "Unit", Namespace: "alice", Name: "train", User: "alice"})
+	// 缓冲区已满，丢弃
+	logger.Log(Record{Time: now, Action: ActionDelete, Kind: "Unit", Namespace: "alice", Name: "train", User: "alice"})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if err := logger.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var actions []Action
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		record := Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		actions = append(actions, record.Action)
+	}
+	if len(actions) != 2 || actions[0] != ActionCreate || actions[1] != ActionSuspend {
+		t.Errorf("unexpected records %v", actions)
+	}
+}
+
+// memorySink 保存写入的记录
+type memorySink struct {
+	records []Record
+}
+
+func (s *memorySink) Write(record Record) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	return nil
+}
+
+func TestLoggerCommit(t *testing.T) {
+	sink := &memorySink{}
+	logger := NewLogger(sink, 10)
+	fakeClock := clock.NewFakeClock(time.Date(2021, 10, 19, 9, 0, 0, 0, time.UTC))
+	logger.Clock = fakeClock
+	logger.Attempt(Record{Time: fakeClock.Now(), Action: ActionCreate, Kind: "Unit", Name: "train", UID: "train"})
+	logger.Attempt(Record{Time: fakeClock.Now(), Action: ActionUpdate, Kind: "Unit", Name: "train", UID: "train"})
+	logger.Attempt(Record{Time: fakeClock.Now(), Action: ActionCreate, Kind: "Unit", Name: "rejected", UID: "rejected"})
+	fakeClock.Step(DefaultCommitTimeout / 2)
+	logger.Attempt(Record{Time: fakeClock.Now(), Action: ActionDelete, Kind: "Unit", Name: "train", UID: "train"})
+
+	// 没有等待中的请求时忽略，例如控制器的 status 更新
+	logger.Commit("train", ActionSuspend)
+	logger.Commit("train", ActionCreate)
+	fakeClock.Step(DefaultCommitTimeout / 2)
+	logger.expire(false)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if err := logger.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		name    string
+		action  Action
+		outcome Outcome
+	}{
+		{"train", ActionCreate, OutcomeCommitted},
+		{"train", ActionUpdate, OutcomeNotCommitted},
+		{"rejected", ActionCreate, OutcomeNotCommitted},
+		{"train", ActionDelete, OutcomeUnknown},
+	}
+	if len(sink.records) != len(expected) {
+		t.Fatalf("unexpected records %+v", sink.records)
+	}
+	// 同一时刻超时的记录顺序不确定
+	for _, e := range expected {
+		found := false
+		for _, record := range sink.records {
+			if record.Name == e.name && record.Action == e.action && record.Outcome == e.outcome {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected %s %s to be %s, got %+v", e.action, e.name, e.outcome, sink.records)
+		}
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received Record
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(Record{Action: ActionDelete, Kind: "Tiny", Namespace: "alice", Name: "dev", User: "admin"}); err != nil {
+		t.Fatal(err)
+	}
+	if received.Action != ActionDelete || received.Kind != "Tiny" || received.User != "admin" {
+		t.Errorf("unexpected record %+v", received)
+	}
+
+	failing := &WebhookSink{URL: server.URL + "/missing", Client: server.Client()}
+	server.Config.Handler = http.NotFoundHandler()
+	if err := failing.Write(Record{}); err == nil {
+		t.Error("expected an error for a 404 response")
+	}
+}
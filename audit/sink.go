@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWebhookTimeout 单次推送的超时时间
+const DefaultWebhookTimeout = 5 * time.Second
+
+// Sink 审计记录的去向
+type Sink interface {
+	Write(record Record) error
+	Close() error
+}
+
+// NewSink 根据参数创建 Sink：stdout、http(s):// 开头的 webhook 地址，或 JSON Lines 文件路径
+func NewSink(target string) (Sink, error) {
+	switch {
+	case target == "stdout" || target == "-":
+		return &WriterSink{Writer: os.Stdout}, nil
+	case strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://"):
+		return &WebhookSink{URL: target}, nil
+	case target == "":
+		return nil, fmt.Errorf("empty audit sink")
+	default:
+		return NewFileSink(strings.TrimPrefix(target, "file://"))
+	}
+}
+
+// WriterSink 每条记录写为一行 JSON
+type WriterSink struct {
+	Writer io.Writer
+
+	mu sync.Mutex
+}
+
+func (s *WriterSink) Write(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.Writer.Write(append(line, '\n'))
+	return err
+}
+
+func (s *WriterSink) Close() error {
+	if closer, ok := s.Writer.(io.Closer); ok && s.Writer != os.Stdout {
+		return closer.Close()
+	}
+	return nil
+}
+
+// NewFileSink 以追加方式打开 JSON Lines 文件
+func NewFileSink(path string) (*WriterSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, err
+	}
+	return &WriterSink{Writer: f}, nil
+}
+
+// WebhookSink 将每条记录以 JSON POST 到 URL，非 2xx 响应视为失败
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *WebhookSink) Write(record Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultWebhookTimeout}
+	}
+	resp, err := client.Post(s.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook %s returned %s", s.URL, resp.Status)
+	}
+	return nil
+}
+
+func (s *WebhookSink) Close() error {
+	return nil
+}
@@ -3,6 +3,7 @@ package client
 import (
 	corev1 "github.com/cokeos/zero/api/v1"
 
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -21,8 +22,20 @@ func init() {
 	utilruntime.Must(corev1.AddToScheme(scheme))
 }
 
+// NewClient 使用 --kubeconfig、KUBECONFIG、集群内配置或 ~/.kube/config 创建客户端，找不到配置时返回错误
+//
+// 需要类型化客户端、Informer 与 Lister 时使用 clientset、informers 与 listers 子包。
 func NewClient() (client.Client, error) {
-	apiReader, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return NewClientForConfig(config)
+}
+
+// NewClientForConfig 使用指定的集群配置创建客户端
+func NewClientForConfig(config *rest.Config) (client.Client, error) {
+	apiReader, err := client.New(config, client.Options{Scheme: scheme})
 	if err != nil {
 		return nil, err
 	}
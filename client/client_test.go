@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/client/clientset/versioned/fake"
+	"github.com/cokeos/zero/client/informers/externalversions"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestFakeClientset(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Tiny{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "demo"},
+	})
+	ctx := context.TODO()
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "demo"}}
+	if _, err := clientset.ZeroV1().Units("default").Create(ctx, unit, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tiny, err := clientset.ZeroV1().Tinies("default").Get(ctx, "demo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tiny.Status.Phase = "Running"
+	if _, err := clientset.ZeroV1().Tinies("default").UpdateStatus(ctx, tiny, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	factory := externalversions.NewSharedInformerFactory(clientset, time.Minute)
+	tinies := factory.Zero().V1().Tinies()
+	units := factory.Zero().V1().Units()
+	// Informer 需在 Start 之前注册
+	tinies.Informer()
+	units.Informer()
+	stop := make(chan struct{})
+	defer close(stop)
+	factory.Start(stop)
+	if !cache.WaitForCacheSync(stop, tinies.Informer().HasSynced, units.Informer().HasSynced) {
+		t.Fatal("cache not synced")
+	}
+
+	cached, err := tinies.Lister().Tinies("default").Get("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.Status.Phase != "Running" {
+		t.Errorf("expected Running, got %q", cached.Status.Phase)
+	}
+	list, err := units.Lister().List(labels.Everything())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Errorf("expected 1 unit, got %d", len(list))
+	}
+	if _, err := tinies.Lister().Tinies("default").Get("missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
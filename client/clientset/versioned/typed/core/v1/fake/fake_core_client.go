@@ -0,0 +1,55 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1 "github.com/cokeos/zero/client/clientset/versioned/typed/core/v1"
+	rest "k8s.io/client-go/rest"
+	testing "k8s.io/client-go/testing"
+)
+
+type FakeZeroV1 struct {
+	*testing.Fake
+}
+
+func (c *FakeZeroV1) ScheduledUnits(namespace string) v1.ScheduledUnitInterface {
+	return &FakeScheduledUnits{c, namespace}
+}
+
+func (c *FakeZeroV1) Tinies(namespace string) v1.TinyInterface {
+	return &FakeTinies{c, namespace}
+}
+
+func (c *FakeZeroV1) Tunnels(namespace string) v1.TunnelInterface {
+	return &FakeTunnels{c, namespace}
+}
+
+func (c *FakeZeroV1) Units(namespace string) v1.UnitInterface {
+	return &FakeUnits{c, namespace}
+}
+
+func (c *FakeZeroV1) UsageReports(namespace string) v1.UsageReportInterface {
+	return &FakeUsageReports{c, namespace}
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *FakeZeroV1) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeScheduledUnits implements ScheduledUnitInterface
+type FakeScheduledUnits struct {
+	Fake *FakeZeroV1
+	ns   string
+}
+
+var scheduledunitsResource = schema.GroupVersionResource{Group: "core.cokeos.io", Version: "v1", Resource: "scheduledunits"}
+
+var scheduledunitsKind = schema.GroupVersionKind{Group: "core.cokeos.io", Version: "v1", Kind: "ScheduledUnit"}
+
+// Get takes name of the scheduledUnit, and returns the corresponding scheduledUnit object, and an error if there is any.
+func (c *FakeScheduledUnits) Get(ctx context.Context, name string, options v1.GetOptions) (result *corev1.ScheduledUnit, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(scheduledunitsResource, c.ns, name), &corev1.ScheduledUnit{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.ScheduledUnit), err
+}
+
+// List takes label and field selectors, and returns the list of ScheduledUnits that match those selectors.
+func (c *FakeScheduledUnits) List(ctx context.Context, opts v1.ListOptions) (result *corev1.ScheduledUnitList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(scheduledunitsResource, scheduledunitsKind, c.ns, opts), &corev1.ScheduledUnitList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &corev1.ScheduledUnitList{ListMeta: obj.(*corev1.ScheduledUnitList).ListMeta}
+	for _, item := range obj.(*corev1.ScheduledUnitList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested scheduledUnits.
+func (c *FakeScheduledUnits) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(scheduledunitsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a scheduledUnit and creates it.  Returns the server's representation of the scheduledUnit, and an error, if there is any.
+func (c *FakeScheduledUnits) Create(ctx context.Context, scheduledUnit *corev1.ScheduledUnit, opts v1.CreateOptions) (result *corev1.ScheduledUnit, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(scheduledunitsResource, c.ns, scheduledUnit), &corev1.ScheduledUnit{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.ScheduledUnit), err
+}
+
+// Update takes the representation of a scheduledUnit and updates it. Returns the server's representation of the scheduledUnit, and an error, if there is any.
+func (c *FakeScheduledUnits) Update(ctx context.Context, scheduledUnit *corev1.ScheduledUnit, opts v1.UpdateOptions) (result *corev1.ScheduledUnit, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(scheduledunitsResource, c.ns, scheduledUnit), &corev1.ScheduledUnit{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.ScheduledUnit), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeScheduledUnits) UpdateStatus(ctx context.Context, scheduledUnit *corev1.ScheduledUnit, opts v1.UpdateOptions) (*corev1.ScheduledUnit, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(scheduledunitsResource, "status", c.ns, scheduledUnit), &corev1.ScheduledUnit{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.ScheduledUnit), err
+}
+
+// Delete takes name of the scheduledUnit and deletes it. Returns an error if one occurs.
+func (c *FakeScheduledUnits) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(scheduledunitsResource, c.ns, name), &corev1.ScheduledUnit{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeScheduledUnits) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(scheduledunitsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &corev1.ScheduledUnitList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched scheduledUnit.
+func (c *FakeScheduledUnits) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *corev1.ScheduledUnit, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(scheduledunitsResource, c.ns, name, pt, data, subresources...), &corev1.ScheduledUnit{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.ScheduledUnit), err
+}
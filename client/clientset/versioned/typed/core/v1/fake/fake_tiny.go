@@ -0,0 +1,141 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeTinies implements TinyInterface
+type FakeTinies struct {
+	Fake *FakeZeroV1
+	ns   string
+}
+
+var tiniesResource = schema.GroupVersionResource{Group: "core.cokeos.io", Version: "v1", Resource: "tinies"}
+
+var tiniesKind = schema.GroupVersionKind{Group: "core.cokeos.io", Version: "v1", Kind: "Tiny"}
+
+// Get takes name of the tiny, and returns the corresponding tiny object, and an error if there is any.
+func (c *FakeTinies) Get(ctx context.Context, name string, options v1.GetOptions) (result *corev1.Tiny, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(tiniesResource, c.ns, name), &corev1.Tiny{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.Tiny), err
+}
+
+// List takes label and field selectors, and returns the list of Tinies that match those selectors.
+func (c *FakeTinies) List(ctx context.Context, opts v1.ListOptions) (result *corev1.TinyList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(tiniesResource, tiniesKind, c.ns, opts), &corev1.TinyList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &corev1.TinyList{ListMeta: obj.(*corev1.TinyList).ListMeta}
+	for _, item := range obj.(*corev1.TinyList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested tinies.
+func (c *FakeTinies) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(tiniesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a tiny and creates it.  Returns the server's representation of the tiny, and an error, if there is any.
+func (c *FakeTinies) Create(ctx context.Context, tiny *corev1.Tiny, opts v1.CreateOptions) (result *corev1.Tiny, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(tiniesResource, c.ns, tiny), &corev1.Tiny{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.Tiny), err
+}
+
+// Update takes the representation of a tiny and updates it. Returns the server's representation of the tiny, and an error, if there is any.
+func (c *FakeTinies) Update(ctx context.Context, tiny *corev1.Tiny, opts v1.UpdateOptions) (result *corev1.Tiny, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(tiniesResource, c.ns, tiny), &corev1.Tiny{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.Tiny), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeTinies) UpdateStatus(ctx context.Context, tiny *corev1.Tiny, opts v1.UpdateOptions) (*corev1.Tiny, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(tiniesResource, "status", c.ns, tiny), &corev1.Tiny{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.Tiny), err
+}
+
+// Delete takes name of the tiny and deletes it. Returns an error if one occurs.
+func (c *FakeTinies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(tiniesResource, c.ns, name), &corev1.Tiny{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeTinies) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(tiniesResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &corev1.TinyList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched tiny.
+func (c *FakeTinies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *corev1.Tiny, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(tiniesResource, c.ns, name, pt, data, subresources...), &corev1.Tiny{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.Tiny), err
+}
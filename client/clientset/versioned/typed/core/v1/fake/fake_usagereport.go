@@ -0,0 +1,141 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeUsageReports implements UsageReportInterface
+type FakeUsageReports struct {
+	Fake *FakeZeroV1
+	ns   string
+}
+
+var usagereportsResource = schema.GroupVersionResource{Group: "core.cokeos.io", Version: "v1", Resource: "usagereports"}
+
+var usagereportsKind = schema.GroupVersionKind{Group: "core.cokeos.io", Version: "v1", Kind: "UsageReport"}
+
+// Get takes name of the usageReport, and returns the corresponding usageReport object, and an error if there is any.
+func (c *FakeUsageReports) Get(ctx context.Context, name string, options v1.GetOptions) (result *corev1.UsageReport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(usagereportsResource, c.ns, name), &corev1.UsageReport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.UsageReport), err
+}
+
+// List takes label and field selectors, and returns the list of UsageReports that match those selectors.
+func (c *FakeUsageReports) List(ctx context.Context, opts v1.ListOptions) (result *corev1.UsageReportList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(usagereportsResource, usagereportsKind, c.ns, opts), &corev1.UsageReportList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &corev1.UsageReportList{ListMeta: obj.(*corev1.UsageReportList).ListMeta}
+	for _, item := range obj.(*corev1.UsageReportList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested usageReports.
+func (c *FakeUsageReports) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(usagereportsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a usageReport and creates it.  Returns the server's representation of the usageReport, and an error, if there is any.
+func (c *FakeUsageReports) Create(ctx context.Context, usageReport *corev1.UsageReport, opts v1.CreateOptions) (result *corev1.UsageReport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(usagereportsResource, c.ns, usageReport), &corev1.UsageReport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.UsageReport), err
+}
+
+// Update takes the representation of a usageReport and updates it. Returns the server's representation of the usageReport, and an error, if there is any.
+func (c *FakeUsageReports) Update(ctx context.Context, usageReport *corev1.UsageReport, opts v1.UpdateOptions) (result *corev1.UsageReport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(usagereportsResource, c.ns, usageReport), &corev1.UsageReport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.UsageReport), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeUsageReports) UpdateStatus(ctx context.Context, usageReport *corev1.UsageReport, opts v1.UpdateOptions) (*corev1.UsageReport, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(usagereportsResource, "status", c.ns, usageReport), &corev1.UsageReport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.UsageReport), err
+}
+
+// Delete takes name of the usageReport and deletes it. Returns an error if one occurs.
+func (c *FakeUsageReports) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(usagereportsResource, c.ns, name), &corev1.UsageReport{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeUsageReports) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(usagereportsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &corev1.UsageReportList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched usageReport.
+func (c *FakeUsageReports) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *corev1.UsageReport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(usagereportsResource, c.ns, name, pt, data, subresources...), &corev1.UsageReport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.UsageReport), err
+}
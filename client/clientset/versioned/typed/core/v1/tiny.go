@@ -0,0 +1,194 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/cokeos/zero/api/v1"
+	scheme "github.com/cokeos/zero/client/clientset/versioned/scheme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// TiniesGetter has a method to return a TinyInterface.
+// A group's client should implement this interface.
+type TiniesGetter interface {
+	Tinies(namespace string) TinyInterface
+}
+
+// TinyInterface has methods to work with Tiny resources.
+type TinyInterface interface {
+	Create(ctx context.Context, tiny *v1.Tiny, opts metav1.CreateOptions) (*v1.Tiny, error)
+	Update(ctx context.Context, tiny *v1.Tiny, opts metav1.UpdateOptions) (*v1.Tiny, error)
+	UpdateStatus(ctx context.Context, tiny *v1.Tiny, opts metav1.UpdateOptions) (*v1.Tiny, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Tiny, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.TinyList, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.Tiny, err error)
+	TinyExpansion
+}
+
+// tinies implements TinyInterface
+type tinies struct {
+	client rest.Interface
+	ns     string
+}
+
+// newTinies returns a Tinies
+func newTinies(c *ZeroV1Client, namespace string) *tinies {
+	return &tinies{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the tiny, and returns the corresponding tiny object, and an error if there is any.
+func (c *tinies) Get(ctx context.Context, name string, options metav1.GetOptions) (result *v1.Tiny, err error) {
+	result = &v1.Tiny{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("tinies").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of Tinies that match those selectors.
+func (c *tinies) List(ctx context.Context, opts metav1.ListOptions) (result *v1.TinyList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1.TinyList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("tinies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested tinies.
+func (c *tinies) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("tinies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a tiny and creates it.  Returns the server's representation of the tiny, and an error, if there is any.
+func (c *tinies) Create(ctx context.Context, tiny *v1.Tiny, opts metav1.CreateOptions) (result *v1.Tiny, err error) {
+	result = &v1.Tiny{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("tinies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(tiny).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a tiny and updates it. Returns the server's representation of the tiny, and an error, if there is any.
+func (c *tinies) Update(ctx context.Context, tiny *v1.Tiny, opts metav1.UpdateOptions) (result *v1.Tiny, err error) {
+	result = &v1.Tiny{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("tinies").
+		Name(tiny.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(tiny).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *tinies) UpdateStatus(ctx context.Context, tiny *v1.Tiny, opts metav1.UpdateOptions) (result *v1.Tiny, err error) {
+	result = &v1.Tiny{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("tinies").
+		Name(tiny.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(tiny).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the tiny and deletes it. Returns an error if one occurs.
+func (c *tinies) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("tinies").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *tinies) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("tinies").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched tiny.
+func (c *tinies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.Tiny, err error) {
+	result = &v1.Tiny{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("tinies").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	internalinterfaces "github.com/cokeos/zero/client/informers/externalversions/internalinterfaces"
+)
+
+// Interface provides access to all the informers in this group version.
+type Interface interface {
+	// ScheduledUnits returns a ScheduledUnitInformer.
+	ScheduledUnits() ScheduledUnitInformer
+	// Tinies returns a TinyInformer.
+	Tinies() TinyInformer
+	// Tunnels returns a TunnelInformer.
+	Tunnels() TunnelInformer
+	// Units returns a UnitInformer.
+	Units() UnitInformer
+	// UsageReports returns a UsageReportInformer.
+	UsageReports() UsageReportInformer
+}
+
+type version struct {
+	factory          internalinterfaces.SharedInformerFactory
+	namespace        string
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// New returns a new Interface.
+func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakListOptions internalinterfaces.TweakListOptionsFunc) Interface {
+	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
+}
+
+// ScheduledUnits returns a ScheduledUnitInformer.
+func (v *version) ScheduledUnits() ScheduledUnitInformer {
+	return &scheduledUnitInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// Tinies returns a TinyInformer.
+func (v *version) Tinies() TinyInformer {
+	return &tinyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// Tunnels returns a TunnelInformer.
+func (v *version) Tunnels() TunnelInformer {
+	return &tunnelInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// Units returns a UnitInformer.
+func (v *version) Units() UnitInformer {
+	return &unitInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// UsageReports returns a UsageReportInformer.
+func (v *version) UsageReports() UsageReportInformer {
+	return &usageReportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	time "time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	versioned "github.com/cokeos/zero/client/clientset/versioned"
+	internalinterfaces "github.com/cokeos/zero/client/informers/externalversions/internalinterfaces"
+	v1 "github.com/cokeos/zero/client/listers/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// ScheduledUnitInformer provides access to a shared informer and lister for
+// ScheduledUnits.
+type ScheduledUnitInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1.ScheduledUnitLister
+}
+
+type scheduledUnitInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewScheduledUnitInformer constructs a new informer for ScheduledUnit type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewScheduledUnitInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredScheduledUnitInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredScheduledUnitInformer constructs a new informer for ScheduledUnit type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredScheduledUnitInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.ZeroV1().ScheduledUnits(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.ZeroV1().ScheduledUnits(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&corev1.ScheduledUnit{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *scheduledUnitInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredScheduledUnitInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *scheduledUnitInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&corev1.ScheduledUnit{}, f.defaultInformer)
+}
+
+func (f *scheduledUnitInformer) Lister() v1.ScheduledUnitLister {
+	return v1.NewScheduledUnitLister(f.Informer().GetIndexer())
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	time "time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	versioned "github.com/cokeos/zero/client/clientset/versioned"
+	internalinterfaces "github.com/cokeos/zero/client/informers/externalversions/internalinterfaces"
+	v1 "github.com/cokeos/zero/client/listers/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// TinyInformer provides access to a shared informer and lister for
+// Tinies.
+type TinyInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1.TinyLister
+}
+
+type tinyInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewTinyInformer constructs a new informer for Tiny type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewTinyInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredTinyInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredTinyInformer constructs a new informer for Tiny type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredTinyInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.ZeroV1().Tinies(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.ZeroV1().Tinies(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&corev1.Tiny{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *tinyInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredTinyInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *tinyInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&corev1.Tiny{}, f.defaultInformer)
+}
+
+func (f *tinyInformer) Lister() v1.TinyLister {
+	return v1.NewTinyLister(f.Informer().GetIndexer())
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1
+
+// ScheduledUnitListerExpansion allows custom methods to be added to
+// ScheduledUnitLister.
+type ScheduledUnitListerExpansion interface{}
+
+// ScheduledUnitNamespaceListerExpansion allows custom methods to be added to
+// ScheduledUnitNamespaceLister.
+type ScheduledUnitNamespaceListerExpansion interface{}
+
+// TinyListerExpansion allows custom methods to be added to
+// TinyLister.
+type TinyListerExpansion interface{}
+
+// TinyNamespaceListerExpansion allows custom methods to be added to
+// TinyNamespaceLister.
+type TinyNamespaceListerExpansion interface{}
+
+// TunnelListerExpansion allows custom methods to be added to
+// TunnelLister.
+type TunnelListerExpansion interface{}
+
+// TunnelNamespaceListerExpansion allows custom methods to be added to
+// TunnelNamespaceLister.
+type TunnelNamespaceListerExpansion interface{}
+
+// UnitListerExpansion allows custom methods to be added to
+// UnitLister.
+type UnitListerExpansion interface{}
+
+// UnitNamespaceListerExpansion allows custom methods to be added to
+// UnitNamespaceLister.
+type UnitNamespaceListerExpansion interface{}
+
+// UsageReportListerExpansion allows custom methods to be added to
+// UsageReportLister.
+type UsageReportListerExpansion interface{}
+
+// UsageReportNamespaceListerExpansion allows custom methods to be added to
+// UsageReportNamespaceLister.
+type UsageReportNamespaceListerExpansion interface{}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	v1 "github.com/cokeos/zero/api/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ScheduledUnitLister helps list ScheduledUnits.
+// All objects returned here must be treated as read-only.
+type ScheduledUnitLister interface {
+	// List lists all ScheduledUnits in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1.ScheduledUnit, err error)
+	// ScheduledUnits returns an object that can list and get ScheduledUnits.
+	ScheduledUnits(namespace string) ScheduledUnitNamespaceLister
+	ScheduledUnitListerExpansion
+}
+
+// scheduledUnitLister implements the ScheduledUnitLister interface.
+type scheduledUnitLister struct {
+	indexer cache.Indexer
+}
+
+// NewScheduledUnitLister returns a new ScheduledUnitLister.
+func NewScheduledUnitLister(indexer cache.Indexer) ScheduledUnitLister {
+	return &scheduledUnitLister{indexer: indexer}
+}
+
+// List lists all ScheduledUnits in the indexer.
+func (s *scheduledUnitLister) List(selector labels.Selector) (ret []*v1.ScheduledUnit, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1.ScheduledUnit))
+	})
+	return ret, err
+}
+
+// ScheduledUnits returns an object that can list and get ScheduledUnits.
+func (s *scheduledUnitLister) ScheduledUnits(namespace string) ScheduledUnitNamespaceLister {
+	return scheduledUnitNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// ScheduledUnitNamespaceLister helps list and get ScheduledUnits.
+// All objects returned here must be treated as read-only.
+type ScheduledUnitNamespaceLister interface {
+	// List lists all ScheduledUnits in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1.ScheduledUnit, err error)
+	// Get retrieves the ScheduledUnit from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1.ScheduledUnit, error)
+	ScheduledUnitNamespaceListerExpansion
+}
+
+// scheduledUnitNamespaceLister implements the ScheduledUnitNamespaceLister
+// interface.
+type scheduledUnitNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all ScheduledUnits in the indexer for a given namespace.
+func (s scheduledUnitNamespaceLister) List(selector labels.Selector) (ret []*v1.ScheduledUnit, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1.ScheduledUnit))
+	})
+	return ret, err
+}
+
+// Get retrieves the ScheduledUnit from the indexer for a given namespace and name.
+func (s scheduledUnitNamespaceLister) Get(name string) (*v1.ScheduledUnit, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1.Resource("scheduledunit"), name)
+	}
+	return obj.(*v1.ScheduledUnit), nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	v1 "github.com/cokeos/zero/api/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// TinyLister helps list Tinies.
+// All objects returned here must be treated as read-only.
+type TinyLister interface {
+	// List lists all Tinies in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1.Tiny, err error)
+	// Tinies returns an object that can list and get Tinies.
+	Tinies(namespace string) TinyNamespaceLister
+	TinyListerExpansion
+}
+
+// tinyLister implements the TinyLister interface.
+type tinyLister struct {
+	indexer cache.Indexer
+}
+
+// NewTinyLister returns a new TinyLister.
+func NewTinyLister(indexer cache.Indexer) TinyLister {
+	return &tinyLister{indexer: indexer}
+}
+
+// List lists all Tinies in the indexer.
+func (s *tinyLister) List(selector labels.Selector) (ret []*v1.Tiny, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1.Tiny))
+	})
+	return ret, err
+}
+
+// Tinies returns an object that can list and get Tinies.
+func (s *tinyLister) Tinies(namespace string) TinyNamespaceLister {
+	return tinyNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// TinyNamespaceLister helps list and get Tinies.
+// All objects returned here must be treated as read-only.
+type TinyNamespaceLister interface {
+	// List lists all Tinies in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1.Tiny, err error)
+	// Get retrieves the Tiny from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1.Tiny, error)
+	TinyNamespaceListerExpansion
+}
+
+// tinyNamespaceLister implements the TinyNamespaceLister
+// interface.
+type tinyNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all Tinies in the indexer for a given namespace.
+func (s tinyNamespaceLister) List(selector labels.Selector) (ret []*v1.Tiny, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1.Tiny))
+	})
+	return ret, err
+}
+
+// Get retrieves the Tiny from the indexer for a given namespace and name.
+func (s tinyNamespaceLister) Get(name string) (*v1.Tiny, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1.Resource("tiny"), name)
+	}
+	return obj.(*v1.Tiny), nil
+}
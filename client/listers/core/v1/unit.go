@@ -0,0 +1,98 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	v1 "github.com/cokeos/zero/api/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// UnitLister helps list Units.
+// All objects returned here must be treated as read-only.
+type UnitLister interface {
+	// List lists all Units in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1.Unit, err error)
+	// Units returns an object that can list and get Units.
+	Units(namespace string) UnitNamespaceLister
+	UnitListerExpansion
+}
+
+// unitLister implements the UnitLister interface.
+type unitLister struct {
+	indexer cache.Indexer
+}
+
+// NewUnitLister returns a new UnitLister.
+func NewUnitLister(indexer cache.Indexer) UnitLister {
+	return &unitLister{indexer: indexer}
+}
+
+// List lists all Units in the indexer.
+func (s *unitLister) List(selector labels.Selector) (ret []*v1.Unit, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1.Unit))
+	})
+	return ret, err
+}
+
+// Units returns an object that can list and get Units.
+func (s *unitLister) Units(namespace string) UnitNamespaceLister {
+	return unitNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// UnitNamespaceLister helps list and get Units.
+// All objects returned here must be treated as read-only.
+type UnitNamespaceLister interface {
+	// List lists all Units in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1.Unit, err error)
+	// Get retrieves the Unit from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1.Unit, error)
+	UnitNamespaceListerExpansion
+}
+
+// unitNamespaceLister implements the UnitNamespaceLister
+// interface.
+type unitNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all Units in the indexer for a given namespace.
+func (s unitNamespaceLister) List(selector labels.Selector) (ret []*v1.Unit, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1.Unit))
+	})
+	return ret, err
+}
+
+// Get retrieves the Unit from the indexer for a given namespace and name.
+func (s unitNamespaceLister) Get(name string) (*v1.Unit, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1.Resource("unit"), name)
+	}
+	return obj.(*v1.Unit), nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	v1 "github.com/cokeos/zero/api/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// UsageReportLister helps list UsageReports.
+// All objects returned here must be treated as read-only.
+type UsageReportLister interface {
+	// List lists all UsageReports in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1.UsageReport, err error)
+	// UsageReports returns an object that can list and get UsageReports.
+	UsageReports(namespace string) UsageReportNamespaceLister
+	UsageReportListerExpansion
+}
+
+// usageReportLister implements the UsageReportLister interface.
+type usageReportLister struct {
+	indexer cache.Indexer
+}
+
+// NewUsageReportLister returns a new UsageReportLister.
+func NewUsageReportLister(indexer cache.Indexer) UsageReportLister {
+	return &usageReportLister{indexer: indexer}
+}
+
+// List lists all UsageReports in the indexer.
+func (s *usageReportLister) List(selector labels.Selector) (ret []*v1.UsageReport, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1.UsageReport))
+	})
+	return ret, err
+}
+
+// UsageReports returns an object that can list and get UsageReports.
+func (s *usageReportLister) UsageReports(namespace string) UsageReportNamespaceLister {
+	return usageReportNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// UsageReportNamespaceLister helps list and get UsageReports.
+// All objects returned here must be treated as read-only.
+type UsageReportNamespaceLister interface {
+	// List lists all UsageReports in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1.UsageReport, err error)
+	// Get retrieves the UsageReport from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1.UsageReport, error)
+	UsageReportNamespaceListerExpansion
+}
+
+// usageReportNamespaceLister implements the UsageReportNamespaceLister
+// interface.
+type usageReportNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all UsageReports in the indexer for a given namespace.
+func (s usageReportNamespaceLister) List(selector labels.Selector) (ret []*v1.UsageReport, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1.UsageReport))
+	})
+	return ret, err
+}
+
+// Get retrieves the UsageReport from the indexer for a given namespace and name.
+func (s usageReportNamespaceLister) Get(name string) (*v1.UsageReport, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1.Resource("usagereport"), name)
+	}
+	return obj.(*v1.UsageReport), nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/apiserver"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(corev1.AddToScheme(scheme))
+}
+
+func main() {
+	var bindAddr string
+	var certFile string
+	var keyFile string
+	var audiences string
+	flag.StringVar(&bindAddr, "bind-address", ":8090", "The address the API server listens on.")
+	flag.StringVar(&certFile, "tls-cert-file", "", "The TLS certificate. Serves plain HTTP if empty.")
+	flag.StringVar(&keyFile, "tls-private-key-file", "", "The TLS private key.")
+	flag.StringVar(&audiences, "token-audiences", "",
+		"Comma separated audiences tokens must be issued for. The API server audiences are used if empty.")
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	config := ctrl.GetConfigOrDie()
+	audienceList := make([]string, 0)
+	for _, audience := range strings.Split(audiences, ",") {
+		if audience = strings.TrimSpace(audience); audience != "" {
+			audienceList = append(audienceList, audience)
+		}
+	}
+	authenticator, err := apiserver.NewTokenReviewAuthenticator(config, audienceList)
+	if err != nil {
+		klog.Errorf("Create Authenticator Error: %v", err)
+		os.Exit(1)
+	}
+	server, err := apiserver.New(config, scheme, authenticator)
+	if err != nil {
+		klog.Errorf("Create API Server Error: %v", err)
+		os.Exit(1)
+	}
+
+	httpServer := &http.Server{Addr: bindAddr, Handler: server}
+	ctx := ctrl.SetupSignalHandler()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdown)
+	}()
+
+	klog.Infof("API server listening on %s", bindAddr)
+	if certFile != "" {
+		err = httpServer.ListenAndServeTLS(certFile, keyFile)
+	} else {
+		err = httpServer.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		klog.Errorf("Serve Error: %v", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"net"
+	"os"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/gateway"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(corev1.AddToScheme(scheme))
+}
+
+func main() {
+	var listenAddr string
+	var hostKeyFile string
+	var upstreamKeyFile string
+	var upstreamUser string
+	var keysSecretName string
+	flag.StringVar(&listenAddr, "listen-address", ":2222", "The address the SSH gateway listens on.")
+	flag.StringVar(&hostKeyFile, "host-key", "/etc/zero-gateway/host_key", "The private host key of the SSH gateway.")
+	flag.StringVar(&upstreamKeyFile, "upstream-key", "/etc/zero-gateway/upstream_key",
+		"The private key the SSH gateway uses to log in to Units.")
+	flag.StringVar(&upstreamUser, "upstream-user", gateway.DefaultUpstreamUser, "The user the SSH gateway logs in to Units as.")
+	flag.StringVar(&keysSecretName, "keys-secret", gateway.DefaultKeysSecretName,
+		"The Secret holding authorized_keys in each namespace.")
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	hostKey, err := gateway.LoadSigner(hostKeyFile)
+	if err != nil {
+		klog.Errorf("Load Host Key Error: %v", err)
+		os.Exit(1)
+	}
+	upstreamKey, err := gateway.LoadSigner(upstreamKeyFile)
+	if err != nil {
+		klog.Errorf("Load Upstream Key Error: %v", err)
+		os.Exit(1)
+	}
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		klog.Errorf("Create Client Error: %v", err)
+		os.Exit(1)
+	}
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		klog.Errorf("Listen Error: %v", err)
+		os.Exit(1)
+	}
+
+	server := &gateway.Server{
+		Client:         c,
+		HostKey:        hostKey,
+		UpstreamKey:    upstreamKey,
+		UpstreamUser:   upstreamUser,
+		KeysSecretName: keysSecretName,
+	}
+	klog.Infof("SSH gateway listening on %s", listenAddr)
+	if err := server.Serve(ctrl.SetupSignalHandler(), listener); err != nil {
+		klog.Errorf("Serve Error: %v", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newCreateCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a resource",
+	}
+	cmd.AddCommand(newCreateTinyCommand(o))
+	return cmd
+}
+
+func newCreateTinyCommand(o *options) *cobra.Command {
+	var frameworkFlag string
+	var gpuFlag bool
+	var profile string
+	var wait bool
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:     "tiny NAME --framework NAME:VERSION",
+		Short:   "Create a Tiny development environment",
+		Example: "  zeroctl create tiny demo --framework pytorch:1.9 --gpu --profile large",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fw, err := parseFramework(frameworkFlag)
+			if err != nil {
+				return err
+			}
+			namespace, err := o.ns()
+			if err != nil {
+				return err
+			}
+			c, err := o.Client()
+			if err != nil {
+				return err
+			}
+			tiny := &corev1.Tiny{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name:      args[0],
+				},
+				Spec: corev1.TinySpec{
+					GPU:       gpuFlag,
+					Framework: fw,
+					Profile:   profile,
+				},
+			}
+			if err := c.Create(cmd.Context(), tiny); err != nil {
+				return err
+			}
+			r, _ := lookupResource("tiny")
+			if wait {
+				obj, err := o.waitFor(cmd.Context(), r, tiny.Name, waitCondition{condition: corev1.TinyReady}, timeout)
+				if err != nil {
+					return err
+				}
+				tiny = obj.(*corev1.Tiny)
+			}
+			if o.output != OutputTable {
+				return o.printStructured(tiny)
+			}
+			fmt.Fprintf(o.out, "tiny/%s created\n", tiny.Name)
+			if tiny.Status.SSHCommand != "" {
+				fmt.Fprintln(o.out, tiny.Status.SSHCommand)
+			}
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&frameworkFlag, "framework", "", "The framework as name:version, e.g. pytorch:1.9.")
+	flags.BoolVar(&gpuFlag, "gpu", false, "Request a GPU.")
+	flags.StringVar(&profile, "profile", "", "The TinyProfile to use. The default profile is used if empty.")
+	flags.BoolVar(&wait, "wait", false, "Wait until the Tiny is ready.")
+	flags.DurationVar(&timeout, "timeout", 10*time.Minute, "How long to wait with --wait.")
+	_ = cmd.MarkFlagRequired("framework")
+	return cmd
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newListCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list [tinies|units|tunnels]",
+		Aliases: []string{"ls", "get"},
+		Short:   "List resources, Tinies by default",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind := "tinies"
+			if len(args) > 0 {
+				kind = args[0]
+			}
+			r, err := lookupResource(kind)
+			if err != nil {
+				return err
+			}
+			namespace, err := o.ns()
+			if err != nil {
+				return err
+			}
+			c, err := o.Client()
+			if err != nil {
+				return err
+			}
+			list := r.newList()
+			if err := c.List(cmd.Context(), list, client.InNamespace(namespace)); err != nil {
+				return err
+			}
+			return o.printList(r, list)
+		},
+	}
+}
+
+func newDescribeCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe (tiny|unit|tunnel) NAME",
+		Short: "Show the details of a resource",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := lookupResource(args[0])
+			if err != nil {
+				return err
+			}
+			obj, err := o.get(cmd.Context(), r, args[1])
+			if err != nil {
+				return err
+			}
+			return o.printObject(r, obj)
+		},
+	}
+}
+
+func newDeleteCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete (tiny|unit|tunnel) NAME...",
+		Short: "Delete resources",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := lookupResource(args[0])
+			if err != nil {
+				return err
+			}
+			namespace, err := o.ns()
+			if err != nil {
+				return err
+			}
+			c, err := o.Client()
+			if err != nil {
+				return err
+			}
+			for _, name := range args[1:] {
+				obj := r.newObject()
+				obj.SetNamespace(namespace)
+				obj.SetName(name)
+				if err := c.Delete(cmd.Context(), obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+					return err
+				}
+				fmt.Fprintf(o.out, "%s/%s deleted\n", r.aliases[0], name)
+			}
+			return nil
+		},
+	}
+}
+
+// get 读取当前命名空间下的资源
+func (o *options) get(ctx context.Context, r resource, name string) (client.Object, error) {
+	namespace, err := o.ns()
+	if err != nil {
+		return nil, err
+	}
+	c, err := o.Client()
+	if err != nil {
+		return nil, err
+	}
+	obj := r.newObject()
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
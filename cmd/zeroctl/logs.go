@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+)
+
+func newLogsCommand(o *options) *cobra.Command {
+	var follow bool
+	var tail int64
+	var previous bool
+	cmd := &cobra.Command{
+		Use:     "logs [KIND/]NAME",
+		Short:   "Print the logs of a Tiny or Unit",
+		Example: "  zeroctl logs demo -f\n  zeroctl logs unit/train --tail 100",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pod, err := o.podName(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			clientset, err := o.Kubernetes()
+			if err != nil {
+				return err
+			}
+			options := &v1.PodLogOptions{Follow: follow, Previous: previous}
+			if tail >= 0 {
+				options.TailLines = &tail
+			}
+			stream, err := clientset.CoreV1().Pods(o.namespace).GetLogs(pod, options).Stream(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer stream.Close()
+			_, err = io.Copy(o.out, stream)
+			return err
+		},
+	}
+	flags := cmd.Flags()
+	flags.BoolVarP(&follow, "follow", "f", false, "Stream the logs.")
+	flags.Int64Var(&tail, "tail", -1, "Lines of recent logs to show. All logs are shown if negative.")
+	flags.BoolVarP(&previous, "previous", "p", false, "Print the logs of the previous container instance.")
+	return cmd
+}
+
+// podName 解析 [KIND/]NAME 并返回对应的 Pod 名称
+func (o *options) podName(cmd *cobra.Command, ref string) (string, error) {
+	r, name, err := parseReference(ref)
+	if err != nil {
+		return "", err
+	}
+	obj, err := o.get(cmd.Context(), r, name)
+	if err != nil {
+		return "", err
+	}
+	return r.pod(obj), nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	zeroclient "github.com/cokeos/zero/client"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// options 各子命令共享的全局参数与客户端
+type options struct {
+	kubeconfig string
+	context    string
+	namespace  string
+	output     string
+
+	out    io.Writer
+	errOut io.Writer
+
+	// 以下字段在首次使用时初始化，测试中可直接注入
+	config     *rest.Config
+	client     client.Client
+	kubernetes kubernetes.Interface
+}
+
+func newOptions(out, errOut io.Writer) *options {
+	return &options{out: out, errOut: errOut}
+}
+
+func newRootCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "zeroctl",
+		Short:         "zeroctl manages Tinies, Units and Tunnels on a zero cluster",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.validate()
+		},
+	}
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&o.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file. Defaults to $KUBECONFIG or ~/.kube/config.")
+	flags.StringVar(&o.context, "context", "", "The kubeconfig context to use.")
+	flags.StringVarP(&o.namespace, "namespace", "n", "", "The namespace to use. Defaults to the namespace of the context.")
+	flags.StringVarP(&o.output, "output", "o", OutputTable, "Output format, one of table, json or yaml.")
+
+	cmd.AddCommand(
+		newCreateCommand(o),
+		newListCommand(o),
+		newDescribeCommand(o),
+		newDeleteCommand(o),
+		newWaitCommand(o),
+		newSSHCommand(o),
+		newLogsCommand(o),
+		newPortForwardCommand(o),
+	)
+	return cmd
+}
+
+func (o *options) validate() error {
+	switch o.output {
+	case OutputTable, OutputJSON, OutputYAML:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q, must be one of table, json or yaml", o.output)
+	}
+}
+
+// clientConfig 按 kubectl 的规则加载 kubeconfig
+func (o *options) clientConfig() clientcmd.ClientConfig {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = o.kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: o.context}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+}
+
+func (o *options) restConfig() (*rest.Config, error) {
+	if o.config == nil {
+		config, err := o.clientConfig().ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		o.config = config
+	}
+	return o.config, nil
+}
+
+// ns 返回操作的命名空间，未指定时使用上下文的命名空间
+func (o *options) ns() (string, error) {
+	if o.namespace == "" {
+		namespace, _, err := o.clientConfig().Namespace()
+		if err != nil {
+			return "", err
+		}
+		o.namespace = namespace
+	}
+	return o.namespace, nil
+}
+
+func (o *options) Client() (client.Client, error) {
+	if o.client == nil {
+		config, err := o.restConfig()
+		if err != nil {
+			return nil, err
+		}
+		c, err := zeroclient.NewClientForConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		o.client = c
+	}
+	return o.client, nil
+}
+
+func (o *options) Kubernetes() (kubernetes.Interface, error) {
+	if o.kubernetes == nil {
+		config, err := o.restConfig()
+		if err != nil {
+			return nil, err
+		}
+		clientset, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		o.kubernetes = clientset
+	}
+	return o.kubernetes, nil
+}
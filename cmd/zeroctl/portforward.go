@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+func newPortForwardCommand(o *options) *cobra.Command {
+	var addresses []string
+	cmd := &cobra.Command{
+		Use:     "port-forward [KIND/]NAME [LOCAL_PORT:]REMOTE_PORT...",
+		Short:   "Forward local ports to a Tiny or Unit",
+		Example: "  zeroctl port-forward demo 8888:8888\n  zeroctl port-forward unit/train 6006",
+		Args:    cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pod, err := o.podName(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			config, err := o.restConfig()
+			if err != nil {
+				return err
+			}
+			clientset, err := o.Kubernetes()
+			if err != nil {
+				return err
+			}
+			transport, upgrader, err := spdy.RoundTripperFor(config)
+			if err != nil {
+				return err
+			}
+			url := clientset.CoreV1().RESTClient().Post().
+				Resource("pods").
+				Namespace(o.namespace).
+				Name(pod).
+				SubResource("portforward").
+				URL()
+			dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+			stop := make(chan struct{})
+			go func() {
+				<-cmd.Context().Done()
+				close(stop)
+			}()
+			forwarder, err := portforward.NewOnAddresses(dialer, addresses, args[1:], stop, nil, o.out, o.errOut)
+			if err != nil {
+				return err
+			}
+			return forwarder.ForwardPorts()
+		},
+	}
+	cmd.Flags().StringSliceVar(&addresses, "address", []string{"localhost"}, "Addresses to listen on, comma separated.")
+	return cmd
+}
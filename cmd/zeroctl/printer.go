@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+// printList 按输出格式打印列表
+func (o *options) printList(r resource, list client.ObjectList) error {
+	items := r.items(list)
+	if o.output != OutputTable {
+		for _, item := range items {
+			if err := o.setKind(item); err != nil {
+				return err
+			}
+		}
+		return o.printStructured(list)
+	}
+	if len(items) == 0 {
+		namespace, _ := o.ns()
+		fmt.Fprintf(o.errOut, "No %s found in %s namespace.\n", r.name, namespace)
+		return nil
+	}
+	w := tabwriter.NewWriter(o.out, 0, 4, 3, ' ', 0)
+	fmt.Fprintln(w, strings.Join(r.header, "\t"))
+	for _, item := range items {
+		fmt.Fprintln(w, strings.Join(r.row(item), "\t"))
+	}
+	return w.Flush()
+}
+
+// printObject 按输出格式打印单个资源，table 格式下输出 describe 的内容
+func (o *options) printObject(r resource, obj client.Object) error {
+	if o.output != OutputTable {
+		return o.printStructured(obj)
+	}
+	w := tabwriter.NewWriter(o.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", obj.GetName())
+	fmt.Fprintf(w, "Namespace:\t%s\n", obj.GetNamespace())
+	fmt.Fprintf(w, "Created:\t%s (%s ago)\n", obj.GetCreationTimestamp().Format("2006-01-02 15:04:05"), age(obj))
+	for _, f := range r.fields(obj) {
+		if f.value != "" {
+			fmt.Fprintf(w, "%s:\t%s\n", f.name, f.value)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	o.printConditions(r.conditions(obj))
+	return nil
+}
+
+func (o *options) printConditions(conditions []metav1.Condition) {
+	if len(conditions) == 0 {
+		return
+	}
+	fmt.Fprintln(o.out, "Conditions:")
+	w := tabwriter.NewWriter(o.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tMESSAGE")
+	for _, c := range conditions {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", c.Type, c.Status, c.Reason, c.Message)
+	}
+	_ = w.Flush()
+}
+
+// printStructured 以 JSON 或 YAML 输出对象
+func (o *options) printStructured(obj runtime.Object) error {
+	if err := o.setKind(obj); err != nil {
+		return err
+	}
+	var data []byte
+	var err error
+	if o.output == OutputYAML {
+		data, err = yaml.Marshal(obj)
+	} else {
+		data, err = json.MarshalIndent(obj, "", "    ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	_, err = o.out.Write(data)
+	return err
+}
+
+// setKind 补全 apiVersion 与 kind，客户端解码后的对象不带类型信息
+func (o *options) setKind(obj runtime.Object) error {
+	c, err := o.Client()
+	if err != nil {
+		return err
+	}
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	return nil
+}
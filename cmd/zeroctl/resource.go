@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// field describe 输出的一项
+type field struct {
+	name  string
+	value string
+}
+
+// resource zeroctl 支持的资源
+type resource struct {
+	name      string
+	aliases   []string
+	newObject func() client.Object
+	newList   func() client.ObjectList
+	items     func(list client.ObjectList) []client.Object
+	header    []string
+	row       func(obj client.Object) []string
+	fields    func(obj client.Object) []field
+	// conditions 资源的状态条件
+	conditions func(obj client.Object) []metav1.Condition
+	// phase 资源的运行阶段，没有阶段的资源为 nil
+	phase func(obj client.Object) string
+	// pod 资源对应的 Pod 名称
+	pod func(obj client.Object) string
+}
+
+var resources = []resource{
+	{
+		name:      "tinies",
+		aliases:   []string{"tiny"},
+		newObject: func() client.Object { return &corev1.Tiny{} },
+		newList:   func() client.ObjectList { return &corev1.TinyList{} },
+		items: func(list client.ObjectList) []client.Object {
+			items := list.(*corev1.TinyList).Items
+			objs := make([]client.Object, 0, len(items))
+			for i := range items {
+				objs = append(objs, &items[i])
+			}
+			return objs
+		},
+		header: []string{"NAME", "PHASE", "FRAMEWORK", "GPU", "PROFILE", "ENDPOINT", "AGE"},
+		row: func(obj client.Object) []string {
+			tiny := obj.(*corev1.Tiny)
+			return []string{tiny.Name, string(tiny.Status.Phase), framework(tiny.Spec.Framework),
+				strconv.FormatBool(tiny.Spec.GPU), tiny.Status.Profile, tiny.Status.Endpoint, age(tiny)}
+		},
+		fields: func(obj client.Object) []field {
+			tiny := obj.(*corev1.Tiny)
+			return []field{
+				{"Framework", framework(tiny.Spec.Framework)},
+				{"GPU", strconv.FormatBool(tiny.Spec.GPU)},
+				{"Profile", tiny.Status.Profile},
+				{"Phase", string(tiny.Status.Phase)},
+				{"Endpoint", tiny.Status.Endpoint},
+				{"SSH Command", tiny.Status.SSHCommand},
+			}
+		},
+		conditions: func(obj client.Object) []metav1.Condition { return obj.(*corev1.Tiny).Status.Conditions },
+		phase:      func(obj client.Object) string { return string(obj.(*corev1.Tiny).Status.Phase) },
+		// Tiny 的 Unit 与其同名
+		pod: func(obj client.Object) string { return obj.GetName() },
+	},
+	{
+		name:      "units",
+		aliases:   []string{"unit"},
+		newObject: func() client.Object { return &corev1.Unit{} },
+		newList:   func() client.ObjectList { return &corev1.UnitList{} },
+		items: func(list client.ObjectList) []client.Object {
+			items := list.(*corev1.UnitList).Items
+			objs := make([]client.Object, 0, len(items))
+			for i := range items {
+				objs = append(objs, &items[i])
+			}
+			return objs
+		},
+		header: []string{"NAME", "PHASE", "FRAMEWORK", "GPU", "NODE", "AGE"},
+		row: func(obj client.Object) []string {
+			unit := obj.(*corev1.Unit)
+			return []string{unit.Name, string(unit.Status.Phase), framework(unit.Spec.Framework),
+				gpu(unit.Spec.GPUPolicy), unit.Status.NodeName, age(unit)}
+		},
+		fields: func(obj client.Object) []field {
+			unit := obj.(*corev1.Unit)
+			fields := []field{
+				{"Framework", framework(unit.Spec.Framework)},
+				{"Image", unit.Spec.Image},
+				{"GPU", gpu(unit.Spec.GPUPolicy)},
+				{"Suspend", strconv.FormatBool(unit.Spec.Suspend)},
+				{"Phase", string(unit.Status.Phase)},
+				{"Node", unit.Status.NodeName},
+				{"Host IP", unit.Status.HostIP},
+			}
+			names := make([]string, 0, len(unit.Spec.ResourceList))
+			for name := range unit.Spec.ResourceList {
+				names = append(names, string(name))
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				quantity := unit.Spec.ResourceList[v1.ResourceName(name)]
+				fields = append(fields, field{"Resource " + name, quantity.String()})
+			}
+			return fields
+		},
+		conditions: func(obj client.Object) []metav1.Condition { return obj.(*corev1.Unit).Status.Conditions },
+		phase:      func(obj client.Object) string { return string(obj.(*corev1.Unit).Status.Phase) },
+		pod:        func(obj client.Object) string { return obj.GetName() },
+	},
+	{
+		name:      "tunnels",
+		aliases:   []string{"tunnel"},
+		newObject: func() client.Object { return &corev1.Tunnel{} },
+		newList:   func() client.ObjectList { return &corev1.TunnelList{} },
+		items: func(list client.ObjectList) []client.Object {
+			items := list.(*corev1.TunnelList).Items
+			objs := make([]client.Object, 0, len(items))
+			for i := range items {
+				objs = append(objs, &items[i])
+			}
+			return objs
+		},
+		header: []string{"NAME", "UNIT", "TYPE", "ENDPOINTS", "AGE"},
+		row: func(obj client.Object) []string {
+			tunnel := obj.(*corev1.Tunnel)
+			return []string{tunnel.Name, tunnel.Spec.UnitName, string(tunnel.Spec.Type), endpoints(tunnel), age(tunnel)}
+		},
+		fields: func(obj client.Object) []field {
+			tunnel := obj.(*corev1.Tunnel)
+			fields := []field{
+				{"Unit", tunnel.Spec.UnitName},
+				{"Type", string(tunnel.Spec.Type)},
+			}
+			for _, e := range tunnel.Status.Endpoints {
+				fields = append(fields, field{"Endpoint " + e.Name, e.Address})
+			}
+			return fields
+		},
+		conditions: func(obj client.Object) []metav1.Condition { return obj.(*corev1.Tunnel).Status.Conditions },
+		pod:        func(obj client.Object) string { return obj.(*corev1.Tunnel).Spec.UnitName },
+	},
+}
+
+// lookupResource 按名称或别名查找资源
+func lookupResource(name string) (resource, error) {
+	name = strings.ToLower(name)
+	for _, r := range resources {
+		if r.name == name {
+			return r, nil
+		}
+		for _, alias := range r.aliases {
+			if alias == name {
+				return r, nil
+			}
+		}
+	}
+	return resource{}, fmt.Errorf("unknown resource %q, must be one of tiny, unit or tunnel", name)
+}
+
+// parseReference 解析 [KIND/]NAME，未指定类型时为 Tiny
+func parseReference(ref string) (resource, string, error) {
+	kind, name := "tiny", ref
+	if i := strings.Index(ref, "/"); i >= 0 {
+		kind, name = ref[:i], ref[i+1:]
+	}
+	if name == "" {
+		return resource{}, "", fmt.Errorf("invalid reference %q, must be [KIND/]NAME", ref)
+	}
+	r, err := lookupResource(kind)
+	return r, name, err
+}
+
+// parseFramework 解析 name:version
+func parseFramework(s string) (corev1.Framework, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return corev1.Framework{}, fmt.Errorf("invalid framework %q, must be name:version such as pytorch:1.9", s)
+	}
+	return corev1.Framework{Name: parts[0], Version: parts[1]}, nil
+}
+
+func framework(f corev1.Framework) string {
+	if f.Name == "" {
+		return ""
+	}
+	return f.Name + ":" + f.Version
+}
+
+func gpu(policy corev1.GPUPolicy) string {
+	if !policy.GPU {
+		return "0"
+	}
+	if policy.Model != "" {
+		return fmt.Sprintf("%d (%s)", policy.Number, policy.Model)
+	}
+	return strconv.Itoa(policy.Number)
+}
+
+func endpoints(tunnel *corev1.Tunnel) string {
+	addresses := make([]string, 0, len(tunnel.Status.Endpoints))
+	for _, e := range tunnel.Status.Endpoints {
+		addresses = append(addresses, e.Address)
+	}
+	return strings.Join(addresses, ",")
+}
+
+func age(obj client.Object) string {
+	created := obj.GetCreationTimestamp()
+	if created.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(created.Time))
+}
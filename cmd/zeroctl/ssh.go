@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// defaultSSHUser 镜像中 SSH 登录使用的用户，与 Tiny 控制器一致
+const defaultSSHUser = "root"
+
+func newSSHCommand(o *options) *cobra.Command {
+	var user string
+	var identity string
+	var printOnly bool
+	cmd := &cobra.Command{
+		Use:   "ssh NAME [-- SSH_ARGS...]",
+		Short: "Connect to a Tiny over SSH",
+		Long: "Connect to a Tiny over SSH. The address is resolved from the Tiny status: the published endpoint, " +
+			"or the node of its Unit and the allocated NodePort.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, _ := lookupResource("tiny")
+			obj, err := o.get(cmd.Context(), r, args[0])
+			if err != nil {
+				return err
+			}
+			tiny := obj.(*corev1.Tiny)
+			endpoint, err := o.sshEndpoint(cmd.Context(), tiny)
+			if err != nil {
+				return err
+			}
+			if user == "" {
+				user = sshUser(tiny.Status.SSHCommand)
+			}
+			sshArgs := sshArgs(endpoint, user, identity, args[1:])
+			if printOnly {
+				fmt.Fprintln(o.out, "ssh "+strings.Join(sshArgs, " "))
+				return nil
+			}
+			ssh := exec.CommandContext(cmd.Context(), "ssh", sshArgs...)
+			ssh.Stdin, ssh.Stdout, ssh.Stderr = os.Stdin, o.out, o.errOut
+			return ssh.Run()
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&user, "user", "l", "", "The user to log in as. Defaults to the user in the Tiny SSH command.")
+	flags.StringVarP(&identity, "identity", "i", "", "The private key file passed to ssh.")
+	flags.BoolVar(&printOnly, "print", false, "Print the ssh command instead of running it.")
+	return cmd
+}
+
+// sshEndpoint 返回 Tiny 的 SSH 地址，状态中没有对外地址时使用 Unit 所在节点与 NodePort
+func (o *options) sshEndpoint(ctx context.Context, tiny *corev1.Tiny) (string, error) {
+	if tiny.Status.Endpoint != "" {
+		return tiny.Status.Endpoint, nil
+	}
+	if tiny.Status.NodePort <= 0 {
+		return "", fmt.Errorf("tiny %s has no SSH port yet, phase is %q", tiny.Name, tiny.Status.Phase)
+	}
+	host := tiny.Status.Host
+	if host == "" {
+		c, err := o.Client()
+		if err != nil {
+			return "", err
+		}
+		unit := &corev1.Unit{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: tiny.Namespace, Name: tiny.Name}, unit); err != nil {
+			return "", err
+		}
+		host = unit.Status.HostIP
+		if host == "" {
+			host = unit.Status.NodeName
+		}
+	}
+	if host == "" {
+		return "", fmt.Errorf("tiny %s is not scheduled yet, phase is %q", tiny.Name, tiny.Status.Phase)
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(tiny.Status.NodePort))), nil
+}
+
+// sshUser 从 ssh -p <port> user@<host> 中取出用户
+func sshUser(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) > 0 {
+		if i := strings.LastIndex(fields[len(fields)-1], "@"); i > 0 {
+			return fields[len(fields)-1][:i]
+		}
+	}
+	return defaultSSHUser
+}
+
+func sshArgs(endpoint, user, identity string, extra []string) []string {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		host, port = endpoint, "22"
+	}
+	args := []string{"-p", port}
+	if identity != "" {
+		args = append(args, "-i", identity)
+	}
+	args = append(args, user+"@"+host)
+	return append(args, extra...)
+}
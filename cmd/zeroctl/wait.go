@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// pollInterval 等待时查询资源的间隔
+var pollInterval = 2 * time.Second
+
+// waitCondition 等待的目标状态，三者只设置其一
+type waitCondition struct {
+	// condition 状态条件为 True
+	condition string
+	// phase 运行阶段
+	phase string
+	// deleted 资源已删除
+	deleted bool
+}
+
+func (c waitCondition) String() string {
+	switch {
+	case c.deleted:
+		return "delete"
+	case c.phase != "":
+		return "phase=" + c.phase
+	default:
+		return "condition=" + c.condition
+	}
+}
+
+// parseWaitCondition 解析 condition=Ready、phase=Running 或 delete
+func parseWaitCondition(r resource, s string) (waitCondition, error) {
+	if s == "" {
+		if r.name == "units" {
+			return waitCondition{phase: string(v1.PodRunning)}, nil
+		}
+		return waitCondition{condition: "Ready"}, nil
+	}
+	if s == "delete" {
+		return waitCondition{deleted: true}, nil
+	}
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) == 2 && parts[1] != "" {
+		switch parts[0] {
+		case "condition":
+			return waitCondition{condition: parts[1]}, nil
+		case "phase":
+			if r.phase == nil {
+				return waitCondition{}, fmt.Errorf("%s have no phase", r.name)
+			}
+			return waitCondition{phase: parts[1]}, nil
+		}
+	}
+	return waitCondition{}, fmt.Errorf("invalid --for %q, must be condition=NAME, phase=PHASE or delete", s)
+}
+
+func newWaitCommand(o *options) *cobra.Command {
+	var forFlag string
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "wait (tiny|unit|tunnel) NAME",
+		Short: "Wait until a resource reaches a condition or phase",
+		Long: "Wait until a resource reaches a condition or phase. Tinies and Tunnels wait for condition=Ready " +
+			"and Units wait for phase=Running by default.",
+		Example: "  zeroctl wait tiny demo\n  zeroctl wait unit demo --for phase=Succeeded --timeout 1h",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := lookupResource(args[0])
+			if err != nil {
+				return err
+			}
+			condition, err := parseWaitCondition(r, forFlag)
+			if err != nil {
+				return err
+			}
+			if _, err := o.waitFor(cmd.Context(), r, args[1], condition, timeout); err != nil {
+				return err
+			}
+			fmt.Fprintf(o.out, "%s/%s %s met\n", r.aliases[0], args[1], condition)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&forFlag, "for", "", "The condition to wait for: condition=NAME, phase=PHASE or delete.")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "How long to wait before giving up.")
+	return cmd
+}
+
+// waitFor 轮询资源直到满足条件，返回最后一次读取到的资源
+func (o *options) waitFor(ctx context.Context, r resource, name string, condition waitCondition, timeout time.Duration) (client.Object, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var last client.Object
+	err := wait.PollImmediateUntil(pollInterval, func() (bool, error) {
+		obj, err := o.get(ctx, r, name)
+		if apierrors.IsNotFound(err) {
+			return condition.deleted, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		last = obj
+		switch {
+		case condition.deleted:
+			return false, nil
+		case condition.phase != "":
+			phase := r.phase(obj)
+			if phase != condition.phase && phase == string(v1.PodFailed) {
+				return false, fmt.Errorf("%s/%s failed", r.aliases[0], name)
+			}
+			return phase == condition.phase, nil
+		default:
+			return meta.IsStatusConditionTrue(r.conditions(obj), condition.condition), nil
+		}
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout || (err != nil && ctx.Err() == context.DeadlineExceeded) {
+		return last, fmt.Errorf("timed out waiting for %s/%s %s", r.aliases[0], name, condition)
+	}
+	return last, err
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/internal/zerotest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newTestOptions(objs ...client.Object) (*options, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	o := newOptions(out, &bytes.Buffer{})
+	o.client = zerotest.NewClient(objs...)
+	o.kubernetes = kubefake.NewSimpleClientset()
+	return o, out
+}
+
+func run(t *testing.T, o *options, args ...string) error {
+	t.Helper()
+	cmd := newRootCommand(o)
+	cmd.SetArgs(append(args, "--namespace", "default"))
+	cmd.SetOut(o.out)
+	cmd.SetErr(o.errOut)
+	return cmd.ExecuteContext(context.TODO())
+}
+
+func TestCreateTiny(t *testing.T) {
+	o, out := newTestOptions()
+	if err := run(t, o, "create", "tiny", "demo", "--framework", "pytorch:1.9", "--gpu", "--profile", "large"); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "tiny/demo created\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+	tiny := &corev1.Tiny{}
+	if err := o.client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "demo"}, tiny); err != nil {
+		t.Fatal(err)
+	}
+	expected := corev1.TinySpec{GPU: true, Framework: corev1.Framework{Name: "pytorch", Version: "1.9"}, Profile: "large"}
+	if tiny.Spec != expected {
+		t.Errorf("expected %+v, got %+v", expected, tiny.Spec)
+	}
+
+	if err := run(t, o, "create", "tiny", "bad", "--framework", "pytorch"); err == nil {
+		t.Error("expected an invalid framework error")
+	}
+}
+
+func TestListAndDescribe(t *testing.T) {
+	tiny := &corev1.Tiny{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "demo"},
+		Spec:       corev1.TinySpec{Framework: corev1.Framework{Name: "pytorch", Version: "1.9"}},
+		Status: corev1.TinyStatus{
+			Phase:    "Running",
+			Endpoint: "10.0.0.1:30022",
+			Conditions: []metav1.Condition{
+				{Type: corev1.TinyReady, Status: metav1.ConditionTrue, Reason: "Ready"},
+			},
+		},
+	}
+	o, out := newTestOptions(tiny)
+	if err := run(t, o, "list"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "NAME") || !strings.Contains(lines[1], "10.0.0.1:30022") {
+		t.Errorf("unexpected table %q", out)
+	}
+
+	out.Reset()
+	if err := run(t, o, "describe", "tiny", "demo"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "pytorch:1.9") || !strings.Contains(out.String(), "Conditions:") {
+		t.Errorf("unexpected describe %q", out)
+	}
+
+	out.Reset()
+	if err := run(t, o, "describe", "tiny", "demo", "-o", "json"); err != nil {
+		t.Fatal(err)
+	}
+	printed := &corev1.Tiny{}
+	if err := json.Unmarshal(out.Bytes(), printed); err != nil {
+		t.Fatal(err)
+	}
+	if printed.Kind != "Tiny" || printed.APIVersion != corev1.GroupVersion.String() {
+		t.Errorf("expected type meta to be set, got %+v", printed.TypeMeta)
+	}
+
+	out.Reset()
+	if err := run(t, o, "list", "tinies", "-o", "yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "kind: TinyList") || !strings.Contains(out.String(), "kind: Tiny\n") {
+		t.Errorf("unexpected yaml %q", out)
+	}
+
+	if err := run(t, o, "list", "-o", "wide"); err == nil {
+		t.Error("expected an unsupported output error")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	o, _ := newTestOptions(&corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "train"}})
+	if err := run(t, o, "delete", "unit", "train"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(t, o, "describe", "unit", "train"); err == nil {
+		t.Error("expected the unit to be deleted")
+	}
+}
+
+func TestWait(t *testing.T) {
+	pollInterval = 10 * time.Millisecond
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "train"}}
+	unit.Status.Phase = "Running"
+	o, out := newTestOptions(unit)
+	if err := run(t, o, "wait", "unit", "train"); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "unit/train phase=Running met\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	err := run(t, o, "wait", "unit", "train", "--for", "phase=Succeeded", "--timeout", "50ms")
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+
+	unit.Status.Phase = "Failed"
+	o, _ = newTestOptions(unit)
+	if err := run(t, o, "wait", "unit", "train", "--for", "phase=Succeeded"); err == nil {
+		t.Error("expected the failed unit to stop waiting")
+	}
+}
+
+func TestSSH(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   corev1.TinyStatus
+		expected string
+	}{
+		{
+			name:     "endpoint",
+			status:   corev1.TinyStatus{Endpoint: "gw.example.com:22", SSHCommand: "ssh -p 22 demo@gw.example.com"},
+			expected: "ssh -p 22 demo@gw.example.com\n",
+		},
+		{
+			name:     "node port",
+			status:   corev1.TinyStatus{NodePort: 30022},
+			expected: "ssh -p 30022 root@10.0.0.2\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tiny := &corev1.Tiny{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "demo"}, Status: test.status}
+			unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "demo"}}
+			unit.Status.HostIP = "10.0.0.2"
+			o, out := newTestOptions(tiny, unit)
+			if err := run(t, o, "ssh", "demo", "--print"); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, out)
+			}
+		})
+	}
+}
+
+func TestLogs(t *testing.T) {
+	o, out := newTestOptions(&corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "train"}})
+	if err := run(t, o, "logs", "unit/train", "--tail", "10"); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "fake logs" {
+		t.Errorf("unexpected logs %q", out)
+	}
+	if err := run(t, o, "logs", "pod/train"); err == nil {
+		t.Error("expected an unknown resource error")
+	}
+}
@@ -0,0 +1,183 @@
+// Package config 加载 ZeroConfig 配置文件，并在文件变化后热加载可以安全修改的字段
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var log = ctrl.Log.WithName("config")
+
+// DefaultReloadPeriod 检查配置文件是否变化的间隔，ConfigMap 挂载的文件在修改后约一分钟内更新
+const DefaultReloadPeriod = time.Second * 10
+
+var (
+	scheme = runtime.NewScheme()
+	// codecs 拒绝未知字段，避免拼错的字段被静默忽略
+	codecs = serializer.NewCodecFactory(scheme, serializer.EnableStrict)
+
+	defaultConfig = configv1alpha1.NewDefault()
+)
+
+func init() {
+	utilruntime.Must(configv1alpha1.AddToScheme(scheme))
+}
+
+// Load 读取配置文件，填充默认值并校验
+func Load(path string) (*configv1alpha1.ZeroConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data)
+}
+
+func decode(data []byte) (*configv1alpha1.ZeroConfig, error) {
+	c := &configv1alpha1.ZeroConfig{}
+	if err := runtime.DecodeInto(codecs.UniversalDecoder(configv1alpha1.GroupVersion), data, c); err != nil {
+		return nil, err
+	}
+	c.Default()
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return c, nil
+}
+
+// Store 保存当前生效的配置，控制器每次使用时通过 Get 读取
+//
+// 热加载只替换 Admission 与 SyncPeriods。其余字段决定已分配的端口、Pod 的标签或 Manager 的监听地址，
+// 运行中替换会使已有对象与新配置不一致，修改后需要重启。
+type Store struct {
+	// Path 配置文件路径，为空时使用默认配置且不热加载
+	Path string
+	// Override 每次加载后执行，命令行中显式指定的参数据此覆盖配置文件
+	Override func(*configv1alpha1.ZeroConfig)
+	// ReloadPeriod 检查配置文件是否变化的间隔
+	ReloadPeriod time.Duration
+
+	current atomic.Value
+	data    []byte
+}
+
+// NewStore 加载 path 处的配置文件，path 为空时使用默认配置
+func NewStore(path string, override func(*configv1alpha1.ZeroConfig)) (*Store, error) {
+	s := &Store{Path: path, Override: override, ReloadPeriod: DefaultReloadPeriod}
+	c := configv1alpha1.NewDefault()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if c, err = decode(data); err != nil {
+			return nil, err
+		}
+		s.data = data
+	}
+	if s.Override != nil {
+		s.Override(c)
+	}
+	s.current.Store(c)
+	return s, nil
+}
+
+// Get 返回当前配置，调用方不能修改；Store 为空时返回默认配置，测试可以直接构造控制器
+func (s *Store) Get() *configv1alpha1.ZeroConfig {
+	if s == nil {
+		return defaultConfig
+	}
+	return s.current.Load().(*configv1alpha1.ZeroConfig)
+}
+
+// Reload 配置文件变化后重新加载，文件无效时保留当前配置
+func (s *Store) Reload() error {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, s.data) {
+		return nil
+	}
+	// 无效的文件只报告一次，修正后再次加载
+	s.data = data
+	next, err := decode(data)
+	if err != nil {
+		return err
+	}
+	if s.Override != nil {
+		s.Override(next)
+	}
+	updated := s.Get().DeepCopy()
+	updated.Admission = next.Admission
+	updated.SyncPeriods = next.SyncPeriods
+	if !equality.Semantic.DeepEqual(updated, next) {
+		log.Info("Config changes outside admission and syncPeriods take effect after restart", "path", s.Path)
+	}
+	s.current.Store(updated)
+	log.Info("Reloaded config", "path", s.Path)
+	return nil
+}
+
+// Start 定期检查配置文件直到 ctx 结束，作为 Runnable 随 Manager 启动
+func (s *Store) Start(ctx context.Context) error {
+	if s.Path == "" {
+		return nil
+	}
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := s.Reload(); err != nil {
+			log.Error(err, "Failed to reload config", "path", s.Path)
+		}
+	}, s.ReloadPeriod)
+	return nil
+}
+
+// NeedLeaderElection 每个副本都会处理准入请求，都需要热加载
+func (s *Store) NeedLeaderElection() bool {
+	return false
+}
+
+// Until 周期执行 f 直到 ctx 结束，每次执行后按 period 的返回值等待，间隔热加载后从下一次起生效
+func Until(ctx context.Context, f func(), period func() time.Duration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		f()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(period()):
+		}
+	}
+}
+
+// Loop 周期执行 Sync 的 Runnable，只在选主成功的副本上运行，避免多个副本重复同步或重复记录
+//
+// Manager 在缓存同步后才启动需要选主的 Runnable，Sync 可以直接读取缓存。
+type Loop struct {
+	Sync   func()
+	Period func() time.Duration
+}
+
+func (l Loop) Start(ctx context.Context) error {
+	Until(ctx, l.Sync, l.Period)
+	return nil
+}
+
+func (l Loop) NeedLeaderElection() bool {
+	return true
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+)
+
+const testConfig = `apiVersion: config.cokeos.io/v1alpha1
+kind: ZeroConfig
+webhook:
+  port: 9444
+unit:
+  registry: registry.example.com/zero
+  gpuModels: [RTX-3090]
+admission:
+  allowedRegistries: [registry.example.com]
+syncPeriods:
+  unit: 30s
+`
+
+func writeConfig(t *testing.T, path, data string) {
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, testConfig)
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *c.Webhook.Port != 9444 || c.Unit.Registry != "registry.example.com/zero" || c.SyncPeriods.Unit.Duration != 30*time.Second {
+		t.Errorf("unexpected config %+v", c)
+	}
+	// 未设置的字段使用默认值
+	if c.LeaderElection.ResourceName != configv1alpha1.DefaultLeaderElectionID || c.Unit.ShmSize.String() != "32Gi" ||
+		c.Tiny.NodePorts.Size() != 2000 || c.SyncPeriods.Tiny.Duration != time.Minute ||
+		c.Unit.LabelKey != configv1alpha1.DefaultLabelKey || c.Unit.UniqLabelKey != configv1alpha1.DefaultUniqLabelKey {
+		t.Errorf("expected defaults, got %+v", c)
+	}
+	if c.Tunnel.AddressMode != configv1alpha1.DefaultAddressMode || c.Gateway.Port != configv1alpha1.DefaultGatewayPort ||
+		c.Snapshot.LabelKey != configv1alpha1.DefaultLabelKey || c.Project.LabelKey != configv1alpha1.DefaultLabelKey ||
+		*c.ScheduledUnit.SuccessfulUnitsHistoryLimit != 3 || c.LogArchive.Root != c.Unit.WorkspaceRoot ||
+		len(c.NetworkPolicy.AllowedNamespaces) != 1 || c.NetworkPolicy.AllowedNamespaces[0] != configv1alpha1.DefaultSystemNamespace {
+		t.Errorf("expected defaults, got %+v", c)
+	}
+
+	for name, data := range map[string]string{
+		"unknown field":  testConfig + "unknown: true\n",
+		"invalid ports":  testConfig + "tiny:\n  nodePorts: {min: 32000, max: 30000}\n",
+		"invalid period": strings.Replace(testConfig, "unit: 30s", "unit: 0s", 1),
+		"wrong kind":     strings.Replace(testConfig, "kind: ZeroConfig", "kind: ControllerManagerConfig", 1),
+		"invalid label":  strings.Replace(testConfig, "unit:\n", "unit:\n  labelKey: zero managed\n", 1),
+		"same labels":    strings.Replace(testConfig, "unit:\n", "unit:\n  labelKey: cokeos.io/zero-id\n", 1),
+		"negative uid":   strings.Replace(testConfig, "unit:\n", "unit:\n  security: {runAsUser: -1}\n", 1),
+		"no localhost profile": strings.Replace(testConfig, "unit:\n",
+			"unit:\n  security: {seccompProfile: {type: Localhost}}\n", 1),
+		"invalid node cidr":     testConfig + "networkPolicy:\n  nodeCIDRs: [10.0.0.1]\n",
+		"unknown address mode":  testConfig + "tunnel:\n  addressMode: NodeInternalIP\n",
+		"no gateway host":       testConfig + "gateway:\n  ssh: true\n",
+		"no prometheus":         testConfig + "idle:\n  metricsSource: prometheus\n",
+		"no usage path":         testConfig + "usage:\n  sink: sqlite\n",
+		"unknown timezone":      testConfig + "usage:\n  timezone: Mars/Olympus\n",
+		"no s3 bucket":          testConfig + "logArchive:\n  store: s3\n  s3: {endpoint: minio:9000}\n",
+		"invalid project label": testConfig + "project:\n  labelKey: zero managed\n",
+		"negative history":      testConfig + "scheduledUnit:\n  failedUnitsHistoryLimit: -1\n",
+	} {
+		writeConfig(t, path, data)
+		if _, err := Load(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, testConfig)
+	s, err := NewStore(path, func(c *configv1alpha1.ZeroConfig) {
+		c.Admission.TrustedUsers = []string{"alice"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeConfig(t, path, strings.NewReplacer(
+		"registry.example.com]", "registry.example.com, docker.io/library]",
+		"unit: 30s", "unit: 1m",
+		"registry: registry.example.com/zero", "registry: other.example.com/zero",
+	).Replace(testConfig))
+	if err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	c := s.Get()
+	if len(c.Admission.AllowedRegistries) != 2 || c.SyncPeriods.Unit.Duration != time.Minute {
+		t.Errorf("expected admission and sync periods to be reloaded, got %+v", c)
+	}
+	if c.Unit.Registry != "registry.example.com/zero" {
+		t.Errorf("expected the registry to be kept until restart, got %s", c.Unit.Registry)
+	}
+	if len(c.Admission.TrustedUsers) != 1 || c.Admission.TrustedUsers[0] != "alice" {
+		t.Errorf("expected the override to be applied, got %v", c.Admission.TrustedUsers)
+	}
+
+	writeConfig(t, path, testConfig+"  tunnel: 10ms\n")
+	if err := s.Reload(); err == nil {
+		t.Error("expected an error for an invalid file")
+	}
+	if s.Get() != c {
+		t.Error("expected an invalid file to keep the current config")
+	}
+}
+
+func TestNilStore(t *testing.T) {
+	var s *Store
+	if s.Get().Unit.Registry != configv1alpha1.DefaultRegistry {
+		t.Errorf("expected the default config, got %+v", s.Get())
+	}
+}
+
+func TestLoop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	synced := 0
+	loop := Loop{
+		Sync: func() {
+			if synced++; synced == 3 {
+				cancel()
+			}
+		},
+		Period: func() time.Duration { return time.Millisecond },
+	}
+	// 周期同步会写入对象或记录用量，只能在主副本上运行
+	if !loop.NeedLeaderElection() {
+		t.Error("expected the loop to need leader election")
+	}
+	if err := loop.Start(ctx); err != nil || synced != 3 {
+		t.Errorf("expected the loop to stop with the context, got %d syncs and %v", synced, err)
+	}
+}
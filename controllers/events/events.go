@@ -0,0 +1,28 @@
+// Package events 记录控制器的 Warning 事件与错误日志
+package events
+
+import (
+	"context"
+
+	"github.com/cokeos/zero/controllers/logging"
+	"github.com/cokeos/zero/controllers/metrics"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// ReasonConflict 他人同时修改了对象
+const ReasonConflict = "Conflict"
+
+// Warning 记录 Warning 事件与错误日志，返回 err 以便 controller-runtime 退避重试
+//
+// 他人同时修改了对象时原因为 ReasonConflict，controller 为指标中的控制器名称。
+func Warning(ctx context.Context, recorder record.EventRecorder, controller string, obj runtime.Object, reason string, err error) error {
+	if apierrors.IsConflict(err) {
+		reason = ReasonConflict
+	}
+	recorder.Event(obj, v1.EventTypeWarning, reason, err.Error())
+	metrics.LogError(logging.FromContext(ctx), controller, err, "Reconcile failed", "reason", reason)
+	return err
+}
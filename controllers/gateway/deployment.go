@@ -0,0 +1,158 @@
+package gateway
+
+import (
+	sshgateway "github.com/cokeos/zero/gateway"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	LabelKey   = "cokeos.io/zero-managed"
+	LabelValue = "true"
+
+	AppLabelKey = "control-plane"
+	AppLabel    = "ssh-gateway"
+
+	HostKey             = "host_key"
+	UpstreamKey         = "upstream_key"
+	UpstreamKeyPublic   = "upstream_key.pub"
+	DefaultKeyMountPath = "/etc/zero-gateway"
+
+	ContainerPort = 2222
+	// nonroot 用户，与 manager 镜像一致
+	RunAsUser = 65532
+)
+
+func gatewayLabels() map[string]string {
+	return map[string]string{
+		LabelKey:    LabelValue,
+		AppLabelKey: AppLabel,
+	}
+}
+
+func generateSecret(namespace, name string) (*v1.Secret, error) {
+	hostKey, _, err := sshgateway.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	upstreamKey, upstreamKeyPublic, err := sshgateway.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    gatewayLabels(),
+		},
+		Data: map[string][]byte{
+			HostKey:           hostKey,
+			UpstreamKey:       upstreamKey,
+			UpstreamKeyPublic: upstreamKeyPublic,
+		},
+	}, nil
+}
+
+func generateDeployment(r *GatewayReconciler) *appsv1.Deployment {
+	var (
+		replicas         = int32(1)
+		mode             = int32(0440)
+		runAsUser        = int64(RunAsUser)
+		runAsNonRoot     = true
+		allowEscalation  = false
+		keysSecretVolume = "keys"
+	)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Namespace,
+			Name:      r.Name,
+			Labels:    gatewayLabels(),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{AppLabelKey: AppLabel},
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: gatewayLabels(),
+				},
+				Spec: v1.PodSpec{
+					ServiceAccountName: r.ServiceAccountName,
+					SecurityContext: &v1.PodSecurityContext{
+						RunAsNonRoot: &runAsNonRoot,
+						RunAsUser:    &runAsUser,
+						FSGroup:      &runAsUser,
+					},
+					Containers: []v1.Container{
+						{
+							Name:    AppLabel,
+							Image:   r.Image,
+							Command: []string{"/gateway"},
+							Args: []string{
+								"--host-key=" + DefaultKeyMountPath + "/" + HostKey,
+								"--upstream-key=" + DefaultKeyMountPath + "/" + UpstreamKey,
+								"--keys-secret=" + r.KeysSecretName,
+							},
+							Ports: []v1.ContainerPort{
+								{
+									Name:          sshgateway.SSH,
+									ContainerPort: ContainerPort,
+								},
+							},
+							SecurityContext: &v1.SecurityContext{
+								AllowPrivilegeEscalation: &allowEscalation,
+							},
+							VolumeMounts: []v1.VolumeMount{
+								{
+									Name:      keysSecretVolume,
+									MountPath: DefaultKeyMountPath,
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					Volumes: []v1.Volume{
+						{
+							Name: keysSecretVolume,
+							VolumeSource: v1.VolumeSource{
+								Secret: &v1.SecretVolumeSource{
+									SecretName:  r.Name,
+									DefaultMode: &mode,
+									Items: []v1.KeyToPath{
+										{Key: HostKey, Path: HostKey},
+										{Key: UpstreamKey, Path: UpstreamKey},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func generateService(r *GatewayReconciler) *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Namespace,
+			Name:      r.Name,
+			Labels:    gatewayLabels(),
+		},
+		Spec: v1.ServiceSpec{
+			Type:     r.ServiceType,
+			Selector: map[string]string{AppLabelKey: AppLabel},
+			Ports: []v1.ServicePort{
+				{
+					Name:       sshgateway.SSH,
+					Protocol:   v1.ProtocolTCP,
+					Port:       r.Port,
+					TargetPort: intstr.FromInt(ContainerPort),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	"github.com/cokeos/zero/controllers/config"
+	"github.com/cokeos/zero/controllers/logging"
+	"github.com/cokeos/zero/controllers/metrics"
+	"strings"
+	"time"
+
+	sshgateway "github.com/cokeos/zero/gateway"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	DefaultName               = "zero-gateway"
+	DefaultNamespace          = configv1alpha1.DefaultSystemNamespace
+	DefaultServiceAccountName = "zero-gateway"
+	DefaultImage              = configv1alpha1.DefaultGatewayImage
+	DefaultPort               = configv1alpha1.DefaultGatewayPort
+)
+
+// GatewayReconciler 部署 SSH 网关并维护其密钥、Deployment 与 Service
+type GatewayReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Reader 直接读取 API Server，网关对象不经过 Manager 的缓存
+	Reader client.Reader
+
+	// Namespace 网关所在的命名空间，一般为 operator 所在的命名空间，为空时使用配置文件的 gateway.namespace
+	Namespace string
+	// Name 网关 Deployment、Service 与密钥 Secret 的名称
+	Name string
+	// Image 网关镜像，与 manager 使用同一镜像，为空时使用配置文件的 gateway.image
+	Image string
+	// ServiceAccountName 网关使用的 ServiceAccount
+	ServiceAccountName string
+	// ServiceType 网关 Service 的类型，为空时使用配置文件的 gateway.serviceType
+	ServiceType v1.ServiceType
+	// Port 网关对外的 SSH 端口，为空时使用配置文件的 gateway.port
+	Port int32
+	// KeysSecretName 各命名空间中保存用户公钥的 Secret
+	KeysSecretName string
+	// Config 当前生效的配置，为空时使用默认配置
+	Config *config.Store
+
+	authorizedKey string
+}
+
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create
+
+// SetupWithManager 生成网关密钥并定期同步网关的 Deployment 与 Service
+func (r *GatewayReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	gatewayConfig := r.Config.Get().Gateway
+	if r.Name == "" {
+		r.Name = DefaultName
+	}
+	if r.Namespace == "" {
+		r.Namespace = gatewayConfig.Namespace
+	}
+	if r.Image == "" {
+		r.Image = gatewayConfig.Image
+	}
+	if r.ServiceAccountName == "" {
+		r.ServiceAccountName = DefaultServiceAccountName
+	}
+	if r.ServiceType == "" {
+		r.ServiceType = gatewayConfig.ServiceType
+	}
+	if r.Port == 0 {
+		r.Port = gatewayConfig.Port
+	}
+	if r.KeysSecretName == "" {
+		r.KeysSecretName = sshgateway.DefaultKeysSecretName
+	}
+	if r.Reader == nil {
+		r.Reader = mgr.GetAPIReader()
+	}
+	// 公钥在 Tiny 控制器启动前就需要确定，因此不等待缓存同步
+	if err := r.ensureSecret(ctx); err != nil {
+		return err
+	}
+	return mgr.Add(config.Loop{Sync: r.SyncGateway, Period: func() time.Duration {
+		return r.Config.Get().SyncPeriods.Gateway.Duration
+	}})
+}
+
+// AuthorizedKey 返回网关登录 Unit 使用的公钥
+func (r *GatewayReconciler) AuthorizedKey() string {
+	return r.authorizedKey
+}
+
+func (r *GatewayReconciler) ensureSecret(ctx context.Context) error {
+	secret := &v1.Secret{}
+	err := r.Reader.Get(ctx, types.NamespacedName{Namespace: r.Namespace, Name: r.Name}, secret)
+	if apierrors.IsNotFound(err) {
+		secret, err = generateSecret(r.Namespace, r.Name)
+		if err != nil {
+			return err
+		}
+		err = r.Create(ctx, secret)
+	}
+	if err != nil {
+		return err
+	}
+	r.authorizedKey = strings.TrimSpace(string(secret.Data[UpstreamKeyPublic]))
+	return nil
+}
+
+// SyncGateway 创建网关的 Deployment 与 Service，并在配置变化后更新
+func (r *GatewayReconciler) SyncGateway() {
+	ctx := context.TODO()
+	key := types.NamespacedName{Namespace: r.Namespace, Name: r.Name}
+	log := logging.Named("gateway").WithValues("namespace", r.Namespace, "name", r.Name)
+
+	desiredDeployment := generateDeployment(r)
+	deployment := &appsv1.Deployment{}
+	if err := r.Reader.Get(ctx, key, deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			if err := r.Create(ctx, desiredDeployment); err != nil {
+				metrics.LogError(log, "gateway", err, "Failed to create gateway Deployment")
+			} else {
+				log.Info("Created gateway Deployment")
+			}
+		} else {
+			metrics.LogError(log, "gateway", err, "Failed to get gateway Deployment")
+		}
+	} else if len(deployment.Spec.Template.Spec.Containers) == 0 ||
+		deployment.Spec.Template.Spec.ServiceAccountName != r.ServiceAccountName ||
+		deployment.Spec.Template.Spec.Containers[0].Image != r.Image ||
+		!equality.Semantic.DeepEqual(deployment.Spec.Template.Spec.Containers[0].Args,
+			desiredDeployment.Spec.Template.Spec.Containers[0].Args) {
+		deployment.Spec.Template = desiredDeployment.Spec.Template
+		if err := r.Update(ctx, deployment); err != nil {
+			metrics.LogError(log, "gateway", err, "Failed to update gateway Deployment")
+		} else {
+			log.Info("Updated gateway Deployment", "image", r.Image)
+		}
+	}
+
+	desiredService := generateService(r)
+	service := &v1.Service{}
+	if err := r.Reader.Get(ctx, key, service); err != nil {
+		if apierrors.IsNotFound(err) {
+			if err := r.Create(ctx, desiredService); err != nil {
+				metrics.LogError(log, "gateway", err, "Failed to create gateway Service")
+			} else {
+				log.Info("Created gateway Service")
+			}
+		} else {
+			metrics.LogError(log, "gateway", err, "Failed to get gateway Service")
+		}
+	} else if service.Spec.Type != r.ServiceType ||
+		len(service.Spec.Ports) != 1 || service.Spec.Ports[0].Port != r.Port {
+		service.Spec.Type = desiredService.Spec.Type
+		service.Spec.Ports = desiredService.Spec.Ports
+		if err := r.Update(ctx, service); err != nil {
+			metrics.LogError(log, "gateway", err, "Failed to update gateway Service")
+		} else {
+			log.Info("Updated gateway Service", "type", r.ServiceType, "port", r.Port)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package idle
+
+import (
+	"context"
+	"fmt"
+	"github.com/cokeos/zero/controllers/config"
+	"github.com/cokeos/zero/controllers/logging"
+	"github.com/cokeos/zero/controllers/metrics"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+)
+
+const (
+	DefaultWarningPeriod = time.Minute * 10
+
+	ReasonIdleWarning = "IdleWarning"
+	ReasonIdleSuspend = "IdleSuspended"
+	ReasonIdleDelete  = "IdleDeleted"
+)
+
+// IdleReconciler 周期性检测配置了 IdlePolicy 的 Unit，空闲超时后挂起或删除
+type IdleReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Source   MetricsSource
+	Clock    clock.Clock
+	// Config 当前生效的配置，为空时使用默认配置
+	Config *config.Store
+}
+
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=units,verbs=get;list;watch;update;patch;delete
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=units/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=tinies,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// SetupWithManager sets up the idle detection loop with the Manager.
+func (r *IdleReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	if r.Clock == nil {
+		r.Clock = clock.RealClock{}
+	}
+	// 只在主副本上检测，否则多个副本会重复告警与挂起
+	return mgr.Add(config.Loop{Sync: r.SyncIdle, Period: func() time.Duration {
+		return r.Config.Get().SyncPeriods.Idle.Duration
+	}})
+}
+
+func (r *IdleReconciler) SyncIdle() {
+	var (
+		ctx  = context.TODO()
+		log  = logging.Named("idle")
+		list = &corev1.UnitList{}
+	)
+	if err := r.List(ctx, list); err != nil {
+		metrics.LogError(log, "idle", err, "Failed to list Units")
+		return
+	}
+	for i := range list.Items {
+		unit := &list.Items[i]
+		if unit.Spec.IdlePolicy == nil || unit.Spec.Suspend || unit.DeletionTimestamp != nil ||
+			unit.Status.Phase != v1.PodRunning {
+			continue
+		}
+		log := logging.ForObject("idle", "Unit", unit)
+		if err := r.syncUnit(logging.IntoContext(ctx, log), unit); err != nil {
+			metrics.LogError(log, "idle", err, "Failed to sync idle state")
+		}
+	}
+}
+
+func (r *IdleReconciler) syncUnit(ctx context.Context, unit *corev1.Unit) error {
+	pod := &v1.Pod{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: unit.Namespace, Name: unit.Name}, pod); err != nil {
+		return err
+	}
+	activity, err := r.Source.Activity(ctx, pod)
+	if err != nil {
+		return err
+	}
+
+	now := r.Clock.Now()
+	policy := unit.Spec.IdlePolicy
+	active, known := lastActivity(policy, activity, now)
+	if !known {
+		// 没有可用的指标时不做判断，避免误删
+		return nil
+	}
+
+	last := activityBase(unit, pod)
+	if active != nil && active.After(last) {
+		last = *active
+	}
+	unit.Status.LastActivityTime = &metav1.Time{Time: last}
+	if unit.Status.IdleWarningTime != nil && unit.Status.IdleWarningTime.Time.Before(last) {
+		unit.Status.IdleWarningTime = nil
+	}
+
+	idle := now.Sub(last)
+	timeout := policy.Timeout.Duration
+	warning := DefaultWarningPeriod
+	if policy.WarningPeriod != nil {
+		warning = policy.WarningPeriod.Duration
+	}
+	if warning > timeout {
+		warning = timeout
+	}
+
+	// 执行动作前必须先发出告警并等待整个告警期，控制器停止或告警期被调大时顺延，避免未经告警就挂起或删除
+	if idle < timeout-warning {
+		return r.Status().Update(ctx, unit)
+	}
+	if unit.Status.IdleWarningTime == nil {
+		delay := timeout - idle
+		if delay < warning {
+			delay = warning
+		}
+		message, err := r.warningMessage(ctx, unit, idle, delay)
+		if err != nil {
+			return err
+		}
+		r.Recorder.Event(unit, v1.EventTypeWarning, ReasonIdleWarning, message)
+		unit.Status.IdleWarningTime = &metav1.Time{Time: now}
+		return r.Status().Update(ctx, unit)
+	}
+	if idle < timeout || now.Before(unit.Status.IdleWarningTime.Add(warning)) {
+		return r.Status().Update(ctx, unit)
+	}
+	unit.Status.IdleWarningTime = nil
+	if err := r.Status().Update(ctx, unit); err != nil {
+		return err
+	}
+	return r.act(ctx, unit, idle)
+}
+
+// warningMessage 告警事件的内容，删除由 Tiny 创建的 Unit 时会删除整个 Tiny
+func (r *IdleReconciler) warningMessage(ctx context.Context, unit *corev1.Unit, idle, delay time.Duration) (string, error) {
+	message := fmt.Sprintf("Unit has been idle for %s and will be %s in %s",
+		idle.Round(time.Second), actionVerb(unit.Spec.IdlePolicy.Action), delay.Round(time.Second))
+	if unit.Spec.IdlePolicy.Action != corev1.IdleActionDelete {
+		return message, nil
+	}
+	tiny, err := r.owningTiny(ctx, unit)
+	if err != nil || tiny == nil {
+		return message, err
+	}
+	return message + fmt.Sprintf(" together with Tiny %s and its SSH Tunnel", tiny.Name), nil
+}
+
+// owningTiny 创建 Unit 的 Tiny，与 Unit 同名，不存在时返回 nil
+func (r *IdleReconciler) owningTiny(ctx context.Context, unit *corev1.Unit) (*corev1.Tiny, error) {
+	tiny := &corev1.Tiny{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: unit.Namespace, Name: unit.Name}, tiny)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tiny, nil
+}
+
+// act 执行空闲动作，Tiny 创建的 Unit 删除时一并删除 Tiny，否则 Tiny 会重新创建它
+func (r *IdleReconciler) act(ctx context.Context, unit *corev1.Unit, idle time.Duration) error {
+	if unit.Spec.IdlePolicy.Action == corev1.IdleActionDelete {
+		r.Recorder.Eventf(unit, v1.EventTypeWarning, ReasonIdleDelete,
+			"Unit has been idle for %s, deleting", idle.Round(time.Second))
+		tiny, err := r.owningTiny(ctx, unit)
+		if err != nil {
+			return err
+		}
+		if tiny != nil {
+			return client.IgnoreNotFound(r.Delete(ctx, tiny))
+		}
+		return client.IgnoreNotFound(r.Delete(ctx, unit))
+	}
+
+	r.Recorder.Eventf(unit, v1.EventTypeWarning, ReasonIdleSuspend,
+		"Unit has been idle for %s, suspending", idle.Round(time.Second))
+	patch := client.MergeFrom(unit.DeepCopy())
+	unit.Spec.Suspend = true
+	return r.Patch(ctx, unit, patch)
+}
+
+// lastActivity 根据策略判断本次采样的最近活动时间，known 表示是否有可用于判断的指标
+func lastActivity(policy *corev1.IdlePolicy, activity *Activity, now time.Time) (last *time.Time, known bool) {
+	if policy.GPUUtilizationThreshold != nil && activity.GPUUtilization != nil {
+		known = true
+		if *activity.GPUUtilization >= float64(*policy.GPUUtilizationThreshold) {
+			last = &now
+		}
+	}
+	if policy.SSHSessions && activity.SSHSessions != nil {
+		known = true
+		if *activity.SSHSessions > 0 {
+			last = &now
+		}
+	}
+	if policy.JupyterKernels && activity.JupyterLastActivity != nil {
+		known = true
+		jupyter := *activity.JupyterLastActivity
+		if jupyter.After(now) {
+			jupyter = now
+		}
+		if last == nil || jupyter.After(*last) {
+			last = &jupyter
+		}
+	}
+	return last, known
+}
+
+// activityBase 空闲计时的起点：上次观察到的活动、上次恢复或 Pod 启动时间中最晚的一个
+func activityBase(unit *corev1.Unit, pod *v1.Pod) time.Time {
+	base := unit.CreationTimestamp.Time
+	for _, t := range []*metav1.Time{
+		unit.Status.LastActivityTime,
+		unit.Status.LastSuspendTransitionTime,
+		pod.Status.StartTime,
+	} {
+		if t != nil && t.Time.After(base) {
+			base = t.Time
+		}
+	}
+	return base
+}
+
+func actionVerb(action corev1.IdleAction) string {
+	if action == corev1.IdleActionDelete {
+		return "deleted"
+	}
+	return "suspended"
+}
@@ -0,0 +1,215 @@
+package idle
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/internal/zerotest"
+)
+
+func newTestReconciler(now time.Time, activity *Activity, objs ...runtime.Object) (*IdleReconciler, *record.FakeRecorder) {
+	recorder := record.NewFakeRecorder(10)
+	return &IdleReconciler{
+		Client:   zerotest.NewClientBuilder().WithRuntimeObjects(objs...).Build(),
+		Scheme:   zerotest.Scheme,
+		Recorder: recorder,
+		Source: MetricsSourceFunc(func(ctx context.Context, pod *v1.Pod) (*Activity, error) {
+			return activity, nil
+		}),
+		Clock: clock.NewFakeClock(now),
+	}, recorder
+}
+
+func newIdleUnit(start time.Time, action corev1.IdleAction) (*corev1.Unit, *v1.Pod) {
+	threshold := int32(10)
+	unit := &corev1.Unit{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "alice",
+			Name:              "notebook",
+			CreationTimestamp: metav1.Time{Time: start},
+		},
+		Spec: corev1.UnitSpec{
+			IdlePolicy: &corev1.IdlePolicy{
+				Timeout:                 metav1.Duration{Duration: time.Hour},
+				WarningPeriod:           &metav1.Duration{Duration: time.Minute * 10},
+				Action:                  action,
+				GPUUtilizationThreshold: &threshold,
+				SSHSessions:             true,
+			},
+		},
+		Status: corev1.UnitStatus{Phase: v1.PodRunning},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "notebook"},
+		Status:     v1.PodStatus{StartTime: &metav1.Time{Time: start}},
+	}
+	return unit, pod
+}
+
+func getUnit(t *testing.T, r *IdleReconciler) *corev1.Unit {
+	unit := &corev1.Unit{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: "alice", Name: "notebook"}, unit); err != nil {
+		t.Fatal(err)
+	}
+	return unit
+}
+
+func idleActivity() *Activity {
+	utilization := 0.0
+	sessions := 0
+	return &Activity{GPUUtilization: &utilization, SSHSessions: &sessions}
+}
+
+func TestSyncIdleWarnsBeforeTimeout(t *testing.T) {
+	start := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	unit, pod := newIdleUnit(start, corev1.IdleActionSuspend)
+	r, recorder := newTestReconciler(start.Add(time.Minute*55), idleActivity(), unit, pod)
+
+	r.SyncIdle()
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, "Warning "+ReasonIdleWarning) {
+			t.Fatalf("unexpected event %q", event)
+		}
+	default:
+		t.Fatal("expected an idle warning event")
+	}
+	got := getUnit(t, r)
+	if got.Spec.Suspend {
+		t.Fatal("unit should not be suspended before the timeout")
+	}
+	if got.Status.IdleWarningTime == nil {
+		t.Fatal("expected idle warning time to be recorded")
+	}
+
+	// 告警只发送一次
+	r.SyncIdle()
+	if len(recorder.Events) != 0 {
+		t.Fatalf("expected no further events, got %d", len(recorder.Events))
+	}
+}
+
+func TestSyncIdleSuspendsAfterTimeout(t *testing.T) {
+	start := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	unit, pod := newIdleUnit(start, corev1.IdleActionSuspend)
+	r, recorder := newTestReconciler(start.Add(time.Hour*2), idleActivity(), unit, pod)
+
+	// 超时前没有告警过时先告警，整个告警期后才挂起
+	r.SyncIdle()
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Warning "+ReasonIdleWarning) ||
+		!strings.HasSuffix(event, "will be suspended in 10m0s") {
+		t.Fatalf("unexpected event %q", event)
+	}
+	if getUnit(t, r).Spec.Suspend {
+		t.Fatal("unit should not be suspended without a warning period")
+	}
+	r.Clock.(*clock.FakeClock).Step(time.Minute * 9)
+	r.SyncIdle()
+	if getUnit(t, r).Spec.Suspend {
+		t.Fatal("unit should not be suspended before the warning period ends")
+	}
+	r.Clock.(*clock.FakeClock).Step(time.Minute)
+	r.SyncIdle()
+	if !getUnit(t, r).Spec.Suspend {
+		t.Fatal("expected idle unit to be suspended")
+	}
+}
+
+func TestSyncIdleDeletesTiny(t *testing.T) {
+	start := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	unit, pod := newIdleUnit(start, corev1.IdleActionDelete)
+	tiny := &corev1.Tiny{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "notebook"}}
+	r, recorder := newTestReconciler(start.Add(time.Hour*2), idleActivity(), unit, pod, tiny)
+
+	r.SyncIdle()
+	if event := <-recorder.Events; !strings.HasSuffix(event, "will be deleted in 10m0s together with Tiny notebook and its SSH Tunnel") {
+		t.Fatalf("expected the warning to mention the Tiny, got %q", event)
+	}
+	r.Clock.(*clock.FakeClock).Step(time.Minute * 10)
+	r.SyncIdle()
+
+	err := r.Get(context.TODO(), types.NamespacedName{Namespace: "alice", Name: "notebook"}, &corev1.Tiny{})
+	if err == nil {
+		t.Fatal("expected the owning tiny to be deleted")
+	}
+}
+
+func TestSyncIdleKeepsActiveUnit(t *testing.T) {
+	start := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	unit, pod := newIdleUnit(start, corev1.IdleActionSuspend)
+	activity := idleActivity()
+	sessions := 1
+	activity.SSHSessions = &sessions
+	now := start.Add(time.Hour * 2)
+	r, recorder := newTestReconciler(now, activity, unit, pod)
+
+	r.SyncIdle()
+
+	got := getUnit(t, r)
+	if got.Spec.Suspend {
+		t.Fatal("unit with an ssh session should not be suspended")
+	}
+	if got.Status.LastActivityTime == nil || !got.Status.LastActivityTime.Time.Equal(now) {
+		t.Fatalf("expected last activity %v, got %v", now, got.Status.LastActivityTime)
+	}
+	if len(recorder.Events) != 0 {
+		t.Fatalf("expected no events, got %d", len(recorder.Events))
+	}
+}
+
+func TestSyncIdleSkipsUnknownMetrics(t *testing.T) {
+	start := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	unit, pod := newIdleUnit(start, corev1.IdleActionSuspend)
+	r, _ := newTestReconciler(start.Add(time.Hour*2), &Activity{}, unit, pod)
+
+	r.SyncIdle()
+
+	if getUnit(t, r).Spec.Suspend {
+		t.Fatal("unit without metrics should not be suspended")
+	}
+}
+
+func TestPrometheusSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query().Get("query")
+		switch query {
+		case `gpu{pod="notebook"}`:
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1630454400,"42.5"]}]}}`)
+		case `ssh{pod="notebook"}`:
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+		default:
+			t.Errorf("unexpected query %q", query)
+		}
+	}))
+	defer server.Close()
+
+	source := NewPrometheusSource(server.URL)
+	source.GPUQuery = `gpu{pod="$pod"}`
+	source.SSHQuery = `ssh{pod="$pod"}`
+	source.JupyterQuery = ""
+
+	activity, err := source.Activity(context.TODO(), &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "notebook"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if activity.GPUUtilization == nil || *activity.GPUUtilization != 42.5 {
+		t.Fatalf("unexpected gpu utilization %v", activity.GPUUtilization)
+	}
+	if activity.SSHSessions != nil {
+		t.Fatalf("expected unknown ssh sessions, got %d", *activity.SSHSessions)
+	}
+}
@@ -0,0 +1,193 @@
+package idle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Activity 一次采样得到的 Unit 活动情况，字段为空表示该指标不可用
+type Activity struct {
+	// GPUUtilization GPU 利用率（百分比）
+	GPUUtilization *float64 `json:"gpuUtilization,omitempty"`
+	// SSHSessions 当前 SSH 会话数
+	SSHSessions *int `json:"sshSessions,omitempty"`
+	// JupyterLastActivity Jupyter 内核最近一次活动时间
+	JupyterLastActivity *time.Time `json:"jupyterLastActivity,omitempty"`
+}
+
+// MetricsSource 空闲检测的指标来源
+type MetricsSource interface {
+	Activity(ctx context.Context, pod *v1.Pod) (*Activity, error)
+}
+
+// MetricsSourceFunc 将函数适配为 MetricsSource，便于测试时替换
+type MetricsSourceFunc func(ctx context.Context, pod *v1.Pod) (*Activity, error)
+
+func (f MetricsSourceFunc) Activity(ctx context.Context, pod *v1.Pod) (*Activity, error) {
+	return f(ctx, pod)
+}
+
+const (
+	// DefaultGPUQuery dcgm-exporter 导出的 GPU 利用率
+	DefaultGPUQuery = `avg(DCGM_FI_DEV_GPU_UTIL{namespace="$namespace",pod="$pod"})`
+	// DefaultSSHQuery 镜像内 exporter 导出的 SSH 会话数
+	DefaultSSHQuery = `sum(zero_ssh_sessions{namespace="$namespace",pod="$pod"})`
+	// DefaultJupyterQuery 镜像内 exporter 导出的 Jupyter 内核最近活动时间戳
+	DefaultJupyterQuery = `max(zero_jupyter_last_activity_timestamp_seconds{namespace="$namespace",pod="$pod"})`
+
+	DefaultSidecarPort = configv1alpha1.DefaultIdleSidecarPort
+	DefaultSidecarPath = "/activity"
+
+	DefaultMetricsTimeout = time.Second * 5
+)
+
+// PrometheusSource 通过 Prometheus 查询 API 获取活动情况，查询语句中的
+// $namespace 与 $pod 会被替换为 Pod 的命名空间与名称，语句为空时跳过该指标
+type PrometheusSource struct {
+	Address      string
+	GPUQuery     string
+	SSHQuery     string
+	JupyterQuery string
+	Client       *http.Client
+}
+
+func NewPrometheusSource(address string) *PrometheusSource {
+	return &PrometheusSource{
+		Address:      strings.TrimSuffix(address, "/"),
+		GPUQuery:     DefaultGPUQuery,
+		SSHQuery:     DefaultSSHQuery,
+		JupyterQuery: DefaultJupyterQuery,
+		Client:       &http.Client{Timeout: DefaultMetricsTimeout},
+	}
+}
+
+func (s *PrometheusSource) Activity(ctx context.Context, pod *v1.Pod) (*Activity, error) {
+	replacer := strings.NewReplacer("$namespace", pod.Namespace, "$pod", pod.Name)
+	activity := &Activity{}
+
+	if s.GPUQuery != "" {
+		value, ok, err := s.query(ctx, replacer.Replace(s.GPUQuery))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			activity.GPUUtilization = &value
+		}
+	}
+	if s.SSHQuery != "" {
+		value, ok, err := s.query(ctx, replacer.Replace(s.SSHQuery))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			sessions := int(value)
+			activity.SSHSessions = &sessions
+		}
+	}
+	if s.JupyterQuery != "" {
+		value, ok, err := s.query(ctx, replacer.Replace(s.JupyterQuery))
+		if err != nil {
+			return nil, err
+		}
+		if ok && value > 0 {
+			last := time.Unix(int64(value), 0)
+			activity.JupyterLastActivity = &last
+		}
+	}
+	return activity, nil
+}
+
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Value []interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// query 执行即时查询，返回第一个样本的值；结果为空时 ok 为 false
+func (s *PrometheusSource) query(ctx context.Context, query string) (value float64, ok bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		s.Address+"/api/v1/query?query="+url.QueryEscape(query), nil)
+	if err != nil {
+		return 0, false, err
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+
+	result := &prometheusResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return 0, false, fmt.Errorf("decode prometheus response: %v", err)
+	}
+	if result.Status != "success" {
+		return 0, false, fmt.Errorf("prometheus query %q failed: %s", query, result.Error)
+	}
+	if result.Data.ResultType != "vector" || len(result.Data.Result) == 0 {
+		return 0, false, nil
+	}
+	sample := result.Data.Result[0].Value
+	if len(sample) != 2 {
+		return 0, false, fmt.Errorf("unexpected prometheus sample %v", sample)
+	}
+	raw, _ := sample[1].(string)
+	value, err = strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("parse prometheus sample %q: %v", raw, err)
+	}
+	return value, true, nil
+}
+
+// SidecarSource 从 Pod 内 sidecar 暴露的 HTTP 接口读取 Activity 的 JSON
+type SidecarSource struct {
+	Port   int
+	Path   string
+	Client *http.Client
+}
+
+func NewSidecarSource(port int) *SidecarSource {
+	return &SidecarSource{
+		Port:   port,
+		Path:   DefaultSidecarPath,
+		Client: &http.Client{Timeout: DefaultMetricsTimeout},
+	}
+}
+
+func (s *SidecarSource) Activity(ctx context.Context, pod *v1.Pod) (*Activity, error) {
+	if pod.Status.PodIP == "" {
+		return nil, fmt.Errorf("pod %s/%s has no IP", pod.Namespace, pod.Name)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"http://"+pod.Status.PodIP+":"+strconv.Itoa(s.Port)+s.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sidecar of pod %s/%s returned %s", pod.Namespace, pod.Name, resp.Status)
+	}
+
+	activity := &Activity{}
+	if err := json.NewDecoder(resp.Body).Decode(activity); err != nil {
+		return nil, fmt.Errorf("decode sidecar response: %v", err)
+	}
+	return activity, nil
+}
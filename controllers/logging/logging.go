@@ -0,0 +1,59 @@
+// Package logging 为各控制器提供带对象键值的 logr 日志，键统一为 kind、namespace、name、generation 与 reconcileID
+package logging
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// 日志级别，默认只输出 V(0)，--zap-log-level=debug 输出 Debug，--zap-log-level=2 输出 Trace
+const (
+	// Debug 创建、删除子资源等每次同步至多一次的动作
+	Debug = 1
+	// Trace 每次同步都会经过的分支，例如跳过、等待与重新入队
+	Trace = 2
+)
+
+// Reconcile 为一次同步生成 reconcileID 并与 kind 一起加入 ctx 中的日志
+//
+// namespace 与 name 已由 controller-runtime 加入，读取对象后再以 WithObject 加入 generation。
+func Reconcile(ctx context.Context, kind string) (context.Context, logr.Logger) {
+	log := ctrllog.FromContext(ctx).WithValues("kind", kind, "reconcileID", string(uuid.NewUUID()))
+	return ctrllog.IntoContext(ctx, log), log
+}
+
+// WithObject 将对象的 generation 加入 ctx 中的日志
+func WithObject(ctx context.Context, obj client.Object) (context.Context, logr.Logger) {
+	log := ctrllog.FromContext(ctx).WithValues("generation", obj.GetGeneration())
+	return ctrllog.IntoContext(ctx, log), log
+}
+
+// FromContext 返回 ctx 中的日志，没有时返回全局日志
+func FromContext(ctx context.Context) logr.Logger {
+	return ctrllog.FromContext(ctx)
+}
+
+// IntoContext 将日志放入 ctx，供接收 ctx 的辅助函数使用
+func IntoContext(ctx context.Context, log logr.Logger) context.Context {
+	return ctrllog.IntoContext(ctx, log)
+}
+
+// ForObject 周期同步等没有请求上下文的场景使用，键与 Reconcile 一致，controller 为日志名
+func ForObject(controller, kind string, obj client.Object) logr.Logger {
+	return ctrl.Log.WithName(controller).WithValues(
+		"kind", kind,
+		"namespace", obj.GetNamespace(),
+		"name", obj.GetName(),
+		"generation", obj.GetGeneration(),
+	)
+}
+
+// Named 周期同步的日志，controller 为日志名
+func Named(controller string) logr.Logger {
+	return ctrl.Log.WithName(controller)
+}
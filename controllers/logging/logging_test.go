@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// recorder 记录 WithValues 累积的键值
+type recorder struct {
+	values []interface{}
+}
+
+func (r *recorder) Enabled() bool                                             { return true }
+func (r *recorder) Info(msg string, keysAndValues ...interface{})             {}
+func (r *recorder) Error(err error, msg string, keysAndValues ...interface{}) {}
+func (r *recorder) V(level int) logr.Logger                                   { return r }
+func (r *recorder) WithName(name string) logr.Logger                          { return r }
+func (r *recorder) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return &recorder{values: append(append([]interface{}{}, r.values...), keysAndValues...)}
+}
+
+func keys(log logr.Logger) map[string]interface{} {
+	values := log.(*recorder).values
+	keys := map[string]interface{}{}
+	for i := 0; i+1 < len(values); i += 2 {
+		keys[values[i].(string)] = values[i+1]
+	}
+	return keys
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := ctrllog.IntoContext(context.TODO(), (&recorder{}).WithValues("namespace", "alice", "name", "train"))
+	ctx, first := Reconcile(ctx, "Unit")
+	_, second := Reconcile(ctrllog.IntoContext(context.TODO(), &recorder{}), "Unit")
+	if keys(first)["reconcileID"] == keys(second)["reconcileID"] {
+		t.Error("expected a new reconcileID for every reconcile")
+	}
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train", Generation: 3}}
+	ctx, log := WithObject(ctx, pod)
+	got := keys(FromContext(ctx))
+	for key, value := range map[string]interface{}{"kind": "Unit", "namespace": "alice", "name": "train", "generation": int64(3)} {
+		if got[key] != value {
+			t.Errorf("expected %s=%v, got %v", key, value, got[key])
+		}
+	}
+	if keys(log)["reconcileID"] != keys(first)["reconcileID"] {
+		t.Error("expected the reconcileID to be kept")
+	}
+}
@@ -0,0 +1,189 @@
+// Package metrics 导出控制器的 Prometheus 指标，注册到 controller-runtime 的 Registry，
+// 与其内置指标一起由 manager 的 metrics 端点提供，config/prometheus/monitor.yaml 无需改动
+package metrics
+
+import (
+	"context"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var log = ctrl.Log.WithName("metrics")
+
+const namespace = "zero"
+
+var (
+	// ReconcileErrors 各控制器同步过程中的错误次数
+	ReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of errors encountered while reconciling, per controller.",
+	}, []string{"controller"})
+
+	// UnitTimeToRunning Unit 的 Pod 从创建到容器运行的耗时
+	UnitTimeToRunning = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "unit_time_to_running_seconds",
+		Help:      "Time from Pod creation until the Unit container is running.",
+		Buckets:   []float64{5, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"framework", "gpu_model"})
+
+	// UsageGPUSeconds 用量统计记录的 GPU 卡秒，按命名空间、用户与 GPU 型号区分
+	UsageGPUSeconds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "usage_gpu_seconds_total",
+		Help:      "GPU seconds recorded by usage accounting.",
+	}, []string{"namespace", "user", "gpu_model"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(ReconcileErrors, UnitTimeToRunning, UsageGPUSeconds)
+}
+
+// LogError 以 log 记录错误并计入 controller 的错误次数，log 应带有对象的键值
+func LogError(log logr.Logger, controller string, err error, msg string, keysAndValues ...interface{}) {
+	log.Error(err, msg, keysAndValues...)
+	ReconcileErrors.WithLabelValues(controller).Inc()
+}
+
+// ObserveTimeToRunning 记录 Unit 进入 Running 的耗时，以容器启动时间为准
+func ObserveTimeToRunning(unit *corev1.Unit, pod *v1.Pod) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running == nil {
+			continue
+		}
+		duration := status.State.Running.StartedAt.Sub(pod.CreationTimestamp.Time)
+		if duration < 0 {
+			return
+		}
+		UnitTimeToRunning.WithLabelValues(unit.Spec.Framework.Name, gpuModel(unit)).Observe(duration.Seconds())
+		return
+	}
+}
+
+// RegisterNodePortPool 注册 Tiny NodePort 池的已用与总数
+func RegisterNodePortPool(used, total func() float64) error {
+	for _, gauge := range []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "tiny_nodeports_used",
+			Help:      "Number of NodePorts allocated to Tinies.",
+		}, used),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "tiny_nodeports_total",
+			Help:      "Size of the Tiny NodePort pool.",
+		}, total),
+	} {
+		if err := metrics.Registry.Register(gauge); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	unitsDesc = prometheus.NewDesc(namespace+"_units",
+		"Number of Units by phase, framework and GPU model.",
+		[]string{"namespace", "phase", "framework", "gpu_model"}, nil)
+	gpusDesc = prometheus.NewDesc(namespace+"_gpus_allocated",
+		"Number of GPUs allocated to running Units.",
+		[]string{"namespace"}, nil)
+	tunnelsDesc = prometheus.NewDesc(namespace+"_tunnels",
+		"Number of Tunnels by readiness.",
+		[]string{"namespace", "ready"}, nil)
+)
+
+// DefaultCollectTimeout 采集时列出资源的超时时间
+const DefaultCollectTimeout = time.Second * 10
+
+// StateCollector 在采集时从缓存中统计 Unit、GPU 分配与 Tunnel 的状态
+type StateCollector struct {
+	Reader client.Reader
+}
+
+// RegisterStateCollector 注册 StateCollector，reader 通常为 manager 的缓存客户端
+func RegisterStateCollector(reader client.Reader) error {
+	return metrics.Registry.Register(&StateCollector{Reader: reader})
+}
+
+func (c *StateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- unitsDesc
+	ch <- gpusDesc
+	ch <- tunnelsDesc
+}
+
+func (c *StateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCollectTimeout)
+	defer cancel()
+	c.collectUnits(ctx, ch)
+	c.collectTunnels(ctx, ch)
+}
+
+func (c *StateCollector) collectUnits(ctx context.Context, ch chan<- prometheus.Metric) {
+	units := &corev1.UnitList{}
+	if err := c.Reader.List(ctx, units); err != nil {
+		log.Error(err, "Failed to list Units")
+		ch <- prometheus.NewInvalidMetric(unitsDesc, err)
+		return
+	}
+	type key struct{ namespace, phase, framework, model string }
+	counts := map[key]float64{}
+	gpus := map[string]float64{}
+	for _, unit := range units.Items {
+		phase := string(unit.Status.Phase)
+		if phase == "" {
+			phase = "Unknown"
+		}
+		counts[key{unit.Namespace, phase, unit.Spec.Framework.Name, gpuModel(&unit)}]++
+		if unit.Spec.GPUPolicy.GPU && unit.Status.Phase == v1.PodRunning {
+			gpus[unit.Namespace] += float64(unit.Spec.GPUPolicy.Number)
+		}
+	}
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(unitsDesc, prometheus.GaugeValue, count, k.namespace, k.phase, k.framework, k.model)
+	}
+	for ns, count := range gpus {
+		ch <- prometheus.MustNewConstMetric(gpusDesc, prometheus.GaugeValue, count, ns)
+	}
+}
+
+func (c *StateCollector) collectTunnels(ctx context.Context, ch chan<- prometheus.Metric) {
+	tunnels := &corev1.TunnelList{}
+	if err := c.Reader.List(ctx, tunnels); err != nil {
+		log.Error(err, "Failed to list Tunnels")
+		ch <- prometheus.NewInvalidMetric(tunnelsDesc, err)
+		return
+	}
+	type key struct{ namespace, ready string }
+	counts := map[key]float64{}
+	for _, tunnel := range tunnels.Items {
+		ready := "false"
+		if meta.IsStatusConditionTrue(tunnel.Status.Conditions, corev1.TunnelReady) {
+			ready = "true"
+		}
+		counts[key{tunnel.Namespace, ready}]++
+	}
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(tunnelsDesc, prometheus.GaugeValue, count, k.namespace, k.ready)
+	}
+}
+
+// gpuModel 未使用 GPU 的 Unit 记为 none，未指定型号的记为 any
+func gpuModel(unit *corev1.Unit) string {
+	if !unit.Spec.GPUPolicy.GPU {
+		return "none"
+	}
+	if unit.Spec.GPUPolicy.Model == "" {
+		return "any"
+	}
+	return unit.Spec.GPUPolicy.Model
+}
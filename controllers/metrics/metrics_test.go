@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/internal/zerotest"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newUnit(namespace, name string, phase v1.PodPhase, gpus int, model string) *corev1.Unit {
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	unit.Spec.Framework.Name = "pytorch"
+	unit.Spec.GPUPolicy = corev1.GPUPolicy{GPU: gpus > 0, Number: gpus, Model: model}
+	unit.Status.Phase = phase
+	return unit
+}
+
+func TestStateCollector(t *testing.T) {
+	ready := &corev1.Tunnel{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "a"}}
+	ready.Status.Conditions = []metav1.Condition{{Type: corev1.TunnelReady, Status: metav1.ConditionTrue}}
+	reader := zerotest.NewClientBuilder().WithObjects(
+		newUnit("alice", "a", v1.PodRunning, 2, "RTX-3090"),
+		newUnit("alice", "b", v1.PodRunning, 1, "RTX-3090"),
+		newUnit("alice", "c", v1.PodPending, 4, ""),
+		newUnit("bob", "a", "", 0, ""),
+		ready,
+		&corev1.Tunnel{ObjectMeta: metav1.ObjectMeta{Namespace: "bob", Name: "a"}},
+	).Build()
+
+	expected := `
+# HELP zero_gpus_allocated Number of GPUs allocated to running Units.
+# TYPE zero_gpus_allocated gauge
+zero_gpus_allocated{namespace="alice"} 3
+# HELP zero_tunnels Number of Tunnels by readiness.
+# TYPE zero_tunnels gauge
+zero_tunnels{namespace="alice",ready="true"} 1
+zero_tunnels{namespace="bob",ready="false"} 1
+# HELP zero_units Number of Units by phase, framework and GPU model.
+# TYPE zero_units gauge
+zero_units{framework="pytorch",gpu_model="RTX-3090",namespace="alice",phase="Running"} 2
+zero_units{framework="pytorch",gpu_model="any",namespace="alice",phase="Pending"} 1
+zero_units{framework="pytorch",gpu_model="none",namespace="bob",phase="Unknown"} 1
+`
+	if err := testutil.CollectAndCompare(&StateCollector{Reader: reader}, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestObserveTimeToRunning(t *testing.T) {
+	unit := newUnit("alice", "a", v1.PodRunning, 1, "RTX-3090")
+	created := time.Now()
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)}}
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{{
+		State: v1.ContainerState{Running: &v1.ContainerStateRunning{StartedAt: metav1.NewTime(created.Add(42 * time.Second))}},
+	}}
+	ObserveTimeToRunning(unit, pod)
+	if count := testutil.CollectAndCount(UnitTimeToRunning); count != 1 {
+		t.Errorf("expected one histogram, got %d", count)
+	}
+}
+
+func TestLogError(t *testing.T) {
+	LogError(log, "test", errors.New("boom"), "Something failed")
+	if value := testutil.ToFloat64(ReconcileErrors.WithLabelValues("test")); value != 1 {
+		t.Errorf("expected one error, got %v", value)
+	}
+}
@@ -0,0 +1,202 @@
+package project
+
+import (
+	"strings"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// LabelValue Project 管理的对象上 LabelKey 标签的取值，标签键来自配置文件
+	LabelValue = "true"
+
+	QuotaName         = "zero-quota"
+	NetworkPolicyName = "zero-isolation"
+
+	// NamespaceNameLabelKey Kubernetes 为每个命名空间自动设置的名称标签
+	NamespaceNameLabelKey = "kubernetes.io/metadata.name"
+
+	AdminRole          = "admin"
+	UnitEditorRole     = "zero-unit-editor-role"
+	UnitViewerRole     = "zero-unit-viewer-role"
+	TinyEditorRole     = "zero-tiny-editor-role"
+	TinyViewerRole     = "zero-tiny-viewer-role"
+	SnapshotEditorRole = "zero-snapshot-editor-role"
+	SnapshotViewerRole = "zero-snapshot-viewer-role"
+	// UsageReportViewerRole 用量报表由控制器生成，所有角色都只能查看
+	UsageReportViewerRole = "zero-usagereport-viewer-role"
+)
+
+// DefaultQuota 未指定配额时的默认配额
+func DefaultQuota() v1.ResourceList {
+	return v1.ResourceList{
+		"requests." + corev1.ResourceNvidiaGPU: resource.MustParse("4"),
+		"count/units.core.cokeos.io":           resource.MustParse("10"),
+		v1.ResourceRequestsStorage:             resource.MustParse("500Gi"),
+	}
+}
+
+// projectRoles 各项目角色绑定的 ClusterRole
+//
+// 控制器只被授权绑定这些 ClusterRole，修改时需同步 ProjectReconciler 的 RBAC 标记。
+func projectRoles(role corev1.ProjectRole) []string {
+	switch role {
+	case corev1.ProjectOwner:
+		return []string{AdminRole, UnitEditorRole, TinyEditorRole, SnapshotEditorRole, UsageReportViewerRole}
+	case corev1.ProjectMember:
+		return []string{UnitEditorRole, TinyEditorRole, SnapshotEditorRole, UsageReportViewerRole}
+	}
+	return []string{UnitViewerRole, TinyViewerRole, SnapshotViewerRole, UsageReportViewerRole}
+}
+
+func projectNamespace(project *corev1.Project) string {
+	if project.Spec.Namespace != "" {
+		return project.Spec.Namespace
+	}
+	return project.Name
+}
+
+func projectLabels(project *corev1.Project, labelKey string) map[string]string {
+	return map[string]string{
+		labelKey:               LabelValue,
+		corev1.ProjectLabelKey: project.Name,
+	}
+}
+
+func generateNamespace(project *corev1.Project, labelKey string) *v1.Namespace {
+	return &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   projectNamespace(project),
+			Labels: projectLabels(project, labelKey),
+		},
+	}
+}
+
+// roleBindingName 例如 zero-owner-unit-editor
+func roleBindingName(role corev1.ProjectRole, clusterRole string) string {
+	name := strings.TrimSuffix(strings.TrimPrefix(clusterRole, "zero-"), "-role")
+	return "zero-" + strings.ToLower(string(role)) + "-" + name
+}
+
+func generateRoleBinding(project *corev1.Project, role corev1.ProjectRole, clusterRole string, subjects []rbacv1.Subject, labelKey string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: projectNamespace(project),
+			Name:      roleBindingName(role, clusterRole),
+			Labels:    projectLabels(project, labelKey),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     clusterRole,
+		},
+		Subjects: subjects,
+	}
+}
+
+func generateQuota(project *corev1.Project, labelKey string) *v1.ResourceQuota {
+	hard := project.Spec.Quota.DeepCopy()
+	if len(hard) == 0 {
+		hard = DefaultQuota()
+	}
+	return &v1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: projectNamespace(project),
+			Name:      QuotaName,
+			Labels:    projectLabels(project, labelKey),
+		},
+		Spec: v1.ResourceQuotaSpec{
+			Hard: hard,
+		},
+	}
+}
+
+// generateNetworkPolicy 只允许同一命名空间及系统命名空间的访问
+//
+// Unit 的 Pod 需要经 Tunnel 对外暴露端口，以 unitLabelKey 标签识别，不在此策略的范围内。
+func generateNetworkPolicy(project *corev1.Project, systemNamespace, unitLabelKey, labelKey string) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: projectNamespace(project),
+			Name:      NetworkPolicyName,
+			Labels:    projectLabels(project, labelKey),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      unitLabelKey,
+						Operator: metav1.LabelSelectorOpDoesNotExist,
+					},
+				},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{
+							PodSelector: &metav1.LabelSelector{},
+						},
+						{
+							NamespaceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{
+									NamespaceNameLabelKey: systemNamespace,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func generateWorkspace(project *corev1.Project, labelKey string) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: projectNamespace(project),
+			Name:      corev1.WorkspaceClaimName,
+			Labels:    projectLabels(project, labelKey),
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			// 同一项目的 Unit 共享工作区
+			AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteMany},
+			StorageClassName: project.Spec.Storage.StorageClassName,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceStorage: project.Spec.Storage.Size.DeepCopy(),
+				},
+			},
+		},
+	}
+}
+
+// members 按角色汇总项目成员
+func members(project *corev1.Project) []corev1.ProjectMemberStatus {
+	list := make([]corev1.ProjectMemberStatus, 0)
+	for _, role := range []corev1.ProjectRole{corev1.ProjectOwner, corev1.ProjectMember, corev1.ProjectViewer} {
+		for _, subject := range subjects(project, role) {
+			list = append(list, corev1.ProjectMemberStatus{
+				Kind: subject.Kind,
+				Name: subject.Name,
+				Role: role,
+			})
+		}
+	}
+	return list
+}
+
+func subjects(project *corev1.Project, role corev1.ProjectRole) []rbacv1.Subject {
+	switch role {
+	case corev1.ProjectOwner:
+		return project.Spec.Owners
+	case corev1.ProjectMember:
+		return project.Spec.Members
+	}
+	return project.Spec.Viewers
+}
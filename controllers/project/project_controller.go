@@ -0,0 +1,294 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cokeos/zero/controllers/config"
+	"github.com/cokeos/zero/controllers/logging"
+	"github.com/cokeos/zero/controllers/metrics"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	DefaultSystemNamespace = "zero-system"
+
+	ReasonReady             = "Ready"
+	ReasonNamespaceConflict = "NamespaceConflict"
+	ReasonSyncFailed        = "SyncFailed"
+)
+
+var errNamespaceConflict = errors.New("namespace conflict")
+
+// ProjectReconciler reconciles a Project object
+type ProjectReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// SystemNamespace operator 所在的命名空间，网络隔离时允许其访问
+	SystemNamespace string
+	// Config 当前生效的配置，为空时使用默认配置
+	Config *config.Store
+}
+
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=projects,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=projects/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=projects/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups="",resources=resourcequotas,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=bind,resourceNames=admin;zero-unit-editor-role;zero-unit-viewer-role;zero-tiny-editor-role;zero-tiny-viewer-role;zero-snapshot-editor-role;zero-snapshot-viewer-role;zero-usagereport-viewer-role
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.10.0/pkg/reconcile
+func (r *ProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, log := logging.Reconcile(ctx, "Project")
+	project := &corev1.Project{}
+	if err := r.Get(ctx, req.NamespacedName, project); err != nil {
+		if !apierrors.IsNotFound(err) {
+			metrics.LogError(log, "project", err, "Failed to get Project")
+		}
+		return ctrl.Result{}, nil
+	}
+	ctx, log = logging.WithObject(ctx, project)
+	// 命名空间及其中的资源通过 OwnerReference 随 Project 一起回收
+	if project.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	ready := metav1.Condition{
+		Type:    corev1.ProjectReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  ReasonReady,
+		Message: "project is ready",
+	}
+	if err := r.sync(ctx, project); err != nil {
+		metrics.LogError(log, "project", err, "Failed to sync Project")
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = ReasonSyncFailed
+		if errors.Is(err, errNamespaceConflict) {
+			ready.Reason = ReasonNamespaceConflict
+		}
+		ready.Message = err.Error()
+	}
+
+	status := project.Status.DeepCopy()
+	ready.ObservedGeneration = project.Generation
+	meta.SetStatusCondition(&status.Conditions, ready)
+	status.Namespace = projectNamespace(project)
+	status.Members = members(project)
+	if err := r.usage(ctx, project, status); err != nil {
+		metrics.LogError(log, "project", err, "Failed to collect Project usage")
+	}
+	if !equality.Semantic.DeepEqual(status, &project.Status) {
+		project.Status = *status
+		if err := r.Status().Update(ctx, project); err != nil {
+			metrics.LogError(log, "project", err, "Failed to update Project status")
+		}
+	}
+	return ctrl.Result{RequeueAfter: r.Config.Get().SyncPeriods.Project.Duration}, nil
+}
+
+// sync 创建或更新项目的命名空间、权限、配额、网络策略与工作区
+func (r *ProjectReconciler) sync(ctx context.Context, project *corev1.Project) error {
+	if err := r.syncNamespace(ctx, project); err != nil {
+		return err
+	}
+	c := r.Config.Get()
+	for _, role := range []corev1.ProjectRole{corev1.ProjectOwner, corev1.ProjectMember, corev1.ProjectViewer} {
+		for _, clusterRole := range projectRoles(role) {
+			desired := generateRoleBinding(project, role, clusterRole, subjects(project, role), c.Project.LabelKey)
+			if len(desired.Subjects) == 0 {
+				if err := r.Delete(ctx, desired); err != nil && !apierrors.IsNotFound(err) {
+					return err
+				}
+				continue
+			}
+			binding := &rbacv1.RoleBinding{ObjectMeta: desired.ObjectMeta}
+			if err := r.apply(ctx, project, binding, func() {
+				binding.Labels = desired.Labels
+				binding.RoleRef = desired.RoleRef
+				binding.Subjects = desired.Subjects
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	desiredQuota := generateQuota(project, c.Project.LabelKey)
+	quota := &v1.ResourceQuota{ObjectMeta: desiredQuota.ObjectMeta}
+	if err := r.apply(ctx, project, quota, func() {
+		quota.Labels = desiredQuota.Labels
+		quota.Spec.Hard = desiredQuota.Spec.Hard
+	}); err != nil {
+		return err
+	}
+
+	desiredPolicy := generateNetworkPolicy(project, r.SystemNamespace, c.Unit.LabelKey, c.Project.LabelKey)
+	if project.Spec.NetworkIsolation == nil || *project.Spec.NetworkIsolation {
+		policy := &networkingv1.NetworkPolicy{ObjectMeta: desiredPolicy.ObjectMeta}
+		if err := r.apply(ctx, project, policy, func() {
+			policy.Labels = desiredPolicy.Labels
+			policy.Spec = desiredPolicy.Spec
+		}); err != nil {
+			return err
+		}
+	} else if err := r.Delete(ctx, desiredPolicy); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	// 移除 Storage 时保留工作区，避免误删数据
+	if project.Spec.Storage != nil {
+		return r.syncWorkspace(ctx, project)
+	}
+	return nil
+}
+
+// syncNamespace 创建项目的命名空间
+//
+// 已存在的命名空间只有由该 Project 创建，或管理员设置了 ProjectAdoptAnnotation 时才能使用，
+// 否则任何能创建 Project 的用户都能成为 kube-system 等命名空间的管理员。
+func (r *ProjectReconciler) syncNamespace(ctx context.Context, project *corev1.Project) error {
+	namespace := &v1.Namespace{}
+	err := r.Get(ctx, types.NamespacedName{Name: projectNamespace(project)}, namespace)
+	if apierrors.IsNotFound(err) {
+		namespace = generateNamespace(project, r.Config.Get().Project.LabelKey)
+		if err := controllerutil.SetControllerReference(project, namespace, r.Scheme); err != nil {
+			return err
+		}
+		return r.Create(ctx, namespace)
+	}
+	if err != nil {
+		return err
+	}
+	if namespace.DeletionTimestamp != nil {
+		return fmt.Errorf("namespace %s is terminating", namespace.Name)
+	}
+	if owner := namespace.Labels[corev1.ProjectLabelKey]; owner != "" && owner != project.Name {
+		return fmt.Errorf("%w: %s belongs to project %s", errNamespaceConflict, namespace.Name, owner)
+	}
+	if !metav1.IsControlledBy(namespace, project) && namespace.Annotations[corev1.ProjectAdoptAnnotation] != project.Name {
+		return fmt.Errorf("%w: %s was not created by the project, set the %s annotation to %s to use it",
+			errNamespaceConflict, namespace.Name, corev1.ProjectAdoptAnnotation, project.Name)
+	}
+	labels := projectLabels(project, r.Config.Get().Project.LabelKey)
+	changed := false
+	for k, v := range labels {
+		if namespace.Labels[k] != v {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if namespace.Labels == nil {
+		namespace.Labels = make(map[string]string)
+	}
+	for k, v := range labels {
+		namespace.Labels[k] = v
+	}
+	return r.Update(ctx, namespace)
+}
+
+// syncWorkspace 创建工作区，已有工作区只允许扩容
+func (r *ProjectReconciler) syncWorkspace(ctx context.Context, project *corev1.Project) error {
+	desired := generateWorkspace(project, r.Config.Get().Project.LabelKey)
+	claim := &v1.PersistentVolumeClaim{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(desired), claim)
+	if apierrors.IsNotFound(err) {
+		if err := controllerutil.SetControllerReference(project, desired, r.Scheme); err != nil {
+			return err
+		}
+		return r.Create(ctx, desired)
+	}
+	if err != nil {
+		return err
+	}
+	size := desired.Spec.Resources.Requests[v1.ResourceStorage]
+	if current := claim.Spec.Resources.Requests[v1.ResourceStorage]; size.Cmp(current) > 0 {
+		claim.Spec.Resources.Requests[v1.ResourceStorage] = size
+		return r.Update(ctx, claim)
+	}
+	return nil
+}
+
+func (r *ProjectReconciler) apply(ctx context.Context, project *corev1.Project, obj client.Object, mutate func()) error {
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, obj, func() error {
+		mutate()
+		return controllerutil.SetControllerReference(project, obj, r.Scheme)
+	})
+	return err
+}
+
+// usage 汇总命名空间的配额、用量及 Unit、Tiny 数量
+func (r *ProjectReconciler) usage(ctx context.Context, project *corev1.Project, status *corev1.ProjectStatus) error {
+	namespace := projectNamespace(project)
+	quota := &v1.ResourceQuota{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: QuotaName}, quota); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+	} else {
+		status.Quota = quota.Status.Hard
+		status.Used = quota.Status.Used
+	}
+
+	units := &corev1.UnitList{}
+	if err := r.List(ctx, units, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	tinies := &corev1.TinyList{}
+	if err := r.List(ctx, tinies, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	status.Units = int32(len(units.Items))
+	status.Tinies = int32(len(tinies.Items))
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ProjectReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.SystemNamespace == "" {
+		r.SystemNamespace = DefaultSystemNamespace
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Project{}).
+		Owns(&v1.Namespace{}).
+		Owns(&v1.ResourceQuota{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Complete(r)
+}
@@ -0,0 +1,218 @@
+package project
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/internal/zerotest"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+func newTestReconciler(objs ...client.Object) *ProjectReconciler {
+	return &ProjectReconciler{
+		Client:          zerotest.NewClient(objs...),
+		Scheme:          zerotest.Scheme,
+		SystemNamespace: DefaultSystemNamespace,
+	}
+}
+
+func newProject() *corev1.Project {
+	return &corev1.Project{
+		ObjectMeta: metav1.ObjectMeta{Name: "vision", UID: "vision-uid"},
+		Spec: corev1.ProjectSpec{
+			Owners:  []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "alice"}},
+			Viewers: []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "bob"}},
+			Storage: &corev1.ProjectStorage{Size: resource.MustParse("100Gi")},
+		},
+	}
+}
+
+func reconcile(t *testing.T, r *ProjectReconciler, project *corev1.Project) *corev1.Project {
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: project.Name}}); err != nil {
+		t.Fatal(err)
+	}
+	current := &corev1.Project{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: project.Name}, current); err != nil {
+		t.Fatal(err)
+	}
+	return current
+}
+
+func TestReconcileProject(t *testing.T) {
+	project := newProject()
+	r := newTestReconciler(project)
+	current := reconcile(t, r, project)
+
+	ready := meta.FindStatusCondition(current.Status.Conditions, corev1.ProjectReady)
+	if ready == nil || ready.Status != metav1.ConditionTrue {
+		t.Fatalf("expected the project to be ready, got %+v", ready)
+	}
+	if current.Status.Namespace != "vision" || len(current.Status.Members) != 2 {
+		t.Errorf("unexpected status %+v", current.Status)
+	}
+
+	namespace := &v1.Namespace{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "vision"}, namespace); err != nil {
+		t.Fatal(err)
+	}
+	if namespace.Labels[corev1.ProjectLabelKey] != "vision" || !metav1.IsControlledBy(namespace, project) {
+		t.Errorf("expected the namespace to belong to the project, got %+v", namespace.ObjectMeta)
+	}
+
+	for _, role := range []corev1.ProjectRole{corev1.ProjectOwner, corev1.ProjectMember, corev1.ProjectViewer} {
+		for _, clusterRole := range projectRoles(role) {
+			binding := &rbacv1.RoleBinding{}
+			err := r.Get(context.TODO(), types.NamespacedName{Namespace: "vision", Name: roleBindingName(role, clusterRole)}, binding)
+			if role == corev1.ProjectMember {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("expected no binding without members, got %v", err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if binding.RoleRef.Name != clusterRole || len(binding.Subjects) != 1 {
+				t.Errorf("unexpected binding %s: %+v", binding.Name, binding)
+			}
+		}
+	}
+
+	quota := &v1.ResourceQuota{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: "vision", Name: QuotaName}, quota); err != nil {
+		t.Fatal(err)
+	}
+	if len(quota.Spec.Hard) != len(DefaultQuota()) {
+		t.Errorf("expected the default quota, got %v", quota.Spec.Hard)
+	}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: "vision", Name: NetworkPolicyName}, &networkingv1.NetworkPolicy{}); err != nil {
+		t.Errorf("expected the network policy to be created: %v", err)
+	}
+	claim := &v1.PersistentVolumeClaim{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: "vision", Name: corev1.WorkspaceClaimName}, claim); err != nil {
+		t.Fatal(err)
+	}
+
+	// 关闭网络隔离后删除策略，缩小存储时保留原有容量
+	disabled := false
+	current.Spec.NetworkIsolation = &disabled
+	current.Spec.Storage.Size = resource.MustParse("10Gi")
+	if err := r.Update(context.TODO(), current); err != nil {
+		t.Fatal(err)
+	}
+	reconcile(t, r, current)
+	err := r.Get(context.TODO(), types.NamespacedName{Namespace: "vision", Name: NetworkPolicyName}, &networkingv1.NetworkPolicy{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected the network policy to be deleted, got %v", err)
+	}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: "vision", Name: corev1.WorkspaceClaimName}, claim); err != nil {
+		t.Fatal(err)
+	}
+	if size := claim.Spec.Resources.Requests[v1.ResourceStorage]; size.String() != "100Gi" {
+		t.Errorf("expected the workspace not to shrink, got %s", size.String())
+	}
+}
+
+func TestReconcileNamespaceConflict(t *testing.T) {
+	namespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "vision",
+		Labels: map[string]string{corev1.ProjectLabelKey: "speech"},
+	}}
+	r := newTestReconciler(newProject(), namespace)
+	current := reconcile(t, r, newProject())
+
+	ready := meta.FindStatusCondition(current.Status.Conditions, corev1.ProjectReady)
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != ReasonNamespaceConflict {
+		t.Fatalf("expected a namespace conflict, got %+v", ready)
+	}
+	bindings := &rbacv1.RoleBindingList{}
+	if err := r.List(context.TODO(), bindings, client.InNamespace("vision")); err != nil {
+		t.Fatal(err)
+	}
+	if len(bindings.Items) != 0 {
+		t.Errorf("expected no bindings in a conflicting namespace, got %d", len(bindings.Items))
+	}
+}
+
+// TestBindResourceNames 控制器只能绑定 projectRoles 中的 ClusterRole，两处需同步修改
+func TestBindResourceNames(t *testing.T) {
+	data, err := ioutil.ReadFile("../../config/rbac/role.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	role := &rbacv1.ClusterRole{}
+	if err := yaml.Unmarshal(data, role); err != nil {
+		t.Fatal(err)
+	}
+	allowed := make(map[string]bool)
+	for _, rule := range role.Rules {
+		for _, resource := range rule.Resources {
+			if resource != "clusterroles" {
+				continue
+			}
+			if len(rule.ResourceNames) == 0 {
+				t.Fatalf("expected bind on clusterroles to be restricted by resourceNames: %+v", rule)
+			}
+			for _, name := range rule.ResourceNames {
+				allowed[name] = true
+			}
+		}
+	}
+	for _, role := range []corev1.ProjectRole{corev1.ProjectOwner, corev1.ProjectMember, corev1.ProjectViewer} {
+		for _, clusterRole := range projectRoles(role) {
+			if !allowed[clusterRole] {
+				t.Errorf("cluster role %s is not bindable by the controller", clusterRole)
+			}
+		}
+	}
+}
+
+func TestReconcileExistingNamespace(t *testing.T) {
+	namespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "vision"}}
+	r := newTestReconciler(newProject(), namespace)
+	current := reconcile(t, r, newProject())
+	ready := meta.FindStatusCondition(current.Status.Conditions, corev1.ProjectReady)
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != ReasonNamespaceConflict {
+		t.Fatalf("expected an existing namespace to be refused, got %+v", ready)
+	}
+
+	// 管理员允许后使用该命名空间，但不接管其生命周期
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "vision"}, namespace); err != nil {
+		t.Fatal(err)
+	}
+	namespace.Annotations = map[string]string{corev1.ProjectAdoptAnnotation: "vision"}
+	if err := r.Update(context.TODO(), namespace); err != nil {
+		t.Fatal(err)
+	}
+	current = reconcile(t, r, current)
+	ready = meta.FindStatusCondition(current.Status.Conditions, corev1.ProjectReady)
+	if ready == nil || ready.Status != metav1.ConditionTrue {
+		t.Fatalf("expected an adopted namespace to be used, got %+v", ready)
+	}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "vision"}, namespace); err != nil {
+		t.Fatal(err)
+	}
+	if namespace.Labels[corev1.ProjectLabelKey] != "vision" || metav1.IsControlledBy(namespace, current) {
+		t.Errorf("expected the namespace to be labeled but not owned, got %+v", namespace.ObjectMeta)
+	}
+	binding := &rbacv1.RoleBinding{}
+	name := roleBindingName(corev1.ProjectOwner, AdminRole)
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: "vision", Name: name}, binding); err != nil {
+		t.Fatal(err)
+	}
+	if !metav1.IsControlledBy(binding, current) {
+		t.Errorf("expected the binding to be removed with the project")
+	}
+}
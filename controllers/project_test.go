@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+)
+
+var _ = Describe("Project controller", func() {
+	ctx := context.Background()
+
+	It("creates the Project namespace and its resources", func() {
+		project := &corev1.Project{
+			ObjectMeta: metav1.ObjectMeta{Name: "vision"},
+			Spec: corev1.ProjectSpec{
+				Owners: []rbacv1.Subject{{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, project)).To(Succeed())
+
+		Eventually(func() (bool, error) {
+			current := &corev1.Project{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: project.Name}, current); err != nil {
+				return false, err
+			}
+			return meta.IsStatusConditionTrue(current.Status.Conditions, corev1.ProjectReady), nil
+		}, timeout, interval).Should(BeTrue())
+
+		namespace := &v1.Namespace{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "vision"}, namespace)).To(Succeed())
+		owner := metav1.GetControllerOf(namespace)
+		Expect(owner).NotTo(BeNil())
+		Expect(owner.UID).To(Equal(project.UID))
+		Expect(namespace.Labels).To(HaveKeyWithValue(corev1.ProjectLabelKey, project.Name))
+
+		bindings := &rbacv1.RoleBindingList{}
+		Expect(k8sClient.List(ctx, bindings, client.InNamespace("vision"))).To(Succeed())
+		Expect(bindings.Items).NotTo(BeEmpty())
+		quotas := &v1.ResourceQuotaList{}
+		Expect(k8sClient.List(ctx, quotas, client.InNamespace("vision"))).To(Succeed())
+		Expect(quotas.Items).To(HaveLen(1))
+	})
+})
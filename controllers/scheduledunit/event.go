@@ -0,0 +1,11 @@
+package scheduledunit
+
+const (
+	ReasonUnitCreated      = "UnitCreated"
+	ReasonUnitCreateFailed = "UnitCreateFailed"
+	ReasonUnitReplaced     = "UnitReplaced"
+	ReasonUnitDeleteFailed = "UnitDeleteFailed"
+	ReasonInvalidSchedule  = "InvalidSchedule"
+	ReasonTooManyMissed    = "TooManyMissedTimes"
+	ReasonUpdateFailed     = "UpdateFailed"
+)
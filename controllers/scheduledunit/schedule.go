@@ -0,0 +1,72 @@
+package scheduledunit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+)
+
+// MaxMissedTimes 错过的调度次数超过该值时不再补建，需设置 StartingDeadlineSeconds
+const MaxMissedTimes = 100
+
+// parseSchedule 解析 cron 表达式与时区，时区为空时使用 UTC
+func parseSchedule(su *corev1.ScheduledUnit) (cron.Schedule, *time.Location, error) {
+	location := time.UTC
+	if su.Spec.TimeZone != "" {
+		var err error
+		if location, err = time.LoadLocation(su.Spec.TimeZone); err != nil {
+			return nil, nil, fmt.Errorf("invalid time zone %q: %w", su.Spec.TimeZone, err)
+		}
+	}
+	schedule, err := cron.ParseStandard(su.Spec.Schedule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid schedule %q: %w", su.Spec.Schedule, err)
+	}
+	return schedule, location, nil
+}
+
+// nextTimes 返回 now 之前最近一次未执行的调度时间与 now 之后的下一次调度时间
+//
+// 从上一次调度时间开始计算，从未调度过时从创建时间开始；设置了 StartingDeadlineSeconds 时
+// 早于 now - StartingDeadlineSeconds 的调度时间视为已错过。没有未执行的调度时 missed 为零值。
+func nextTimes(su *corev1.ScheduledUnit, schedule cron.Schedule, location *time.Location, now time.Time) (missed, next time.Time, err error) {
+	earliest := su.CreationTimestamp.Time
+	if su.Status.LastScheduleTime != nil {
+		earliest = su.Status.LastScheduleTime.Time
+	}
+	if deadline := su.Spec.StartingDeadlineSeconds; deadline != nil {
+		if start := now.Add(-time.Duration(*deadline) * time.Second); start.After(earliest) {
+			earliest = start
+		}
+	}
+
+	count := 0
+	for t := schedule.Next(earliest.In(location)); ; t = schedule.Next(t) {
+		if t.After(now) {
+			return missed, t, nil
+		}
+		missed = t
+		if count++; count > MaxMissedTimes {
+			return time.Time{}, schedule.Next(now.In(location)),
+				fmt.Errorf("more than %d missed start times, set startingDeadlineSeconds or check clock skew", MaxMissedTimes)
+		}
+	}
+}
+
+// unitName 由调度时间生成的 Unit 名称，同一时间重复同步时名称相同，不会重复创建
+func unitName(su *corev1.ScheduledUnit, scheduled time.Time) string {
+	return su.Name + "-" + scheduled.Format("200601021504")
+}
+
+// scheduledTime 读取 Unit 的调度时间注解，没有或无法解析时返回 false
+func scheduledTime(unit *corev1.Unit) (time.Time, bool) {
+	value, ok := unit.Annotations[corev1.ScheduledTimeAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, err == nil
+}
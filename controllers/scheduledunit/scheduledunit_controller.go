@@ -0,0 +1,299 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduledunit
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/cokeos/zero/controllers/events"
+	"github.com/cokeos/zero/controllers/logging"
+	"github.com/cokeos/zero/controllers/metrics"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/controllers/config"
+)
+
+const (
+	DefaultSuccessfulUnitsHistoryLimit = configv1alpha1.DefaultSuccessfulUnitsHistoryLimit
+	DefaultFailedUnitsHistoryLimit     = configv1alpha1.DefaultFailedUnitsHistoryLimit
+)
+
+// ScheduledUnitReconciler reconciles a ScheduledUnit object
+type ScheduledUnitReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Clock    clock.Clock
+	// Config 当前生效的配置，为空时使用默认配置
+	Config *config.Store
+}
+
+// children 按状态分类的 Unit，每类按调度时间从早到晚排序
+type children struct {
+	active    []*corev1.Unit
+	succeeded []*corev1.Unit
+	failed    []*corev1.Unit
+}
+
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=scheduledunits,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=scheduledunits/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=scheduledunits/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=units,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.10.0/pkg/reconcile
+func (r *ScheduledUnitReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, log := logging.Reconcile(ctx, "ScheduledUnit")
+	su := &corev1.ScheduledUnit{}
+
+	// 创建的 Unit 通过 OwnerReference 随 ScheduledUnit 一起回收
+	if err := r.Get(ctx, req.NamespacedName, su); err != nil {
+		if !apierrors.IsNotFound(err) {
+			metrics.LogError(log, "scheduledunit", err, "Failed to get ScheduledUnit")
+		}
+		return ctrl.Result{}, nil
+	}
+	ctx, log = logging.WithObject(ctx, su)
+	if su.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	units, err := r.listChildren(ctx, su)
+	if err != nil {
+		metrics.LogError(log, "scheduledunit", err, "Failed to list Units")
+		return ctrl.Result{}, err
+	}
+	status := su.Status.DeepCopy()
+	syncStatus(status, units)
+	limits := r.Config.Get().ScheduledUnit
+	r.cleanup(ctx, units.succeeded, historyLimit(su.Spec.SuccessfulUnitsHistoryLimit, *limits.SuccessfulUnitsHistoryLimit))
+	r.cleanup(ctx, units.failed, historyLimit(su.Spec.FailedUnitsHistoryLimit, *limits.FailedUnitsHistoryLimit))
+
+	result, err := r.schedule(ctx, su, status, units.active)
+	if !equality.Semantic.DeepEqual(status, &su.Status) {
+		su.Status = *status
+		if updateErr := r.Status().Update(ctx, su); updateErr != nil {
+			return ctrl.Result{}, events.Warning(ctx, r.Recorder, "scheduledunit", su, ReasonUpdateFailed, updateErr)
+		}
+	}
+	return result, err
+}
+
+// schedule 在到达调度时间时按并发策略创建 Unit，返回到下一次调度时间的等待
+func (r *ScheduledUnitReconciler) schedule(ctx context.Context, su *corev1.ScheduledUnit, status *corev1.ScheduledUnitStatus, active []*corev1.Unit) (ctrl.Result, error) {
+	log := logging.FromContext(ctx)
+	if su.Spec.Suspend {
+		log.V(logging.Trace).Info("Suspended, skipping schedule")
+		return ctrl.Result{}, nil
+	}
+	schedule, location, err := parseSchedule(su)
+	if err != nil {
+		// 修改 spec 后会重新同步，无需重试
+		_ = events.Warning(ctx, r.Recorder, "scheduledunit", su, ReasonInvalidSchedule, err)
+		return ctrl.Result{}, nil
+	}
+
+	now := r.Clock.Now()
+	missed, next, err := nextTimes(su, schedule, location, now)
+	if err != nil {
+		_ = events.Warning(ctx, r.Recorder, "scheduledunit", su, ReasonTooManyMissed, err)
+	}
+	result := ctrl.Result{RequeueAfter: next.Sub(now)}
+	if missed.IsZero() {
+		log.V(logging.Trace).Info("Waiting for the next schedule", "next", next)
+		return result, nil
+	}
+
+	switch su.Spec.ConcurrencyPolicy {
+	case corev1.ForbidConcurrent:
+		if len(active) > 0 {
+			// Unit 结束后会重新同步，期限内仍可补建
+			log.V(logging.Debug).Info("Units still active, skipping schedule", "scheduledTime", missed, "active", len(active))
+			return result, nil
+		}
+	case corev1.ReplaceConcurrent:
+		for _, unit := range active {
+			if err := r.Delete(ctx, unit, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, events.Warning(ctx, r.Recorder, "scheduledunit", su, ReasonUnitDeleteFailed, err)
+			}
+			r.Recorder.Eventf(su, v1.EventTypeNormal, ReasonUnitReplaced, "Deleted active Unit %s", unit.Name)
+			log.V(logging.Debug).Info("Deleted active Unit", "unit", unit.Name)
+		}
+		status.Active = nil
+	}
+
+	unit, err := r.generateUnit(su, missed)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.Create(ctx, unit); err != nil && !apierrors.IsAlreadyExists(err) {
+		return ctrl.Result{}, events.Warning(ctx, r.Recorder, "scheduledunit", su, ReasonUnitCreateFailed, err)
+	} else if err == nil {
+		r.Recorder.Eventf(su, v1.EventTypeNormal, ReasonUnitCreated, "Created Unit %s", unit.Name)
+		log.V(logging.Debug).Info("Created Unit", "unit", unit.Name, "scheduledTime", missed)
+		status.Active = appendName(status.Active, unit.Name)
+	}
+	scheduled := metav1.NewTime(missed)
+	status.LastScheduleTime = &scheduled
+	return result, nil
+}
+
+// generateUnit 由模板生成调度时间为 scheduled 的 Unit，创建者注解与 ScheduledUnit 相同
+func (r *ScheduledUnitReconciler) generateUnit(su *corev1.ScheduledUnit, scheduled time.Time) (*corev1.Unit, error) {
+	template := su.Spec.UnitTemplate.DeepCopy()
+	unit := &corev1.Unit{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   su.Namespace,
+			Name:        unitName(su, scheduled),
+			Labels:      template.Labels,
+			Annotations: template.Annotations,
+		},
+		Spec: template.Spec,
+	}
+	if unit.Labels == nil {
+		unit.Labels = map[string]string{}
+	}
+	unit.Labels[corev1.ScheduledUnitLabel] = su.Name
+	if unit.Annotations == nil {
+		unit.Annotations = map[string]string{}
+	}
+	unit.Annotations[corev1.ScheduledTimeAnnotation] = scheduled.UTC().Format(time.RFC3339)
+	if creator, ok := su.Annotations[corev1.CreatedByAnnotation]; ok {
+		unit.Annotations[corev1.CreatedByAnnotation] = creator
+	}
+	if err := controllerutil.SetControllerReference(su, unit, r.Scheme); err != nil {
+		return nil, err
+	}
+	return unit, nil
+}
+
+// listChildren 列出 ScheduledUnit 创建的 Unit，Pod 结束后的 Unit 视为成功或失败，其余视为运行中
+func (r *ScheduledUnitReconciler) listChildren(ctx context.Context, su *corev1.ScheduledUnit) (*children, error) {
+	list := &corev1.UnitList{}
+	if err := r.List(ctx, list, client.InNamespace(su.Namespace),
+		client.MatchingLabels{corev1.ScheduledUnitLabel: su.Name}); err != nil {
+		return nil, err
+	}
+	units := &children{}
+	for i := range list.Items {
+		unit := &list.Items[i]
+		if !metav1.IsControlledBy(unit, su) || unit.DeletionTimestamp != nil {
+			continue
+		}
+		switch unit.Status.Phase {
+		case v1.PodSucceeded:
+			units.succeeded = append(units.succeeded, unit)
+		case v1.PodFailed:
+			units.failed = append(units.failed, unit)
+		default:
+			units.active = append(units.active, unit)
+		}
+	}
+	for _, group := range [][]*corev1.Unit{units.active, units.succeeded, units.failed} {
+		sortBySchedule(group)
+	}
+	return units, nil
+}
+
+// cleanup 删除超过 limit 个的最早的 Unit
+func (r *ScheduledUnitReconciler) cleanup(ctx context.Context, units []*corev1.Unit, limit int32) {
+	log := logging.FromContext(ctx)
+	for i := 0; i < len(units)-int(limit); i++ {
+		if err := r.Delete(ctx, units[i], client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			metrics.LogError(log, "scheduledunit", err, "Failed to delete old Unit", "unit", units[i].Name)
+			continue
+		}
+		log.V(logging.Debug).Info("Deleted old Unit", "unit", units[i].Name, "phase", units[i].Status.Phase)
+	}
+}
+
+// syncStatus 根据现有的 Unit 更新运行中的 Unit 与最近的调度、成功时间
+func syncStatus(status *corev1.ScheduledUnitStatus, units *children) {
+	status.Active = nil
+	for _, unit := range units.active {
+		status.Active = append(status.Active, unit.Name)
+	}
+	for _, unit := range units.succeeded {
+		if t, ok := scheduledTime(unit); ok && (status.LastSuccessfulTime == nil || t.After(status.LastSuccessfulTime.Time)) {
+			successful := metav1.NewTime(t)
+			status.LastSuccessfulTime = &successful
+		}
+	}
+	for _, group := range [][]*corev1.Unit{units.active, units.succeeded, units.failed} {
+		for _, unit := range group {
+			if t, ok := scheduledTime(unit); ok && (status.LastScheduleTime == nil || t.After(status.LastScheduleTime.Time)) {
+				scheduled := metav1.NewTime(t)
+				status.LastScheduleTime = &scheduled
+			}
+		}
+	}
+}
+
+func sortBySchedule(units []*corev1.Unit) {
+	sort.SliceStable(units, func(i, j int) bool {
+		ti, _ := scheduledTime(units[i])
+		tj, _ := scheduledTime(units[j])
+		if ti.Equal(tj) {
+			return units[i].Name < units[j].Name
+		}
+		return ti.Before(tj)
+	})
+}
+
+func appendName(names []string, name string) []string {
+	for _, n := range names {
+		if n == name {
+			return names
+		}
+	}
+	return append(names, name)
+}
+
+func historyLimit(limit *int32, defaultLimit int32) int32 {
+	if limit == nil {
+		return defaultLimit
+	}
+	return *limit
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ScheduledUnitReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Clock == nil {
+		r.Clock = clock.RealClock{}
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.ScheduledUnit{}).
+		Owns(&corev1.Unit{}).
+		Complete(r)
+}
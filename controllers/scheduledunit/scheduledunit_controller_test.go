@@ -0,0 +1,190 @@
+package scheduledunit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/internal/zerotest"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var req = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "alice", Name: "nightly"}}
+
+func newTestReconciler(now time.Time, objs ...client.Object) (*ScheduledUnitReconciler, *clock.FakeClock) {
+	fakeClock := clock.NewFakeClock(now)
+	return &ScheduledUnitReconciler{
+		Client:   zerotest.NewClient(objs...),
+		Scheme:   zerotest.Scheme,
+		Recorder: record.NewFakeRecorder(10),
+		Clock:    fakeClock,
+	}, fakeClock
+}
+
+// newScheduledUnit 每天上海时间 02:00 运行，创建于 2021-10-18 12:00 UTC
+func newScheduledUnit(policy corev1.ConcurrencyPolicy) *corev1.ScheduledUnit {
+	return &corev1.ScheduledUnit{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "alice",
+			Name:              "nightly",
+			UID:               "nightly-uid",
+			CreationTimestamp: metav1.Date(2021, 10, 18, 12, 0, 0, 0, time.UTC),
+			Annotations:       map[string]string{corev1.CreatedByAnnotation: "alice"},
+		},
+		Spec: corev1.ScheduledUnitSpec{
+			Schedule:          "0 2 * * *",
+			TimeZone:          "Asia/Shanghai",
+			ConcurrencyPolicy: policy,
+			UnitTemplate: corev1.UnitTemplateSpec{
+				Labels: map[string]string{"app": "train"},
+				Spec:   corev1.UnitSpec{Image: "pytorch"},
+			},
+		},
+	}
+}
+
+// newChild 调度时间为 scheduled、Pod 阶段为 phase 的 Unit，名称使用 ScheduledUnit 的时区
+func newChild(su *corev1.ScheduledUnit, scheduled time.Time, phase v1.PodPhase) *corev1.Unit {
+	controller := true
+	if location, err := time.LoadLocation(su.Spec.TimeZone); err == nil {
+		scheduled = scheduled.In(location)
+	}
+	return &corev1.Unit{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       su.Namespace,
+			Name:            unitName(su, scheduled),
+			Labels:          map[string]string{corev1.ScheduledUnitLabel: su.Name},
+			Annotations:     map[string]string{corev1.ScheduledTimeAnnotation: scheduled.UTC().Format(time.RFC3339)},
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: corev1.GroupVersion.String(), Kind: "ScheduledUnit", Name: su.Name, UID: su.UID, Controller: &controller}},
+		},
+		Status: corev1.UnitStatus{Phase: phase},
+	}
+}
+
+func listUnits(t *testing.T, r *ScheduledUnitReconciler) map[string]corev1.Unit {
+	list := &corev1.UnitList{}
+	if err := r.List(context.TODO(), list); err != nil {
+		t.Fatal(err)
+	}
+	units := map[string]corev1.Unit{}
+	for _, unit := range list.Items {
+		units[unit.Name] = unit
+	}
+	return units
+}
+
+func TestReconcileCreatesUnitAtScheduledTime(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip(err)
+	}
+	su := newScheduledUnit(corev1.AllowConcurrent)
+	// 上海时间 2021-10-19 01:59，距离调度还有一分钟
+	r, fakeClock := newTestReconciler(time.Date(2021, 10, 18, 17, 59, 0, 0, time.UTC), su)
+
+	result, err := r.Reconcile(context.TODO(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listUnits(t, r)) != 0 || result.RequeueAfter != time.Minute {
+		t.Fatalf("expected to wait a minute, got %v", result.RequeueAfter)
+	}
+
+	fakeClock.Step(time.Minute)
+	result, err = r.Reconcile(context.TODO(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RequeueAfter != 24*time.Hour {
+		t.Errorf("expected to requeue after a day, got %v", result.RequeueAfter)
+	}
+	unit, ok := listUnits(t, r)["nightly-202110190200"]
+	if !ok {
+		t.Fatalf("expected Unit nightly-202110190200, got %v", listUnits(t, r))
+	}
+	if unit.Spec.Image != "pytorch" || unit.Labels["app"] != "train" || unit.Labels[corev1.ScheduledUnitLabel] != "nightly" ||
+		unit.Annotations[corev1.CreatedByAnnotation] != "alice" || !metav1.IsControlledBy(&unit, su) {
+		t.Errorf("unexpected Unit %+v", unit.ObjectMeta)
+	}
+	if err := r.Get(context.TODO(), req.NamespacedName, su); err != nil {
+		t.Fatal(err)
+	}
+	if !su.Status.LastScheduleTime.Time.Equal(time.Date(2021, 10, 19, 2, 0, 0, 0, shanghai)) ||
+		len(su.Status.Active) != 1 || su.Status.Active[0] != unit.Name {
+		t.Errorf("unexpected status %+v", su.Status)
+	}
+
+	// 再次同步不会重复创建
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	if units := listUnits(t, r); len(units) != 1 {
+		t.Errorf("expected one Unit, got %d", len(units))
+	}
+}
+
+func TestReconcileConcurrencyPolicy(t *testing.T) {
+	last := time.Date(2021, 10, 18, 18, 0, 0, 0, time.UTC)
+	now := last.Add(24 * time.Hour)
+	for policy, expected := range map[corev1.ConcurrencyPolicy][]string{
+		corev1.AllowConcurrent:   {"nightly-202110190200", "nightly-202110200200"},
+		corev1.ForbidConcurrent:  {"nightly-202110190200"},
+		corev1.ReplaceConcurrent: {"nightly-202110200200"},
+	} {
+		su := newScheduledUnit(policy)
+		su.Status.LastScheduleTime = &metav1.Time{Time: last}
+		r, _ := newTestReconciler(now, su, newChild(su, last, v1.PodRunning))
+		if _, err := r.Reconcile(context.TODO(), req); err != nil {
+			t.Fatal(err)
+		}
+		units := listUnits(t, r)
+		if len(units) != len(expected) {
+			t.Errorf("%s: expected %v, got %d Units", policy, expected, len(units))
+		}
+		for _, name := range expected {
+			if _, ok := units[name]; !ok {
+				t.Errorf("%s: expected Unit %s", policy, name)
+			}
+		}
+	}
+}
+
+func TestReconcileCleansUpHistory(t *testing.T) {
+	su := newScheduledUnit(corev1.AllowConcurrent)
+	su.Spec.Suspend = true
+	one := int32(1)
+	su.Spec.SuccessfulUnitsHistoryLimit = &one
+	start := time.Date(2021, 10, 10, 18, 0, 0, 0, time.UTC)
+	objs := []client.Object{su}
+	for i := 0; i < 3; i++ {
+		objs = append(objs, newChild(su, start.Add(time.Duration(i)*24*time.Hour), v1.PodSucceeded))
+	}
+	objs = append(objs, newChild(su, start.Add(3*24*time.Hour), v1.PodFailed))
+	r, _ := newTestReconciler(start.Add(4*24*time.Hour), objs...)
+
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	units := listUnits(t, r)
+	if len(units) != 2 {
+		t.Errorf("expected the latest succeeded and failed Units, got %v", units)
+	}
+	for _, name := range []string{"nightly-202110130200", "nightly-202110140200"} {
+		if _, ok := units[name]; !ok {
+			t.Errorf("expected Unit %s to be kept", name)
+		}
+	}
+	if err := r.Get(context.TODO(), req.NamespacedName, su); err != nil {
+		t.Fatal(err)
+	}
+	if !su.Status.LastSuccessfulTime.Time.Equal(start.Add(2 * 24 * time.Hour)) {
+		t.Errorf("unexpected last successful time %v", su.Status.LastSuccessfulTime)
+	}
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+)
+
+const (
+	timeout  = time.Second * 10
+	interval = time.Millisecond * 250
+)
+
+func newScheduledUnit(name string, suspend bool) *corev1.ScheduledUnit {
+	return &corev1.ScheduledUnit{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: corev1.ScheduledUnitSpec{
+			Schedule:          "0 * * * *",
+			ConcurrencyPolicy: corev1.AllowConcurrent,
+			Suspend:           suspend,
+			UnitTemplate: corev1.UnitTemplateSpec{
+				Spec: corev1.UnitSpec{
+					Image:        "pytorch",
+					Framework:    corev1.Framework{Name: "pytorch", Version: "1.9"},
+					ResourceList: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+				},
+			},
+		},
+	}
+}
+
+func scheduledUnits(ctx context.Context, su *corev1.ScheduledUnit) func() ([]corev1.Unit, error) {
+	return func() ([]corev1.Unit, error) {
+		units := &corev1.UnitList{}
+		err := k8sClient.List(ctx, units, client.InNamespace(su.Namespace), client.MatchingLabels{corev1.ScheduledUnitLabel: su.Name})
+		return units.Items, err
+	}
+}
+
+var _ = Describe("ScheduledUnit controller", func() {
+	ctx := context.Background()
+
+	It("creates a Unit for the missed schedule", func() {
+		su := newScheduledUnit("hourly", false)
+		Expect(k8sClient.Create(ctx, su)).To(Succeed())
+
+		Eventually(scheduledUnits(ctx, su), timeout, interval).Should(HaveLen(1))
+		units, err := scheduledUnits(ctx, su)()
+		Expect(err).NotTo(HaveOccurred())
+		owner := metav1.GetControllerOf(&units[0])
+		Expect(owner).NotTo(BeNil())
+		Expect(owner.UID).To(Equal(su.UID))
+		Expect(units[0].Spec.Image).To(Equal("pytorch"))
+
+		Eventually(func() (*metav1.Time, error) {
+			current := &corev1.ScheduledUnit{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Namespace: su.Namespace, Name: su.Name}, current)
+			return current.Status.LastScheduleTime, err
+		}, timeout, interval).ShouldNot(BeNil())
+	})
+
+	It("does not create Units while suspended", func() {
+		su := newScheduledUnit("suspended", true)
+		Expect(k8sClient.Create(ctx, su)).To(Succeed())
+
+		Consistently(scheduledUnits(ctx, su), time.Second*2, interval).Should(BeEmpty())
+	})
+})
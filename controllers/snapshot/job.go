@@ -0,0 +1,231 @@
+package snapshot
+
+import (
+	"path"
+	"strings"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	corev1 "github.com/cokeos/zero/api/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// LabelValue 快照对象上 LabelKey 标签的取值，标签键来自配置文件
+	LabelValue = "true"
+
+	SnapshotLabelKey = "cokeos.io/zero-snapshot"
+
+	DefaultRegistry      = configv1alpha1.DefaultRegistry
+	DefaultBuilderImage  = configv1alpha1.DefaultSnapshotBuilderImage
+	DefaultExporterImage = configv1alpha1.DefaultSnapshotExporterImage
+
+	// DefaultServiceAccountName 构建任务使用的 ServiceAccount 的名称前缀，每个 Snapshot 一个，只能 exec 被提交的 Pod
+	DefaultServiceAccountName = "zero-snapshot"
+
+	WorkspaceMountPath = "/workspace"
+	DockerConfigPath   = "/kaniko/.docker"
+
+	// exportExcludes 提交整个文件系统时排除的路径，工作目录与共享内存由卷提供
+	exportExcludes = "--exclude=./proc --exclude=./sys --exclude=./dev --exclude=./run --exclude=./tmp " +
+		"--exclude=./data --exclude=./var/run/secrets"
+
+	// exportScript 从 Unit 容器导出文件并生成 Dockerfile，参数均通过环境变量传入
+	exportScript = `set -e
+if [ "$TARGET" = "." ]; then
+  kubectl exec -n "$NAMESPACE" "$POD" -c "$CONTAINER" -- tar -C / -cf - ` + exportExcludes + ` . > ` + WorkspaceMountPath + `/rootfs.tar
+else
+  kubectl exec -n "$NAMESPACE" "$POD" -c "$CONTAINER" -- tar -C / -cf - "$TARGET" > ` + WorkspaceMountPath + `/rootfs.tar
+fi
+printf 'FROM %s\nADD rootfs.tar /\n' "$BASE_IMAGE" > ` + WorkspaceMountPath + `/Dockerfile
+`
+)
+
+// targetImage 快照的目标镜像
+func targetImage(snapshot *corev1.Snapshot, registry string) string {
+	if snapshot.Spec.Image != "" {
+		return snapshot.Spec.Image
+	}
+	return registry + "/" + snapshot.Namespace + "/" + snapshot.Spec.UnitName + ":" + snapshot.Name
+}
+
+// exportTarget tar 的提交路径，相对于根目录
+func exportTarget(snapshot *corev1.Snapshot) string {
+	p := strings.TrimPrefix(path.Clean("/"+snapshot.Spec.Path), "/")
+	if p == "" {
+		return "."
+	}
+	return "./" + p
+}
+
+// snapshotLabels 快照任务及其 ServiceAccount、Role 上的标签
+func snapshotLabels(snapshot *corev1.Snapshot, labelKey string) map[string]string {
+	return map[string]string{
+		labelKey:         LabelValue,
+		SnapshotLabelKey: snapshot.Name,
+	}
+}
+
+func generateJob(snapshot *corev1.Snapshot, pod *v1.Pod, snapshotConfig configv1alpha1.SnapshotConfig, serviceAccount string) *batchv1.Job {
+	var backoffLimit int32 = 0
+
+	volumes := []v1.Volume{
+		{
+			Name: "workspace",
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+	builderMounts := []v1.VolumeMount{
+		{
+			Name:      "workspace",
+			MountPath: WorkspaceMountPath,
+		},
+	}
+	if snapshot.Spec.PushSecretName != "" {
+		volumes = append(volumes, v1.Volume{
+			Name: "docker-config",
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName: snapshot.Spec.PushSecretName,
+					Items: []v1.KeyToPath{
+						{
+							Key:  v1.DockerConfigJsonKey,
+							Path: "config.json",
+						},
+					},
+				},
+			},
+		})
+		builderMounts = append(builderMounts, v1.VolumeMount{
+			Name:      "docker-config",
+			MountPath: DockerConfigPath,
+		})
+	}
+
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: snapshot.Namespace,
+			Name:      snapshot.Name,
+			Labels:    snapshotLabels(snapshot, snapshotConfig.LabelKey),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: snapshotLabels(snapshot, snapshotConfig.LabelKey),
+				},
+				Spec: v1.PodSpec{
+					RestartPolicy:      v1.RestartPolicyNever,
+					ServiceAccountName: serviceAccount,
+					// 与 Unit 调度到同一节点，避免跨节点传输整个文件系统
+					NodeName: pod.Spec.NodeName,
+					InitContainers: []v1.Container{
+						{
+							Name:    "export",
+							Image:   snapshotConfig.ExporterImage,
+							Command: []string{"sh", "-c", exportScript},
+							Env: []v1.EnvVar{
+								{Name: "NAMESPACE", Value: pod.Namespace},
+								{Name: "POD", Value: pod.Name},
+								{Name: "CONTAINER", Value: pod.Spec.Containers[0].Name},
+								{Name: "TARGET", Value: exportTarget(snapshot)},
+								{Name: "BASE_IMAGE", Value: pod.Spec.Containers[0].Image},
+							},
+							VolumeMounts: []v1.VolumeMount{
+								{
+									Name:      "workspace",
+									MountPath: WorkspaceMountPath,
+								},
+							},
+						},
+					},
+					Containers: []v1.Container{
+						{
+							Name:  "build",
+							Image: snapshotConfig.BuilderImage,
+							Args: []string{
+								"--dockerfile=" + WorkspaceMountPath + "/Dockerfile",
+								"--context=dir://" + WorkspaceMountPath,
+								"--destination=" + targetImage(snapshot, snapshotConfig.Registry),
+								// digest 写入终止消息，由控制器读取
+								"--digest-file=/dev/termination-log",
+							},
+							VolumeMounts: builderMounts,
+						},
+					},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+}
+
+// serviceAccountName 每个 Snapshot 独立的 ServiceAccount，权限只覆盖其目标 Pod
+func serviceAccountName(snapshot *corev1.Snapshot, prefix string) string {
+	return prefix + "-" + snapshot.Name
+}
+
+func generateServiceAccount(snapshot *corev1.Snapshot, name, labelKey string) *v1.ServiceAccount {
+	return &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: snapshot.Namespace,
+			Name:      name,
+			Labels:    snapshotLabels(snapshot, labelKey),
+		},
+	}
+}
+
+// generateRole 只允许读取并 exec 被提交的 Pod
+func generateRole(snapshot *corev1.Snapshot, pod *v1.Pod, name, labelKey string) *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: snapshot.Namespace,
+			Name:      name,
+			Labels:    snapshotLabels(snapshot, labelKey),
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"pods"},
+				ResourceNames: []string{pod.Name},
+				Verbs:         []string{"get"},
+			},
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"pods/exec"},
+				ResourceNames: []string{pod.Name},
+				Verbs:         []string{"create"},
+			},
+		},
+	}
+}
+
+func generateRoleBinding(snapshot *corev1.Snapshot, name, labelKey string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: snapshot.Namespace,
+			Name:      name,
+			Labels:    snapshotLabels(snapshot, labelKey),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     name,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Namespace: snapshot.Namespace,
+				Name:      name,
+			},
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package snapshot
+
+import (
+	"testing"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newSnapshot() (*corev1.Snapshot, *v1.Pod) {
+	snapshot := &corev1.Snapshot{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "v1"},
+		Spec:       corev1.SnapshotSpec{UnitName: "train", Path: "/opt/conda/../conda", PushSecretName: "registry"},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"},
+		Spec: v1.PodSpec{
+			NodeName:   "gpu-1",
+			Containers: []v1.Container{{Name: "train", Image: "pytorch:1.9"}},
+		},
+	}
+	return snapshot, pod
+}
+
+func TestGenerateJob(t *testing.T) {
+	snapshot, pod := newSnapshot()
+	snapshotConfig := configv1alpha1.NewDefault().Snapshot
+	snapshotConfig.Registry = "registry.local"
+	job := generateJob(snapshot, pod, snapshotConfig, "zero-snapshot-v1")
+	spec := job.Spec.Template.Spec
+	if spec.NodeName != "gpu-1" || spec.ServiceAccountName != "zero-snapshot-v1" {
+		t.Errorf("unexpected node %q or service account %q", spec.NodeName, spec.ServiceAccountName)
+	}
+	env := map[string]string{}
+	for _, e := range spec.InitContainers[0].Env {
+		env[e.Name] = e.Value
+	}
+	for name, value := range map[string]string{"POD": "train", "CONTAINER": "train", "TARGET": "./opt/conda", "BASE_IMAGE": "pytorch:1.9"} {
+		if env[name] != value {
+			t.Errorf("expected %s=%s, got %q", name, value, env[name])
+		}
+	}
+	build := spec.Containers[0]
+	if build.Args[2] != "--destination=registry.local/alice/train:v1" {
+		t.Errorf("unexpected destination %s", build.Args[2])
+	}
+	if len(build.VolumeMounts) != 2 || build.VolumeMounts[1].MountPath != DockerConfigPath {
+		t.Errorf("expected the push secret to be mounted, got %+v", build.VolumeMounts)
+	}
+}
+
+func TestGenerateRole(t *testing.T) {
+	snapshot, pod := newSnapshot()
+	role := generateRole(snapshot, pod, serviceAccountName(snapshot, DefaultServiceAccountName), configv1alpha1.DefaultLabelKey)
+	if role.Name != "zero-snapshot-v1" || len(role.Rules) != 2 {
+		t.Fatalf("unexpected Role %+v", role)
+	}
+	for _, rule := range role.Rules {
+		if len(rule.ResourceNames) != 1 || rule.ResourceNames[0] != "train" {
+			t.Errorf("expected rule %v to be limited to the target Pod", rule.Resources)
+		}
+	}
+	binding := generateRoleBinding(snapshot, role.Name, configv1alpha1.DefaultLabelKey)
+	if binding.RoleRef.Name != role.Name || binding.Subjects[0].Name != role.Name {
+		t.Errorf("unexpected RoleBinding %+v", binding)
+	}
+}
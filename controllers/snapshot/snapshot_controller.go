@@ -0,0 +1,245 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"context"
+	"github.com/cokeos/zero/controllers/config"
+	"github.com/cokeos/zero/controllers/logging"
+	"github.com/cokeos/zero/controllers/metrics"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+)
+
+const (
+	ConditionComplete = "Complete"
+)
+
+// SnapshotReconciler reconciles a Snapshot object
+type SnapshotReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// ServiceAccountName 构建任务使用的 ServiceAccount
+	ServiceAccountName string
+	// Config 当前生效的配置，为空时使用默认配置
+	Config *config.Store
+}
+
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=snapshots,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=snapshots/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=snapshots/finalizers,verbs=update
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+//+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.10.0/pkg/reconcile
+func (r *SnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, log := logging.Reconcile(ctx, "Snapshot")
+	var (
+		snapshot = &corev1.Snapshot{}
+		job      = &batchv1.Job{}
+	)
+
+	// 构建任务通过 OwnerReference 随 Snapshot 一起回收
+	if err := r.Get(ctx, req.NamespacedName, snapshot); err != nil {
+		if !apierrors.IsNotFound(err) {
+			metrics.LogError(log, "snapshot", err, "Failed to get Snapshot")
+		}
+		return ctrl.Result{}, nil
+	}
+	ctx, log = logging.WithObject(ctx, snapshot)
+	if snapshot.DeletionTimestamp != nil ||
+		snapshot.Status.Phase == corev1.SnapshotSucceeded ||
+		snapshot.Status.Phase == corev1.SnapshotFailed {
+		return ctrl.Result{}, nil
+	}
+
+	jobErr := r.Get(ctx, req.NamespacedName, job)
+	if jobErr == nil {
+		return ctrl.Result{}, r.syncJob(ctx, snapshot, job)
+	}
+	if !apierrors.IsNotFound(jobErr) {
+		metrics.LogError(log, "snapshot", jobErr, "Failed to get Job")
+		return ctrl.Result{}, nil
+	}
+
+	// 创建逻辑
+	unit := &corev1.Unit{}
+	key := types.NamespacedName{Namespace: snapshot.Namespace, Name: snapshot.Spec.UnitName}
+	if err := r.Get(ctx, key, unit); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.finish(ctx, snapshot, corev1.SnapshotFailed, "UnitNotFound",
+				"unit "+key.String()+" not found")
+		}
+		metrics.LogError(log, "snapshot", err, "Failed to get Unit", "unit", key.Name)
+		return ctrl.Result{}, nil
+	}
+	pod := &v1.Pod{}
+	if err := r.Get(ctx, key, pod); err != nil || pod.Status.Phase != v1.PodRunning {
+		if err != nil && !apierrors.IsNotFound(err) {
+			metrics.LogError(log, "snapshot", err, "Failed to get Pod", "unit", key.Name)
+		}
+		log.V(logging.Trace).Info("Waiting for the Unit to run", "unit", key.Name)
+		if snapshot.Status.Phase != corev1.SnapshotPending {
+			snapshot.Status.Phase = corev1.SnapshotPending
+			if err := r.Status().Update(ctx, snapshot); err != nil {
+				metrics.LogError(log, "snapshot", err, "Failed to update Snapshot status")
+			}
+		}
+		return ctrl.Result{RequeueAfter: r.Config.Get().SyncPeriods.Snapshot.Duration}, nil
+	}
+
+	serviceAccount := serviceAccountName(snapshot, r.ServiceAccountName)
+	if err := r.ensureServiceAccount(ctx, snapshot, pod, serviceAccount); err != nil {
+		metrics.LogError(log, "snapshot", err, "Failed to ensure ServiceAccount")
+		return ctrl.Result{}, err
+	}
+	snapshotConfig := r.Config.Get().Snapshot
+	job = generateJob(snapshot, pod, snapshotConfig, serviceAccount)
+	if err := controllerutil.SetControllerReference(snapshot, job, r.Scheme); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.Create(ctx, job); err != nil {
+		metrics.LogError(log, "snapshot", err, "Failed to create Job")
+		return ctrl.Result{}, err
+	}
+	log.Info("Created Job", "job", job.Name, "unit", key.Name)
+
+	snapshot.Status.Phase = corev1.SnapshotRunning
+	snapshot.Status.JobName = job.Name
+	snapshot.Status.BaseImage = pod.Spec.Containers[0].Image
+	snapshot.Status.Image = targetImage(snapshot, snapshotConfig.Registry)
+	return ctrl.Result{}, r.Status().Update(ctx, snapshot)
+}
+
+// syncJob 根据构建任务的状态更新 Snapshot
+func (r *SnapshotReconciler) syncJob(ctx context.Context, snapshot *corev1.Snapshot, job *batchv1.Job) error {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			image := targetImage(snapshot, r.Config.Get().Snapshot.Registry)
+			digest, err := r.jobDigest(ctx, job)
+			if err != nil {
+				metrics.LogError(logging.FromContext(ctx), "snapshot", err, "Failed to get image digest", "job", job.Name)
+			}
+			if digest != "" {
+				image = image + "@" + digest
+			}
+			snapshot.Status.Image = image
+			return r.finish(ctx, snapshot, corev1.SnapshotSucceeded, "Pushed", "image pushed to "+image)
+		case batchv1.JobFailed:
+			return r.finish(ctx, snapshot, corev1.SnapshotFailed, condition.Reason, condition.Message)
+		}
+	}
+	return nil
+}
+
+// jobDigest 读取 kaniko 写入终止消息的镜像 digest
+func (r *SnapshotReconciler) jobDigest(ctx context.Context, job *batchv1.Job) (string, error) {
+	list := &v1.PodList{}
+	err := r.List(ctx, list, client.InNamespace(job.Namespace), &client.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{
+			SnapshotLabelKey: job.Name,
+		}),
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, pod := range list.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.State.Terminated
+			if terminated == nil || terminated.ExitCode != 0 {
+				continue
+			}
+			if digest := strings.TrimSpace(terminated.Message); strings.HasPrefix(digest, "sha256:") {
+				return digest, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func (r *SnapshotReconciler) finish(ctx context.Context, snapshot *corev1.Snapshot, phase corev1.SnapshotPhase, reason, message string) error {
+	status := metav1.ConditionTrue
+	if phase == corev1.SnapshotFailed {
+		status = metav1.ConditionFalse
+	}
+	if reason == "" {
+		reason = string(phase)
+	}
+	now := metav1.Now()
+	snapshot.Status.Phase = phase
+	snapshot.Status.CompletionTime = &now
+	meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+		Type:    ConditionComplete,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+	return r.Status().Update(ctx, snapshot)
+}
+
+// ensureServiceAccount 确保构建任务的 ServiceAccount 及其对目标 Pod 的 exec 权限存在，随 Snapshot 一起回收
+func (r *SnapshotReconciler) ensureServiceAccount(ctx context.Context, snapshot *corev1.Snapshot, pod *v1.Pod, name string) error {
+	labelKey := r.Config.Get().Snapshot.LabelKey
+	for _, obj := range []client.Object{
+		generateServiceAccount(snapshot, name, labelKey),
+		generateRole(snapshot, pod, name, labelKey),
+		generateRoleBinding(snapshot, name, labelKey),
+	} {
+		if err := controllerutil.SetControllerReference(snapshot, obj, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, obj); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SnapshotReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.ServiceAccountName == "" {
+		r.ServiceAccountName = DefaultServiceAccountName
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Snapshot{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
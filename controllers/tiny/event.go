@@ -0,0 +1,18 @@
+package tiny
+
+const (
+	ReasonUnitCreated        = "UnitCreated"
+	ReasonUnitCreateFailed   = "UnitCreateFailed"
+	ReasonUnitUpdateFailed   = "UnitUpdateFailed"
+	ReasonTunnelCreated      = "TunnelCreated"
+	ReasonTunnelCreateFailed = "TunnelCreateFailed"
+	ReasonTunnelUpdateFailed = "TunnelUpdateFailed"
+	ReasonPortAllocated      = "PortAllocated"
+	ReasonPortExhausted      = "PortExhausted"
+	ReasonProfileFailed      = "ProfileFailed"
+	ReasonProfileNotFound    = "ProfileNotFound"
+	ReasonHostKeyFailed      = "HostKeyFailed"
+	ReasonDeleting           = "Deleting"
+	ReasonDeleteFailed       = "DeleteFailed"
+	ReasonUpdateFailed       = "UpdateFailed"
+)
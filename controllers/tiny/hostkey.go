@@ -0,0 +1,47 @@
+package tiny
+
+import (
+	"context"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/controllers/logging"
+	sshgateway "github.com/cokeos/zero/gateway"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ensureHostKey 为 Tiny 生成 Unit 的 SSH 主机密钥，SSH 网关以其中的公钥校验 Unit
+//
+// 密钥生成后不再变化，Unit 重建后主机密钥保持不变；Secret 通过 OwnerReference 随 Tiny 一起回收。
+func (r *TinyReconciler) ensureHostKey(ctx context.Context, tiny *corev1.Tiny) error {
+	name := sshgateway.HostKeySecretName(tiny.Name)
+	err := r.Get(ctx, types.NamespacedName{Namespace: tiny.Namespace, Name: name}, &v1.Secret{})
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+	privateKey, publicKey, err := sshgateway.GenerateHostKey()
+	if err != nil {
+		return err
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: tiny.Namespace,
+			Name:      name,
+		},
+		Data: map[string][]byte{
+			sshgateway.HostKeyKey:       privateKey,
+			sshgateway.HostPublicKeyKey: publicKey,
+		},
+	}
+	if err := controllerutil.SetControllerReference(tiny, secret, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, secret); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	logging.FromContext(ctx).V(logging.Debug).Info("Created SSH host key", "secret", name)
+	return nil
+}
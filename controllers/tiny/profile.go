@@ -0,0 +1,103 @@
+package tiny
+
+import (
+	"context"
+	"fmt"
+	"github.com/cokeos/zero/controllers/events"
+	"github.com/cokeos/zero/controllers/logging"
+	"github.com/cokeos/zero/controllers/metrics"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// getProfile 返回 Tiny 使用的 TinyProfile，未指定且没有默认 Profile 时返回 nil，指定的 Profile 不存在时返回 NotFound
+func (r *TinyReconciler) getProfile(ctx context.Context, tiny *corev1.Tiny) (*corev1.TinyProfile, error) {
+	if tiny.Spec.Profile != "" {
+		profile := &corev1.TinyProfile{}
+		if err := r.Get(ctx, types.NamespacedName{Name: tiny.Spec.Profile}, profile); err != nil {
+			return nil, fmt.Errorf("get tiny profile %s: %w", tiny.Spec.Profile, err)
+		}
+		return profile, nil
+	}
+
+	list := &corev1.TinyProfileList{}
+	if err := r.List(ctx, list); err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		if list.Items[i].Annotations[corev1.DefaultTinyProfileAnnotation] == "true" {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// profileNotFound 指定的 TinyProfile 不存在时记录事件并设置 ProfileReady 与 Ready 条件，不创建 Unit 与 Tunnel
+//
+// Profile 创建后由 profileRequests 重新入队，无需重试。
+func (r *TinyReconciler) profileNotFound(ctx context.Context, tiny *corev1.Tiny) error {
+	r.Recorder.Eventf(tiny, v1.EventTypeWarning, ReasonProfileNotFound, "TinyProfile %s not found", tiny.Spec.Profile)
+	logging.FromContext(ctx).Info("TinyProfile not found", "profile", tiny.Spec.Profile)
+
+	status := tiny.Status.DeepCopy()
+	message := "tiny profile " + tiny.Spec.Profile + " not found"
+	for _, t := range []string{corev1.TinyProfileReady, corev1.TinyReady} {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               t,
+			Status:             metav1.ConditionFalse,
+			Reason:             ReasonProfileNotFound,
+			Message:            message,
+			ObservedGeneration: tiny.Generation,
+		})
+	}
+	if equality.Semantic.DeepEqual(status, &tiny.Status) {
+		return nil
+	}
+	tiny.Status = *status
+	if err := r.Status().Update(ctx, tiny); err != nil {
+		return events.Warning(ctx, r.Recorder, "tiny", tiny, ReasonUpdateFailed, err)
+	}
+	return nil
+}
+
+// profileRequests Profile 变化时，将使用该 Profile 的 Tiny 加入队列
+func (r *TinyReconciler) profileRequests(obj client.Object) []reconcile.Request {
+	profile, ok := obj.(*corev1.TinyProfile)
+	if !ok {
+		return nil
+	}
+	isDefault := profile.Annotations[corev1.DefaultTinyProfileAnnotation] == "true"
+
+	list := &corev1.TinyList{}
+	if err := r.List(context.TODO(), list); err != nil {
+		metrics.LogError(logging.Named("tiny"), "tiny", err, "Failed to list Tinies", "profile", profile.Name)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0)
+	for _, tiny := range list.Items {
+		if tiny.Spec.Profile == profile.Name || tiny.Status.Profile == profile.Name ||
+			(tiny.Spec.Profile == "" && isDefault) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: tiny.Namespace,
+				Name:      tiny.Name,
+			}})
+		}
+	}
+	return requests
+}
+
+// childRequests Unit/Tunnel 变化时，将同名 Tiny 加入队列；没有同名 Tiny 的 Unit/Tunnel 不归 Tiny 管理
+func (r *TinyReconciler) childRequests(obj client.Object) []reconcile.Request {
+	key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	if err := r.Get(context.TODO(), key, &corev1.Tiny{}); err != nil {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: key}}
+}
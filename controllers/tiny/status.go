@@ -0,0 +1,174 @@
+package tiny
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SSHUser 镜像中 SSH 登录使用的用户
+	SSHUser = "root"
+
+	ReasonPending  = "Pending"
+	ReasonRunning  = "Running"
+	ReasonReady    = "Ready"
+	ReasonMissing  = "NotFound"
+	ReasonResolved = "Resolved"
+)
+
+// setConditions 根据 Unit 与 Tunnel 的状态设置 UnitReady、TunnelReady 与 Ready 条件
+func setConditions(status *corev1.TinyStatus, generation int64,
+	unit *corev1.Unit, unitExists bool, tunnel *corev1.Tunnel, tunnelExists bool) {
+	unitReady := metav1.Condition{
+		Type:    corev1.TinyUnitReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  ReasonMissing,
+		Message: "unit not found",
+	}
+	if unitExists {
+		unitReady = unitCondition(unit)
+	}
+	tunnelReady := metav1.Condition{
+		Type:    corev1.TinyTunnelReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  ReasonMissing,
+		Message: "tunnel not found",
+	}
+	if tunnelExists {
+		tunnelReady = tunnelCondition(tunnel)
+	}
+
+	ready := metav1.Condition{
+		Type:    corev1.TinyReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  ReasonReady,
+		Message: "tiny is ready to connect",
+	}
+	for _, condition := range []metav1.Condition{unitReady, tunnelReady} {
+		if condition.Status != metav1.ConditionTrue {
+			ready.Status = condition.Status
+			ready.Reason = condition.Reason
+			ready.Message = condition.Message
+			break
+		}
+	}
+
+	for _, condition := range []metav1.Condition{unitReady, tunnelReady, ready} {
+		condition.ObservedGeneration = generation
+		meta.SetStatusCondition(&status.Conditions, condition)
+	}
+}
+
+// profileCondition 找到 TinyProfile 或未使用 Profile 时 ProfileReady 为 True
+func profileCondition(tiny *corev1.Tiny, profile *corev1.TinyProfile) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               corev1.TinyProfileReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonResolved,
+		Message:            "no tiny profile",
+		ObservedGeneration: tiny.Generation,
+	}
+	if profile != nil {
+		condition.Message = "using tiny profile " + profile.Name
+	}
+	return condition
+}
+
+func unitCondition(unit *corev1.Unit) metav1.Condition {
+	condition := metav1.Condition{
+		Type:    corev1.TinyUnitReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  ReasonPending,
+		Message: "unit is pending",
+	}
+	switch unit.Status.Phase {
+	case v1.PodRunning:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ReasonRunning
+		condition.Message = "unit is running"
+		return condition
+	case "", v1.PodPending:
+	default:
+		condition.Reason = string(unit.Status.Phase)
+		condition.Message = "unit is " + string(unit.Status.Phase)
+	}
+	// Pod 创建、调度或镜像拉取失败时给出更具体的原因
+	for _, t := range []string{corev1.ConditionPodCreated, corev1.ConditionPodScheduled, corev1.ConditionImagePulled} {
+		if c := meta.FindStatusCondition(unit.Status.Conditions, t); c != nil && c.Status == metav1.ConditionFalse {
+			condition.Reason = c.Reason
+			condition.Message = c.Message
+			break
+		}
+	}
+	// 引用缺失时给出更具体的原因
+	refs := meta.FindStatusCondition(unit.Status.Conditions, corev1.ConditionReferencesResolved)
+	if refs != nil && refs.Status == metav1.ConditionFalse {
+		condition.Reason = refs.Reason
+		condition.Message = refs.Message
+	}
+	return condition
+}
+
+func tunnelCondition(tunnel *corev1.Tunnel) metav1.Condition {
+	condition := metav1.Condition{
+		Type:    corev1.TinyTunnelReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  ReasonPending,
+		Message: "tunnel is pending",
+	}
+	if ready := meta.FindStatusCondition(tunnel.Status.Conditions, corev1.TunnelReady); ready != nil {
+		condition.Status = ready.Status
+		condition.Reason = ready.Reason
+		condition.Message = ready.Message
+	}
+	return condition
+}
+
+// connection 返回 SSH 连接的地址，优先使用 Tunnel 计算出的对外地址，否则回退到 Pod 所在节点
+func connection(unit *corev1.Unit, tunnel *corev1.Tunnel, tunnelExists bool, port int32) (host, endpoint string) {
+	if tunnelExists {
+		for _, e := range tunnel.Status.Endpoints {
+			if e.Name == SSH {
+				return e.Host, e.Address
+			}
+		}
+	}
+	host = unit.Status.HostIP
+	if host == "" {
+		host = unit.Status.NodeName
+	}
+	if host == "" || port <= 0 {
+		return "", ""
+	}
+	return host, net.JoinHostPort(host, strconv.Itoa(int(port)))
+}
+
+// sshCommand 生成 ssh -p <port> user@<host>，地址未知时为空
+func sshCommand(endpoint, user string) string {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("ssh -p %s %s@%s", port, user, host)
+}
+
+// Gateway SSH 网关的连接信息，启用网关后 Tiny 不再占用 NodePort
+type Gateway struct {
+	// Host 网关对外的主机名或地址
+	Host string
+	// Port 网关对外的 SSH 端口
+	Port int32
+	// AuthorizedKey 网关登录 Unit 使用的公钥
+	AuthorizedKey string
+}
+
+// endpoint 返回网关的 host:port
+func (g *Gateway) endpoint() string {
+	return net.JoinHostPort(g.Host, strconv.Itoa(int(g.Port)))
+}
@@ -18,17 +18,28 @@ package tiny
 
 import (
 	"context"
+	"errors"
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	"github.com/cokeos/zero/controllers/config"
+	"github.com/cokeos/zero/controllers/events"
+	"github.com/cokeos/zero/controllers/logging"
+	"github.com/cokeos/zero/controllers/metrics"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/klog/v2"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"sync"
 	"time"
 
 	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // TinyReconciler reconciles a Tiny object
@@ -39,11 +50,24 @@ type TinyReconciler struct {
 	// PortMap
 	PortMap map[int32]bool
 	Mu      sync.RWMutex
+
+	// Gateway SSH 网关，为空时每个 Tiny 分配一个 NodePort
+	Gateway *Gateway
+	// Recorder 记录 Unit、Tunnel 创建与端口分配等事件
+	Recorder record.EventRecorder
+	// Config 当前生效的配置，为空时使用默认配置
+	Config *config.Store
+
+	// ports InitNodeMap 时配置的 NodePort 范围，修改后需要重启
+	ports configv1alpha1.PortRange
 }
 
 //+kubebuilder:rbac:groups=core.cokeos.io,resources=tinies,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.cokeos.io,resources=tinies/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core.cokeos.io,resources=tinies/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=tinyprofiles,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -54,6 +78,7 @@ type TinyReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.10.0/pkg/reconcile
 func (r *TinyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, log := logging.Reconcile(ctx, "Tiny")
 	var (
 		tiny      = &corev1.Tiny{}
 		unit      = &corev1.Unit{}
@@ -63,72 +88,195 @@ func (r *TinyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		tunnelErr = r.Get(ctx, req.NamespacedName, tunnel)
 	)
 
-	if tinyErr != nil {
-		if apierrors.IsNotFound(tinyErr) {
-			if err := r.Delete(ctx, unit); err != nil {
-				klog.Error(err)
-			}
-			if err := r.Delete(ctx, tunnel); err != nil {
-				klog.Error(err)
-			}
-		} else {
-			klog.Error(tinyErr)
+	for _, err := range []error{tinyErr, unitErr, tunnelErr} {
+		if err != nil && !apierrors.IsNotFound(err) {
+			metrics.LogError(log, "tiny", err, "Failed to get Tiny or its children")
+			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, nil
 	}
+	unitExists, tunnelExists := unitErr == nil, tunnelErr == nil
 
-	if tiny.DeletionTimestamp != nil {
-		if err := r.Delete(ctx, unit); err != nil {
-			klog.Error(err)
-		}
-		if err := r.Delete(ctx, tunnel); err != nil {
-			klog.Error(err)
+	if tinyErr != nil || tiny.DeletionTimestamp != nil {
+		return ctrl.Result{}, r.deleteChildren(ctx, tiny, tinyErr == nil, unit, unitExists, tunnel, tunnelExists)
+	}
+	ctx, log = logging.WithObject(ctx, tiny)
+
+	profile, err := r.getProfile(ctx, tiny)
+	if apierrors.IsNotFound(err) {
+		return ctrl.Result{}, r.profileNotFound(ctx, tiny)
+	}
+	if err != nil {
+		return ctrl.Result{}, events.Warning(ctx, r.Recorder, "tiny", tiny, ReasonProfileFailed, err)
+	}
+	if r.Gateway != nil {
+		if err := r.ensureHostKey(ctx, tiny); err != nil {
+			return ctrl.Result{}, events.Warning(ctx, r.Recorder, "tiny", tiny, ReasonHostKeyFailed, err)
 		}
-		return ctrl.Result{}, nil
 	}
 
-	if unitErr != nil {
-		if apierrors.IsNotFound(unitErr) {
-			unit = generateUnit(tiny)
-			if err := r.Create(ctx, unit); err != nil {
-				klog.Error(err)
-			}
+	// Unit 与 Tunnel 的错误不影响状态汇总，最后一并返回
+	var errs []error
+
+	// Unit 规格随 Tiny 与 Profile 同步
+	if !unitExists {
+		unit = generateUnit(tiny, profile, r.Gateway)
+		if err := r.Create(ctx, unit); err != nil {
+			errs = append(errs, events.Warning(ctx, r.Recorder, "tiny", tiny, ReasonUnitCreateFailed, err))
+		} else {
+			unitExists = true
+			r.Recorder.Eventf(tiny, v1.EventTypeNormal, ReasonUnitCreated, "Created Unit %s", unit.Name)
+			log.Info("Created Unit")
+		}
+	} else if syncUnit(unit, tiny, profile, r.Gateway) {
+		if err := r.Update(ctx, unit); err != nil {
+			errs = append(errs, events.Warning(ctx, r.Recorder, "tiny", tiny, ReasonUnitUpdateFailed, err))
+		} else {
+			log.V(logging.Debug).Info("Updated Unit")
 		}
 	}
 
-	if tunnelErr != nil {
-		if apierrors.IsNotFound(tunnelErr) {
-			port := r.FindSSHAvailablePort()
+	// Tunnel 规格随 Tiny 同步，沿用已分配的 NodePort，启用 SSH 网关时为 ClusterIP
+	status := tiny.Status.DeepCopy()
+	if !tunnelExists {
+		port := int32(0)
+		if r.Gateway == nil {
+			if port, err = r.allocatePort(ctx, tiny); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if port >= 0 {
 			tunnel = generateTunnel(tiny, port)
-			r.AddUsedPort(port)
 			if err := r.Create(ctx, tunnel); err != nil {
-				klog.Error(err)
+				// 创建失败时释放端口，否则端口一直被占用
+				if port > 0 && !apierrors.IsAlreadyExists(err) {
+					r.ReleasePort(port)
+				}
+				errs = append(errs, events.Warning(ctx, r.Recorder, "tiny", tiny, ReasonTunnelCreateFailed, err))
+			} else {
+				tunnelExists = true
+				r.Recorder.Eventf(tiny, v1.EventTypeNormal, ReasonTunnelCreated, "Created Tunnel %s", tunnel.Name)
+				log.Info("Created Tunnel", "nodePort", port)
+				status.NodePort = port
+			}
+		}
+	} else {
+		current := tunnelNodePort(tunnel)
+		port, allocated := int32(0), false
+		if r.Gateway == nil {
+			port = current
+			// 关闭 SSH 网关前创建的 ClusterIP Tunnel 没有 NodePort，重新分配
+			if port == 0 {
+				if port, err = r.allocatePort(ctx, tiny); err != nil {
+					errs = append(errs, err)
+				}
+				allocated = port > 0
 			}
-			tiny.Status.NodePort = port
-			if err := r.Status().Update(ctx, tiny); err != nil {
-				klog.Error(err)
+		}
+		if port < 0 {
+			port = 0
+		}
+		if syncTunnel(tunnel, tiny, port) {
+			if err := r.Update(ctx, tunnel); err != nil {
+				if allocated {
+					r.ReleasePort(port)
+				}
+				port = current
+				errs = append(errs, events.Warning(ctx, r.Recorder, "tiny", tiny, ReasonTunnelUpdateFailed, err))
+			} else {
+				log.V(logging.Debug).Info("Updated Tunnel")
 			}
 		}
+		status.NodePort = port
 	}
 
-	return ctrl.Result{}, nil
+	// 汇总 Unit 与 Tunnel 的状态
+	status.CreatedBy = tiny.Annotations[corev1.CreatedByAnnotation]
+	status.Profile = ""
+	if profile != nil {
+		status.Profile = profile.Name
+	}
+	if unitExists {
+		status.Phase = unit.Status.Phase
+	}
+	setConditions(status, tiny.Generation, unit, unitExists, tunnel, tunnelExists)
+	meta.SetStatusCondition(&status.Conditions, profileCondition(tiny, profile))
+	if r.Gateway != nil {
+		status.Host, status.Endpoint = r.Gateway.Host, r.Gateway.endpoint()
+		status.SSHCommand = sshCommand(status.Endpoint, tiny.Name)
+	} else {
+		status.Host, status.Endpoint = connection(unit, tunnel, tunnelExists, status.NodePort)
+		status.SSHCommand = sshCommand(status.Endpoint, SSHUser)
+	}
+	if !equality.Semantic.DeepEqual(status, &tiny.Status) {
+		tiny.Status = *status
+		if err := r.Status().Update(ctx, tiny); err != nil {
+			errs = append(errs, events.Warning(ctx, r.Recorder, "tiny", tiny, ReasonUpdateFailed, err))
+		}
+	}
+
+	return ctrl.Result{}, utilerrors.NewAggregate(errs)
+}
+
+// deleteChildren 删除 Tiny 的 Unit 与 Tunnel，Tiny 已不存在时不记录事件
+func (r *TinyReconciler) deleteChildren(ctx context.Context, tiny *corev1.Tiny, tinyExists bool,
+	unit *corev1.Unit, unitExists bool, tunnel *corev1.Tunnel, tunnelExists bool) error {
+	var errs []error
+	for _, child := range []struct {
+		obj    client.Object
+		exists bool
+	}{{unit, unitExists}, {tunnel, tunnelExists}} {
+		if !child.exists || child.obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+		err := client.IgnoreNotFound(r.Delete(ctx, child.obj))
+		kind := "Unit"
+		if _, ok := child.obj.(*corev1.Tunnel); ok {
+			kind = "Tunnel"
+		}
+		if !tinyExists {
+			if err != nil {
+				metrics.LogError(logging.FromContext(ctx), "tiny", err, "Failed to delete child of deleted Tiny", "child", kind)
+				errs = append(errs, err)
+			} else {
+				logging.FromContext(ctx).V(logging.Debug).Info("Deleted child of deleted Tiny", "child", kind)
+			}
+			continue
+		}
+		if err != nil {
+			errs = append(errs, events.Warning(ctx, r.Recorder, "tiny", tiny, ReasonDeleteFailed, err))
+			continue
+		}
+		r.Recorder.Eventf(tiny, v1.EventTypeNormal, ReasonDeleting, "Deleting %s %s", kind, child.obj.GetName())
+	}
+	return utilerrors.NewAggregate(errs)
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *TinyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *TinyReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
 	r.InitNodeMap()
-	go wait.Forever(r.UpdatePortMap, time.Minute)
-	go wait.Forever(r.SyncTiny, time.Minute)
+	period := func() time.Duration {
+		return r.Config.Get().SyncPeriods.Tiny.Duration
+	}
+	if err := mgr.Add(config.Loop{Sync: r.UpdatePortMap, Period: period}); err != nil {
+		return err
+	}
+	if err := mgr.Add(config.Loop{Sync: r.SyncTiny, Period: period}); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Tiny{}).
+		Watches(&source.Kind{Type: &corev1.TinyProfile{}}, handler.EnqueueRequestsFromMapFunc(r.profileRequests)).
+		Watches(&source.Kind{Type: &corev1.Unit{}}, handler.EnqueueRequestsFromMapFunc(r.childRequests)).
+		Watches(&source.Kind{Type: &corev1.Tunnel{}}, handler.EnqueueRequestsFromMapFunc(r.childRequests)).
 		Complete(r)
 }
 
 func (r *TinyReconciler) InitNodeMap() {
 	r.PortMap = make(map[int32]bool, 0)
 	r.Mu = sync.RWMutex{}
-	for i := 30000; i < 32000; i++ {
-		r.PortMap[int32(i)] = false
+	r.ports = *r.Config.Get().Tiny.NodePorts
+	for i := r.ports.Min; i <= r.ports.Max; i++ {
+		r.PortMap[i] = false
 	}
 }
 
@@ -136,7 +284,7 @@ func (r *TinyReconciler) UpdatePortMap() {
 	tunnelList := &corev1.TunnelList{}
 	err := r.Client.List(context.TODO(), tunnelList)
 	if err != nil {
-		klog.Error(err)
+		metrics.LogError(logging.Named("tiny"), "tiny", err, "Failed to list Tunnels")
 		return
 	}
 	for _, tunnel := range tunnelList.Items {
@@ -152,25 +300,66 @@ func (r *TinyReconciler) AddUsedPort(port int32) {
 	r.PortMap[port] = true
 }
 
-func (r *TinyReconciler) FindSSHAvailablePort() int32 {
+// AllocatePort 分配一个未使用的 NodePort 并标记为已使用，端口耗尽时返回 -1
+func (r *TinyReconciler) AllocatePort() int32 {
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+	for i := r.ports.Min; i <= r.ports.Max; i++ {
+		if !r.PortMap[i] {
+			r.PortMap[i] = true
+			return i
+		}
+	}
+	return -1
+}
+
+// ReleasePort 释放未被 Tunnel 使用的 NodePort
+func (r *TinyReconciler) ReleasePort(port int32) {
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+	if _, ok := r.PortMap[port]; ok {
+		r.PortMap[port] = false
+	}
+}
+
+// allocatePort 为 Tiny 分配 SSH NodePort，端口耗尽时记录事件并返回 -1 与错误
+func (r *TinyReconciler) allocatePort(ctx context.Context, tiny *corev1.Tiny) (int32, error) {
+	port := r.AllocatePort()
+	if port < 0 {
+		return port, events.Warning(ctx, r.Recorder, "tiny", tiny, ReasonPortExhausted, errors.New("no NodePort available for SSH"))
+	}
+	r.Recorder.Eventf(tiny, v1.EventTypeNormal, ReasonPortAllocated, "Allocated NodePort %d for SSH", port)
+	logging.FromContext(ctx).V(logging.Debug).Info("Allocated NodePort", "nodePort", port)
+	return port, nil
+}
+
+// UsedPorts NodePort 池中已分配的端口数
+func (r *TinyReconciler) UsedPorts() float64 {
 	r.Mu.RLock()
 	defer r.Mu.RUnlock()
-	for i := 30000; i < 32000; i++ {
-		if !r.PortMap[int32(i)] {
-			return int32(i)
+	used := 0
+	for i := r.ports.Min; i <= r.ports.Max; i++ {
+		if r.PortMap[i] {
+			used++
 		}
 	}
-	return -1
+	return float64(used)
+}
+
+// PortPoolSize NodePort 池的大小
+func (r *TinyReconciler) PortPoolSize() float64 {
+	return float64(r.ports.Size())
 }
 
 func (r *TinyReconciler) SyncTiny() {
 	var (
 		ctx  = context.TODO()
+		log  = logging.Named("tiny")
 		list = &corev1.TinyList{}
 	)
 	err := r.List(ctx, list)
 	if err != nil {
-		klog.Errorf("List Unit Error: %v", err)
+		metrics.LogError(log, "tiny", err, "Failed to list Tinies")
 		return
 	}
 	for _, tiny := range list.Items {
@@ -180,13 +369,13 @@ func (r *TinyReconciler) SyncTiny() {
 			Namespace: tiny.GetNamespace(),
 		}, unit)
 		if err != nil {
-			klog.Errorf("Get Unit Error: %v", err)
+			metrics.LogError(logging.ForObject("tiny", "Tiny", &tiny), "tiny", err, "Failed to get Unit")
 			continue
 		}
 		tiny.Status.Phase = unit.Status.Phase
 		err = r.Status().Update(ctx, tiny.DeepCopy())
 		if err != nil {
-			klog.Errorf("Update Tiny Phase Error: %v", err)
+			metrics.LogError(logging.ForObject("tiny", "Tiny", &tiny), "tiny", err, "Failed to update Tiny phase")
 		}
 	}
 
@@ -0,0 +1,259 @@
+package tiny
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	sshgateway "github.com/cokeos/zero/gateway"
+	"github.com/cokeos/zero/internal/zerotest"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newTestReconciler(tiny *corev1.Tiny) (*TinyReconciler, *record.FakeRecorder) {
+	recorder := record.NewFakeRecorder(10)
+	r := &TinyReconciler{
+		Client:   zerotest.NewClient(tiny),
+		Scheme:   zerotest.Scheme,
+		Recorder: recorder,
+	}
+	r.InitNodeMap()
+	return r, recorder
+}
+
+func recordedEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case e := <-recorder.Events:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestReconcileEvents(t *testing.T) {
+	tiny := &corev1.Tiny{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "demo"}}
+	r, recorder := newTestReconciler(tiny)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "alice", Name: "demo"}}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"Normal UnitCreated Created Unit demo",
+		"Normal PortAllocated Allocated NodePort 30000 for SSH",
+		"Normal TunnelCreated Created Tunnel demo",
+	}
+	got := recordedEvents(recorder)
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], got[i])
+		}
+	}
+	if r.UsedPorts() != 1 {
+		t.Errorf("expected one used port, got %v", r.UsedPorts())
+	}
+}
+
+func TestReconcilePortExhausted(t *testing.T) {
+	tiny := &corev1.Tiny{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "demo"}}
+	r, recorder := newTestReconciler(tiny)
+	for port := range r.PortMap {
+		r.PortMap[port] = true
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "alice", Name: "demo"}}
+	if _, err := r.Reconcile(context.TODO(), req); err == nil {
+		t.Fatal("expected the error to be returned for backoff")
+	}
+	found := false
+	for _, e := range recordedEvents(recorder) {
+		if e == "Warning PortExhausted no NodePort available for SSH" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected a PortExhausted event")
+	}
+}
+
+// failingTunnelClient 创建 Tunnel 时返回错误
+type failingTunnelClient struct {
+	client.Client
+}
+
+func (c *failingTunnelClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if _, ok := obj.(*corev1.Tunnel); ok {
+		return errors.New("admission denied")
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestReconcileReleasesPort(t *testing.T) {
+	tiny := &corev1.Tiny{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "demo"}}
+	r, _ := newTestReconciler(tiny)
+	r.Client = &failingTunnelClient{Client: r.Client}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "alice", Name: "demo"}}
+	if _, err := r.Reconcile(context.TODO(), req); err == nil {
+		t.Fatal("expected the error to be returned for backoff")
+	}
+	if r.UsedPorts() != 0 {
+		t.Errorf("expected the port to be released, got %v used", r.UsedPorts())
+	}
+	got := &corev1.Tiny{}
+	if err := r.Get(context.TODO(), req.NamespacedName, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.NodePort != 0 {
+		t.Errorf("expected no NodePort in the status, got %d", got.Status.NodePort)
+	}
+}
+
+func TestReconcileGatewayDisabled(t *testing.T) {
+	tiny := &corev1.Tiny{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "demo"}}
+	r, _ := newTestReconciler(tiny)
+	r.Gateway = &Gateway{Host: "ssh.cokeos.io", Port: 2222, AuthorizedKey: "ssh-ed25519 AAAA gateway"}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "alice", Name: "demo"}}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	// 关闭网关后 ClusterIP Tunnel 改为 NodePort
+	r.Gateway = nil
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	tunnel := &corev1.Tunnel{}
+	if err := r.Get(context.TODO(), req.NamespacedName, tunnel); err != nil {
+		t.Fatal(err)
+	}
+	if tunnelNodePort(tunnel) != 30000 || tunnel.Spec.Type == v1.ServiceTypeClusterIP {
+		t.Errorf("expected a NodePort Tunnel, got %+v", tunnel.Spec)
+	}
+	got := &corev1.Tiny{}
+	if err := r.Get(context.TODO(), req.NamespacedName, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.NodePort != 30000 || r.UsedPorts() != 1 {
+		t.Errorf("expected NodePort 30000 to be allocated, got %d with %v used", got.Status.NodePort, r.UsedPorts())
+	}
+}
+
+func TestReconcileCreatedBy(t *testing.T) {
+	tiny := &corev1.Tiny{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "alice",
+		Name:        "demo",
+		Annotations: map[string]string{corev1.CreatedByAnnotation: "alice"},
+	}}
+	r, _ := newTestReconciler(tiny)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "alice", Name: "demo"}}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	unit, tunnel := &corev1.Unit{}, &corev1.Tunnel{}
+	if err := r.Get(context.TODO(), req.NamespacedName, unit); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(context.TODO(), req.NamespacedName, tunnel); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(context.TODO(), req.NamespacedName, tiny); err != nil {
+		t.Fatal(err)
+	}
+	if unit.Annotations[corev1.CreatedByAnnotation] != "alice" || tunnel.Annotations[corev1.CreatedByAnnotation] != "alice" ||
+		tiny.Status.CreatedBy != "alice" {
+		t.Errorf("expected the creator to be propagated, got unit %v, tunnel %v, status %q",
+			unit.Annotations, tunnel.Annotations, tiny.Status.CreatedBy)
+	}
+}
+
+func TestReconcileProfileNotFound(t *testing.T) {
+	tiny := &corev1.Tiny{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "demo"}}
+	tiny.Spec.Profile = "gpu-large"
+	r, recorder := newTestReconciler(tiny)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "alice", Name: "demo"}}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	got := recordedEvents(recorder)
+	if len(got) != 1 || got[0] != "Warning ProfileNotFound TinyProfile gpu-large not found" {
+		t.Errorf("unexpected events %v", got)
+	}
+	if err := r.Get(context.TODO(), req.NamespacedName, tiny); err != nil {
+		t.Fatal(err)
+	}
+	for _, condition := range []string{corev1.TinyProfileReady, corev1.TinyReady} {
+		if c := meta.FindStatusCondition(tiny.Status.Conditions, condition); c == nil || c.Reason != ReasonProfileNotFound {
+			t.Errorf("expected %s to be %s, got %+v", condition, ReasonProfileNotFound, c)
+		}
+	}
+	if err := r.Get(context.TODO(), req.NamespacedName, &corev1.Unit{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected no Unit without the profile, got %v", err)
+	}
+
+	profile := &corev1.TinyProfile{ObjectMeta: metav1.ObjectMeta{Name: "gpu-large"}}
+	if err := r.Create(context.TODO(), profile); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(context.TODO(), req.NamespacedName, tiny); err != nil {
+		t.Fatal(err)
+	}
+	if !meta.IsStatusConditionTrue(tiny.Status.Conditions, corev1.TinyProfileReady) || tiny.Status.Profile != "gpu-large" {
+		t.Errorf("expected the profile to be resolved, got %+v", tiny.Status)
+	}
+}
+
+func TestReconcileGatewayHostKey(t *testing.T) {
+	tiny := &corev1.Tiny{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "demo"}}
+	r, _ := newTestReconciler(tiny)
+	r.Gateway = &Gateway{Host: "ssh.cokeos.io", Port: 2222, AuthorizedKey: "ssh-ed25519 AAAA gateway"}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "alice", Name: "demo"}}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	secret := &v1.Secret{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: "alice", Name: "demo-ssh-host-key"}, secret); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sshgateway.ParseHostKey(secret.Data); err != nil {
+		t.Errorf("expected a valid host key, got %v", err)
+	}
+	if !metav1.IsControlledBy(secret, tiny) {
+		t.Error("expected the host key to be owned by the Tiny")
+	}
+	unit := &corev1.Unit{}
+	if err := r.Get(context.TODO(), req.NamespacedName, unit); err != nil {
+		t.Fatal(err)
+	}
+	files := unit.Spec.Execution.Files
+	if len(files) != 1 || files[0].MountPath != sshgateway.HostKeyPath || files[0].Secret.Name != secret.Name {
+		t.Errorf("expected the host key to be mounted, got %+v", files)
+	}
+
+	// 再次同步不重新生成密钥
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	again := &v1.Secret{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: "alice", Name: secret.Name}, again); err != nil {
+		t.Fatal(err)
+	}
+	if string(again.Data[sshgateway.HostPublicKeyKey]) != string(secret.Data[sshgateway.HostPublicKeyKey]) {
+		t.Error("expected the host key to be kept")
+	}
+}
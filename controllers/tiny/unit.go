@@ -2,34 +2,149 @@ package tiny
 
 import (
 	corev1 "github.com/cokeos/zero/api/v1"
+	sshgateway "github.com/cokeos/zero/gateway"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-func generateUnit(tiny *corev1.Tiny) *corev1.Unit {
-	return &corev1.Unit{
+// AuthorizedKeysEnv 传给 Unit 的 SSH 网关公钥，镜像启动时将其写入 authorized_keys
+const AuthorizedKeysEnv = "SSH_AUTHORIZED_KEYS"
+
+// createdBy Tiny 生成的 Unit 与 Tunnel 沿用 Tiny 的创建者
+func createdBy(tiny *corev1.Tiny) map[string]string {
+	user := tiny.Annotations[corev1.CreatedByAnnotation]
+	if user == "" {
+		return nil
+	}
+	return map[string]string{corev1.CreatedByAnnotation: user}
+}
+
+func generateUnit(tiny *corev1.Tiny, profile *corev1.TinyProfile, gateway *Gateway) *corev1.Unit {
+	unit := &corev1.Unit{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: tiny.GetNamespace(),
-			Name:      tiny.GetName(),
+			Namespace:   tiny.GetNamespace(),
+			Name:        tiny.GetName(),
+			Annotations: createdBy(tiny),
 		},
 		Spec: corev1.UnitSpec{
 			Framework: tiny.Spec.Framework,
-			GPUPolicy: corev1.GPUPolicy{
-				GPU:    tiny.Spec.GPU,
-				Number: 1,
-			},
-			ResourceList: map[v1.ResourceName]resource.Quantity{
-				v1.ResourceCPU:           resource.MustParse("1"),
-				v1.ResourceMemory:        resource.MustParse("2Gi"),
-				corev1.ResourceNvidiaGPU: resource.MustParse("1"),
-			},
 			Execution: corev1.Execution{
 				SSH: true,
 			},
+			IdlePolicy: tiny.Spec.IdlePolicy.DeepCopy(),
 		},
 	}
+	applyProfile(&unit.Spec, tiny, profile)
+	applyGateway(&unit.Spec, tiny, gateway)
+	return unit
+}
+
+// applyGateway 启用 SSH 网关时传入网关公钥并挂载控制器生成的主机密钥，未启用时移除
+func applyGateway(spec *corev1.UnitSpec, tiny *corev1.Tiny, gateway *Gateway) {
+	authorizedKey, hostKeySecret := "", ""
+	if gateway != nil {
+		authorizedKey, hostKeySecret = gateway.AuthorizedKey, sshgateway.HostKeySecretName(tiny.Name)
+	}
+	applyAuthorizedKey(spec, authorizedKey)
+	applyHostKey(spec, hostKeySecret)
+}
+
+// applyHostKey 将 secret 中的主机私钥挂载为 sshd 的 ECDSA 主机密钥，secret 为空时移除挂载
+func applyHostKey(spec *corev1.UnitSpec, secret string) {
+	files := make([]corev1.FileProjection, 0, len(spec.Execution.Files)+1)
+	for _, file := range spec.Execution.Files {
+		if file.MountPath != sshgateway.HostKeyPath {
+			files = append(files, file)
+		}
+	}
+	if secret != "" {
+		// sshd 拒绝其他用户可读的私钥
+		mode := int32(0600)
+		files = append(files, corev1.FileProjection{
+			MountPath: sshgateway.HostKeyPath,
+			SubPath:   sshgateway.HostKeyKey,
+			Secret: &v1.SecretProjection{
+				LocalObjectReference: v1.LocalObjectReference{Name: secret},
+				Items:                []v1.KeyToPath{{Key: sshgateway.HostKeyKey, Path: sshgateway.HostKeyKey}},
+			},
+			DefaultMode: &mode,
+		})
+	}
+	if len(files) == 0 {
+		files = nil
+	}
+	spec.Execution.Files = files
+}
+
+// applyAuthorizedKey 设置或移除 SSH 网关公钥的环境变量
+func applyAuthorizedKey(spec *corev1.UnitSpec, authorizedKey string) {
+	env := make([]v1.EnvVar, 0, len(spec.Execution.Env)+1)
+	for _, e := range spec.Execution.Env {
+		if e.Name != AuthorizedKeysEnv {
+			env = append(env, e)
+		}
+	}
+	if authorizedKey != "" {
+		env = append(env, v1.EnvVar{
+			Name:  AuthorizedKeysEnv,
+			Value: authorizedKey,
+		})
+	}
+	if len(env) == 0 {
+		env = nil
+	}
+	spec.Execution.Env = env
+}
+
+// applyProfile 将 Profile 决定的规格写入 Unit，profile 为空时使用 1 CPU / 2Gi / 1 GPU
+func applyProfile(spec *corev1.UnitSpec, tiny *corev1.Tiny, profile *corev1.TinyProfile) {
+	if profile == nil {
+		spec.GPUPolicy = corev1.GPUPolicy{
+			GPU:    tiny.Spec.GPU,
+			Number: 1,
+		}
+		spec.ResourceList = map[v1.ResourceName]resource.Quantity{
+			v1.ResourceCPU:           resource.MustParse("1"),
+			v1.ResourceMemory:        resource.MustParse("2Gi"),
+			corev1.ResourceNvidiaGPU: resource.MustParse("1"),
+		}
+		applyTinyGPU(spec, tiny)
+		spec.ShmSize = nil
+		spec.LifeCycle = nil
+		return
+	}
+
+	number := profile.Spec.GPUNumber
+	if number == 0 {
+		number = 1
+	}
+	spec.GPUPolicy = corev1.GPUPolicy{
+		GPU:    tiny.Spec.GPU,
+		Model:  profile.Spec.GPUModel,
+		Number: number,
+	}
+	applyTinyGPU(spec, tiny)
+	spec.ResourceList = profile.Spec.ResourceList.DeepCopy()
+	if profile.Spec.ShmSize != nil {
+		shm := profile.Spec.ShmSize.DeepCopy()
+		spec.ShmSize = &shm
+	} else {
+		spec.ShmSize = nil
+	}
+	spec.LifeCycle = profile.Spec.LifeCycle.DeepCopy()
+}
+
+// applyTinyGPU Tiny 中的 GPU 数量与型号优先于 Profile
+func applyTinyGPU(spec *corev1.UnitSpec, tiny *corev1.Tiny) {
+	if tiny.Spec.GPUNumber > 0 {
+		spec.GPUPolicy.Number = int(tiny.Spec.GPUNumber)
+	}
+	if tiny.Spec.GPUModel != "" {
+		spec.GPUPolicy.Model = tiny.Spec.GPUModel
+	}
 }
 
 const (
@@ -37,11 +152,13 @@ const (
 	SSHPort = 22
 )
 
+// generateTunnel port 为 0 时生成 ClusterIP 类型的 Tunnel，由 SSH 网关转发
 func generateTunnel(tiny *corev1.Tiny, port int32) *corev1.Tunnel {
-	return &corev1.Tunnel{
+	tunnel := &corev1.Tunnel{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: tiny.GetNamespace(),
-			Name:      tiny.GetName(),
+			Namespace:   tiny.GetNamespace(),
+			Name:        tiny.GetName(),
+			Annotations: createdBy(tiny),
 		},
 		Spec: corev1.TunnelSpec{
 			UnitName: tiny.GetNamespace() + "." + tiny.GetName(),
@@ -56,4 +173,42 @@ func generateTunnel(tiny *corev1.Tiny, port int32) *corev1.Tunnel {
 			},
 		},
 	}
+	if port == 0 {
+		tunnel.Spec.Type = v1.ServiceTypeClusterIP
+	}
+	return tunnel
+}
+
+// syncUnit 将 Tiny 决定的字段同步到 Unit，Suspend 等由其他控制器维护的字段保持不变
+func syncUnit(unit *corev1.Unit, tiny *corev1.Tiny, profile *corev1.TinyProfile, gateway *Gateway) bool {
+	spec := unit.Spec.DeepCopy()
+	spec.Framework = tiny.Spec.Framework
+	spec.IdlePolicy = tiny.Spec.IdlePolicy.DeepCopy()
+	applyProfile(spec, tiny, profile)
+	applyGateway(spec, tiny, gateway)
+	if equality.Semantic.DeepEqual(spec, &unit.Spec) {
+		return false
+	}
+	unit.Spec = *spec
+	return true
+}
+
+// syncTunnel 将 Tiny 决定的端口与类型同步到 Tunnel
+func syncTunnel(tunnel *corev1.Tunnel, tiny *corev1.Tiny, port int32) bool {
+	desired := generateTunnel(tiny, port)
+	if equality.Semantic.DeepEqual(desired.Spec, tunnel.Spec) {
+		return false
+	}
+	tunnel.Spec = desired.Spec
+	return true
+}
+
+// tunnelNodePort 返回 Tunnel 已分配的 SSH NodePort，ClusterIP Tunnel 返回 0
+func tunnelNodePort(tunnel *corev1.Tunnel) int32 {
+	for _, port := range tunnel.Spec.Ports {
+		if port.Name == SSH && port.NodePort > 0 {
+			return port.NodePort
+		}
+	}
+	return 0
 }
@@ -0,0 +1,105 @@
+package tunnel
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AddressMode 对外连接地址的选取方式，取自配置文件的 tunnel.addressMode
+type AddressMode string
+
+const (
+	// AddressNodeExternalIP 使用 Pod 所在节点的外部 IP，没有时依次回退到内部 IP 与主机名
+	AddressNodeExternalIP AddressMode = "NodeExternalIP"
+	// AddressGateway 使用配置的网关主机名
+	AddressGateway AddressMode = "Gateway"
+	// AddressLoadBalancer 使用配置的负载均衡 VIP，未配置时使用 Service 的 LoadBalancer 地址
+	AddressLoadBalancer AddressMode = "LoadBalancer"
+)
+
+// endpoints 计算 Tunnel 各端口对外连接的地址，地址未知时返回空
+func (r *TunnelReconciler) endpoints(ctx context.Context, tunnel *corev1.Tunnel, svc *v1.Service) ([]corev1.TunnelEndpoint, error) {
+	host, err := r.host(ctx, tunnel, svc)
+	if err != nil || host == "" {
+		return nil, err
+	}
+	tunnelConfig := r.Config.Get().Tunnel
+	endpoints := make([]corev1.TunnelEndpoint, 0, len(svc.Spec.Ports))
+	for _, port := range svc.Spec.Ports {
+		number := port.NodePort
+		if svc.Spec.Type == v1.ServiceTypeLoadBalancer && AddressMode(tunnelConfig.AddressMode) == AddressLoadBalancer && tunnelConfig.LoadBalancerVIP == "" {
+			number = port.Port
+		}
+		if number == 0 {
+			continue
+		}
+		endpoints = append(endpoints, corev1.TunnelEndpoint{
+			Name:    port.Name,
+			Host:    host,
+			Port:    number,
+			Address: net.JoinHostPort(host, strconv.Itoa(int(number))),
+		})
+	}
+	return endpoints, nil
+}
+
+func (r *TunnelReconciler) host(ctx context.Context, tunnel *corev1.Tunnel, svc *v1.Service) (string, error) {
+	c := r.Config.Get()
+	switch AddressMode(c.Tunnel.AddressMode) {
+	case AddressGateway:
+		return c.Gateway.Host, nil
+	case AddressLoadBalancer:
+		if c.Tunnel.LoadBalancerVIP != "" {
+			return c.Tunnel.LoadBalancerVIP, nil
+		}
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			if ingress.Hostname != "" {
+				return ingress.Hostname, nil
+			}
+			if ingress.IP != "" {
+				return ingress.IP, nil
+			}
+		}
+		return "", nil
+	}
+
+	// 使用 Unit Pod 当前所在节点，Pod 迁移后地址随之更新
+	pods := &v1.PodList{}
+	err := r.List(ctx, pods, client.InNamespace(tunnel.Namespace), &client.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{
+			c.Unit.UniqLabelKey: tunnel.Spec.UnitName,
+		}),
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		node := &v1.Node{}
+		if err := r.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+			return "", err
+		}
+		return nodeAddress(node), nil
+	}
+	return "", nil
+}
+
+func nodeAddress(node *v1.Node) string {
+	for _, addressType := range []v1.NodeAddressType{v1.NodeExternalIP, v1.NodeInternalIP, v1.NodeHostName} {
+		for _, address := range node.Status.Addresses {
+			if address.Type == addressType && address.Address != "" {
+				return address.Address
+			}
+		}
+	}
+	return node.Name
+}
@@ -0,0 +1,31 @@
+package tunnel
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	ReasonServiceCreated      = "ServiceCreated"
+	ReasonServiceCreateFailed = "ServiceCreateFailed"
+	ReasonServiceUpdated      = "ServiceUpdated"
+	ReasonServiceUpdateFailed = "ServiceUpdateFailed"
+	ReasonDeleting            = "Deleting"
+	ReasonDeleteFailed        = "DeleteFailed"
+	ReasonUpdateFailed        = "UpdateFailed"
+)
+
+// portsMessage 描述 Service 分配的端口，例如 22:30022/TCP
+func portsMessage(service *v1.Service) string {
+	ports := make([]string, 0, len(service.Spec.Ports))
+	for _, port := range service.Spec.Ports {
+		if port.NodePort != 0 {
+			ports = append(ports, fmt.Sprintf("%d:%d/%s", port.Port, port.NodePort, port.Protocol))
+		} else {
+			ports = append(ports, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+		}
+	}
+	return strings.Join(ports, ",")
+}
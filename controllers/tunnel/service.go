@@ -1,19 +1,23 @@
 package tunnel
 
 import (
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
 	corev1 "github.com/cokeos/zero/api/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-const (
-	LabelKey   = "cokeos.io/zero-managed"
-	LabelValue = "true"
+// LabelValue Tunnel Service 上 LabelKey 标签的取值，标签键来自配置文件
+const LabelValue = "true"
 
-	UniqLabelKey = "cokeos.io/zero-id"
-)
-
-func generateService(tunnel *corev1.Tunnel) *v1.Service {
+// generateService 以 Unit Pod 的 UniqLabelKey 标签选择 Pod
+func generateService(tunnel *corev1.Tunnel, unitConfig configv1alpha1.UnitConfig) *v1.Service {
+	serviceType := tunnel.Spec.Type
+	if serviceType == "" {
+		serviceType = v1.ServiceTypeNodePort
+	}
 	return &v1.Service{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
@@ -23,15 +27,50 @@ func generateService(tunnel *corev1.Tunnel) *v1.Service {
 			Name:      tunnel.GetName(),
 			Namespace: tunnel.GetNamespace(),
 			Labels: map[string]string{
-				LabelKey: LabelValue,
+				unitConfig.LabelKey: LabelValue,
 			},
 		},
 		Spec: v1.ServiceSpec{
-			Type: v1.ServiceTypeNodePort,
+			Type: serviceType,
 			Selector: map[string]string{
-				UniqLabelKey: tunnel.Spec.UnitName,
+				unitConfig.UniqLabelKey: tunnel.Spec.UnitName,
 			},
 			Ports: tunnel.Spec.Ports,
 		},
 	}
 }
+
+// syncService 将 Tunnel 的类型、端口与选择器同步到 Service，返回 Service 是否发生变化
+func syncService(service *v1.Service, tunnel *corev1.Tunnel, unitConfig configv1alpha1.UnitConfig) bool {
+	desired := generateService(tunnel, unitConfig)
+	ports := make([]v1.ServicePort, 0, len(desired.Spec.Ports))
+	for _, port := range desired.Spec.Ports {
+		// 与 API Server 的默认值保持一致，避免反复更新
+		if port.Protocol == "" {
+			port.Protocol = v1.ProtocolTCP
+		}
+		if port.TargetPort.Type == intstr.Int && port.TargetPort.IntVal == 0 {
+			port.TargetPort = intstr.FromInt(int(port.Port))
+		}
+		// ClusterIP 不分配 NodePort，其余类型未指定时沿用已分配的端口
+		if desired.Spec.Type == v1.ServiceTypeClusterIP {
+			port.NodePort = 0
+		} else if port.NodePort == 0 {
+			for _, old := range service.Spec.Ports {
+				if old.Name == port.Name && old.Port == port.Port {
+					port.NodePort = old.NodePort
+				}
+			}
+		}
+		ports = append(ports, port)
+	}
+	if desired.Spec.Type == service.Spec.Type &&
+		equality.Semantic.DeepEqual(ports, service.Spec.Ports) &&
+		equality.Semantic.DeepEqual(desired.Spec.Selector, service.Spec.Selector) {
+		return false
+	}
+	service.Spec.Type = desired.Spec.Type
+	service.Spec.Ports = ports
+	service.Spec.Selector = desired.Spec.Selector
+	return true
+}
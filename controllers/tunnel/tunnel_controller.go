@@ -18,32 +18,41 @@ package tunnel
 
 import (
 	"context"
+	"github.com/cokeos/zero/controllers/config"
+	"github.com/cokeos/zero/controllers/events"
+	"github.com/cokeos/zero/controllers/logging"
+	"github.com/cokeos/zero/controllers/metrics"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/klog/v2"
 	"time"
 
 	corev1 "github.com/cokeos/zero/api/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const UpdatePeriod = time.Second * 10
-
 // TunnelReconciler reconciles a Tunnel object
 type TunnelReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Recorder 记录 Service 创建、端口分配与错误的事件
+	Recorder record.EventRecorder
+	// Config 当前生效的配置，为空时使用默认配置
+	Config *config.Store
 }
 
 //+kubebuilder:rbac:groups=core.cokeos.io,resources=tunnels,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.cokeos.io,resources=tunnels/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core.cokeos.io,resources=tunnels/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -54,63 +63,115 @@ type TunnelReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.10.0/pkg/reconcile
 func (r *TunnelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, log := logging.Reconcile(ctx, "Tunnel")
 	var (
 		tunnel  = &corev1.Tunnel{}
 		service = &v1.Service{}
 	)
 	tunnelErr := r.Get(ctx, req.NamespacedName, tunnel)
 	serviceErr := r.Get(ctx, req.NamespacedName, service)
+	if serviceErr != nil && !apierrors.IsNotFound(serviceErr) {
+		metrics.LogError(log, "tunnel", serviceErr, "Failed to get Service")
+		return ctrl.Result{}, serviceErr
+	}
+	serviceExists := serviceErr == nil
 
 	if tunnelErr != nil {
-		if apierrors.IsNotFound(tunnelErr) {
-			if err := r.Delete(ctx, service); err != nil {
-				klog.Error(err)
+		if !apierrors.IsNotFound(tunnelErr) {
+			metrics.LogError(log, "tunnel", tunnelErr, "Failed to get Tunnel")
+			return ctrl.Result{}, tunnelErr
+		}
+		if serviceExists {
+			if err := r.Delete(ctx, service); client.IgnoreNotFound(err) != nil {
+				metrics.LogError(log, "tunnel", err, "Failed to delete Service of deleted Tunnel")
+				return ctrl.Result{}, err
 			}
-		} else {
-			klog.Error(tunnelErr)
+			log.V(logging.Debug).Info("Deleted Service of deleted Tunnel")
 		}
 		return ctrl.Result{}, nil
 	}
+	ctx, log = logging.WithObject(ctx, tunnel)
 
 	if tunnel.DeletionTimestamp != nil {
-		if err := r.Delete(ctx, service); err != nil {
-			klog.Error(err)
+		if serviceExists && service.DeletionTimestamp == nil {
+			if err := r.Delete(ctx, service); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, events.Warning(ctx, r.Recorder, "tunnel", tunnel, ReasonDeleteFailed, err)
+			}
+			r.Recorder.Eventf(tunnel, v1.EventTypeNormal, ReasonDeleting, "Deleting Service %s", service.Name)
+			log.V(logging.Debug).Info("Deleting Service of terminating Tunnel")
 		}
 		return ctrl.Result{}, nil
 	}
 
-	if serviceErr != nil {
-		if apierrors.IsNotFound(serviceErr) {
-			service = generateService(tunnel)
-			if err := r.Create(ctx, service); err != nil {
-				tunnel.Status.Conditions = []metav1.Condition{
-					{
-						Type:    "Error",
-						Status:  metav1.ConditionTrue,
-						Message: err.Error(),
-					},
-				}
-				return ctrl.Result{}, r.Update(ctx, tunnel)
+	if !serviceExists {
+		service = generateService(tunnel, r.Config.Get().Unit)
+		if createErr := r.Create(ctx, service); createErr != nil {
+			events.Warning(ctx, r.Recorder, "tunnel", tunnel, ReasonServiceCreateFailed, createErr)
+			setReadyCondition(tunnel, metav1.ConditionFalse, ReasonServiceCreateFailed, createErr.Error())
+			if err := r.Status().Update(ctx, tunnel); err != nil {
+				metrics.LogError(log, "tunnel", err, "Failed to update Tunnel status")
 			}
-			return ctrl.Result{}, nil
-		} else {
-			klog.Error(serviceErr)
+			return ctrl.Result{}, createErr
 		}
+		r.Recorder.Eventf(tunnel, v1.EventTypeNormal, ReasonServiceCreated, "Created Service %s with ports %s", service.Name, portsMessage(service))
+		log.Info("Created Service", "ports", portsMessage(service))
+		setReadyCondition(tunnel, metav1.ConditionTrue, ReasonServiceCreated, "service "+service.Name+" created")
+		if err := r.Status().Update(ctx, tunnel); err != nil {
+			return ctrl.Result{}, events.Warning(ctx, r.Recorder, "tunnel", tunnel, ReasonUpdateFailed, err)
+		}
+		return ctrl.Result{}, nil
 	}
 
+	// 同步 Tunnel 规格到 Service
+	if syncService(service, tunnel, r.Config.Get().Unit) {
+		if updateErr := r.Update(ctx, service); updateErr != nil {
+			events.Warning(ctx, r.Recorder, "tunnel", tunnel, ReasonServiceUpdateFailed, updateErr)
+			setReadyCondition(tunnel, metav1.ConditionFalse, ReasonServiceUpdateFailed, updateErr.Error())
+			if err := r.Status().Update(ctx, tunnel); err != nil {
+				metrics.LogError(log, "tunnel", err, "Failed to update Tunnel status")
+			}
+			return ctrl.Result{}, updateErr
+		}
+		r.Recorder.Eventf(tunnel, v1.EventTypeNormal, ReasonServiceUpdated, "Updated Service %s with ports %s", service.Name, portsMessage(service))
+		log.Info("Updated Service", "ports", portsMessage(service))
+		setReadyCondition(tunnel, metav1.ConditionTrue, ReasonServiceUpdated, "service "+service.Name+" updated")
+		if err := r.Status().Update(ctx, tunnel); err != nil {
+			return ctrl.Result{}, events.Warning(ctx, r.Recorder, "tunnel", tunnel, ReasonUpdateFailed, err)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Service 与规格一致但没有 Ready 条件时补上，更新失败的原因保留到下一次成功更新
+	if meta.FindStatusCondition(tunnel.Status.Conditions, corev1.TunnelReady) == nil {
+		setReadyCondition(tunnel, metav1.ConditionTrue, ReasonServiceCreated, "service "+service.Name+" created")
+		if err := r.Status().Update(ctx, tunnel); err != nil {
+			return ctrl.Result{}, events.Warning(ctx, r.Recorder, "tunnel", tunnel, ReasonUpdateFailed, err)
+		}
+	}
 	return ctrl.Result{}, nil
 }
 
+func setReadyCondition(tunnel *corev1.Tunnel, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&tunnel.Status.Conditions, metav1.Condition{
+		Type:               corev1.TunnelReady,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: tunnel.Generation,
+	})
+}
+
 func (r *TunnelReconciler) SyncService() {
 	var (
 		ctx  = context.TODO()
+		log  = logging.Named("tunnel")
 		list = &v1.ServiceList{}
 	)
 	err := r.List(ctx, list, &client.ListOptions{LabelSelector: labels.SelectorFromSet(map[string]string{
-		LabelKey: LabelValue,
+		r.Config.Get().Unit.LabelKey: LabelValue,
 	})})
 	if err != nil {
-		klog.Errorf("List Services Error: %v", err)
+		metrics.LogError(log, "tunnel", err, "Failed to list Services")
 		return
 	}
 	for _, svc := range list.Items {
@@ -120,24 +181,36 @@ func (r *TunnelReconciler) SyncService() {
 			Namespace: svc.GetNamespace(),
 		}, tunnel)
 		if err != nil {
-			klog.Errorf("Get Tunnel Error: %v", err)
+			metrics.LogError(log, "tunnel", err, "Failed to get Tunnel", "namespace", svc.Namespace, "name", svc.Name)
 			continue
 		}
-		tunnel.Status.Conditions = svc.Status.Conditions
+		log := logging.ForObject("tunnel", "Tunnel", tunnel)
+		// Service 自身的条件加上 Ready 条件，Ready 只由 Reconcile 根据 Service 的创建与更新结果设置
+		conditions := append([]metav1.Condition{}, svc.Status.Conditions...)
+		if ready := meta.FindStatusCondition(tunnel.Status.Conditions, corev1.TunnelReady); ready != nil {
+			conditions = append(conditions, *ready)
+		}
+		tunnel.Status.Conditions = conditions
+		endpoints, err := r.endpoints(ctx, tunnel, &svc)
+		if err != nil {
+			metrics.LogError(log, "tunnel", err, "Failed to get Tunnel endpoints")
+		} else {
+			tunnel.Status.Endpoints = endpoints
+		}
 		err = r.Status().Update(ctx, tunnel)
 		if err != nil {
-			klog.Errorf("Update Tunnel Conditions Error: %v", err)
+			metrics.LogError(log, "tunnel", err, "Failed to update Tunnel conditions")
 		}
 	}
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *TunnelReconciler) SetupWithManager(mgr ctrl.Manager, ctx context.Context) error {
-	go func() {
-		if mgr.GetCache().WaitForCacheSync(ctx) {
-			go wait.Until(r.SyncService, UpdatePeriod, ctx.Done())
-		}
-	}()
+func (r *TunnelReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	if err := mgr.Add(config.Loop{Sync: r.SyncService, Period: func() time.Duration {
+		return r.Config.Get().SyncPeriods.Tunnel.Duration
+	}}); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Tunnel{}).
 		Complete(r)
@@ -0,0 +1,178 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/controllers/config"
+	"github.com/cokeos/zero/internal/zerotest"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// failingClient 更新 Service 时返回 err
+type failingClient struct {
+	client.Client
+	err error
+}
+
+func (c *failingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if _, ok := obj.(*v1.Service); ok && c.err != nil {
+		return c.err
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func newTestReconciler(objs ...client.Object) (*TunnelReconciler, *failingClient) {
+	c := &failingClient{Client: zerotest.NewClient(objs...)}
+	return &TunnelReconciler{
+		Client:   c,
+		Scheme:   zerotest.Scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}, c
+}
+
+func newTunnel() *corev1.Tunnel {
+	return &corev1.Tunnel{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"},
+		Spec: corev1.TunnelSpec{
+			UnitName: "alice.train",
+			Ports:    []v1.ServicePort{{Name: "ssh", Port: 22}},
+		},
+	}
+}
+
+func readyCondition(t *testing.T, r *TunnelReconciler) *metav1.Condition {
+	tunnel := &corev1.Tunnel{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: "alice", Name: "train"}, tunnel); err != nil {
+		t.Fatal(err)
+	}
+	return meta.FindStatusCondition(tunnel.Status.Conditions, corev1.TunnelReady)
+}
+
+func TestReadyKeepsServiceUpdateFailure(t *testing.T) {
+	tunnel := newTunnel()
+	service := generateService(tunnel, configv1alpha1.NewDefault().Unit)
+	service.Spec.Ports = []v1.ServicePort{{Name: "ssh", Port: 2222}}
+	r, c := newTestReconciler(tunnel, service)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "alice", Name: "train"}}
+
+	c.err = errors.New("nodePort conflict")
+	if _, err := r.Reconcile(context.TODO(), req); err == nil {
+		t.Fatal("expected the update error to be returned")
+	}
+	if ready := readyCondition(t, r); ready == nil || ready.Reason != ReasonServiceUpdateFailed {
+		t.Fatalf("expected %s, got %+v", ReasonServiceUpdateFailed, ready)
+	}
+
+	// 周期同步不覆盖失败原因
+	r.SyncService()
+	if ready := readyCondition(t, r); ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != ReasonServiceUpdateFailed {
+		t.Fatalf("expected the failure to be kept, got %+v", ready)
+	}
+
+	c.err = nil
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	if ready := readyCondition(t, r); ready == nil || ready.Status != metav1.ConditionTrue || ready.Reason != ReasonServiceUpdated {
+		t.Errorf("expected Ready after a successful update, got %+v", ready)
+	}
+}
+
+func newNode(name string, addresses ...v1.NodeAddress) *v1.Node {
+	return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: v1.NodeStatus{Addresses: addresses}}
+}
+
+func TestNodeAddress(t *testing.T) {
+	cases := []struct {
+		node     *v1.Node
+		expected string
+	}{
+		{newNode("gpu-1",
+			v1.NodeAddress{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+			v1.NodeAddress{Type: v1.NodeExternalIP, Address: "1.2.3.4"}), "1.2.3.4"},
+		{newNode("gpu-1",
+			v1.NodeAddress{Type: v1.NodeHostName, Address: "gpu-1.local"},
+			v1.NodeAddress{Type: v1.NodeInternalIP, Address: "10.0.0.1"}), "10.0.0.1"},
+		{newNode("gpu-1", v1.NodeAddress{Type: v1.NodeHostName, Address: "gpu-1.local"}), "gpu-1.local"},
+		{newNode("gpu-1"), "gpu-1"},
+	}
+	for _, c := range cases {
+		if got := nodeAddress(c.node); got != c.expected {
+			t.Errorf("expected %s, got %s", c.expected, got)
+		}
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	tunnel := newTunnel()
+	service := generateService(tunnel, configv1alpha1.NewDefault().Unit)
+	service.Spec.Ports[0].NodePort = 30022
+	service.Spec.Ports = append(service.Spec.Ports, v1.ServicePort{Name: "http", Port: 80})
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train", Labels: map[string]string{configv1alpha1.DefaultUniqLabelKey: "alice.train"}},
+		Spec:       v1.PodSpec{NodeName: "gpu-1"},
+	}
+	node := newNode("gpu-1", v1.NodeAddress{Type: v1.NodeExternalIP, Address: "1.2.3.4"})
+
+	cases := []struct {
+		name     string
+		mode     AddressMode
+		vip      string
+		svcType  v1.ServiceType
+		ingress  []v1.LoadBalancerIngress
+		expected []string
+	}{
+		{"node", AddressNodeExternalIP, "", v1.ServiceTypeNodePort, nil, []string{"1.2.3.4:30022"}},
+		{"gateway", AddressGateway, "", v1.ServiceTypeNodePort, nil, []string{"ssh.cokeos.io:30022"}},
+		{"vip", AddressLoadBalancer, "10.1.1.1", v1.ServiceTypeLoadBalancer, nil, []string{"10.1.1.1:30022"}},
+		{"ingress", AddressLoadBalancer, "", v1.ServiceTypeLoadBalancer,
+			[]v1.LoadBalancerIngress{{Hostname: "lb.cokeos.io"}}, []string{"lb.cokeos.io:22", "lb.cokeos.io:80"}},
+		{"pending", AddressLoadBalancer, "", v1.ServiceTypeLoadBalancer, nil, nil},
+	}
+	for _, c := range cases {
+		r, _ := newTestReconciler(pod, node)
+		store, err := config.NewStore("", func(config *configv1alpha1.ZeroConfig) {
+			config.Tunnel.AddressMode, config.Tunnel.LoadBalancerVIP = string(c.mode), c.vip
+			config.Gateway.Host = "ssh.cokeos.io"
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Config = store
+		svc := service.DeepCopy()
+		svc.Spec.Type = c.svcType
+		svc.Status.LoadBalancer.Ingress = c.ingress
+		endpoints, err := r.endpoints(context.TODO(), tunnel, svc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(endpoints) != len(c.expected) {
+			t.Errorf("%s: expected %v, got %+v", c.name, c.expected, endpoints)
+			continue
+		}
+		for i, e := range endpoints {
+			if e.Address != c.expected[i] {
+				t.Errorf("%s: expected %s, got %s", c.name, c.expected[i], e.Address)
+			}
+		}
+	}
+}
+
+func TestEndpointsWithoutPod(t *testing.T) {
+	r, _ := newTestReconciler()
+	tunnel := newTunnel()
+	endpoints, err := r.endpoints(context.TODO(), tunnel, generateService(tunnel, configv1alpha1.NewDefault().Unit))
+	if err != nil || endpoints != nil {
+		t.Errorf("expected no endpoints before the Pod is scheduled, got %+v, %v", endpoints, err)
+	}
+}
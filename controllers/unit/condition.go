@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"strings"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ReasonCreated      = "Created"
+	ReasonCreateFailed = "CreateFailed"
+	ReasonScheduled    = "Scheduled"
+	ReasonPulled       = "Pulled"
+	ReasonPulling      = "Pulling"
+)
+
+// imagePullFailures 容器等待中表示镜像拉取失败的原因
+var imagePullFailures = map[string]bool{
+	"ErrImagePull":      true,
+	"ImagePullBackOff":  true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// setCondition 设置条件，返回条件是否发生变化
+func setCondition(unit *corev1.Unit, condition metav1.Condition) bool {
+	condition.ObservedGeneration = unit.Generation
+	old := meta.FindStatusCondition(unit.Status.Conditions, condition.Type)
+	if old != nil && old.Status == condition.Status && old.Reason == condition.Reason &&
+		old.Message == condition.Message && old.ObservedGeneration == condition.ObservedGeneration {
+		return false
+	}
+	meta.SetStatusCondition(&unit.Status.Conditions, condition)
+	return true
+}
+
+// setPodCreatedCondition 根据 Pod 的创建结果设置 PodCreated 条件，超出配额时原因为 QuotaExceeded
+func setPodCreatedCondition(unit *corev1.Unit, err error) bool {
+	condition := metav1.Condition{
+		Type:    corev1.ConditionPodCreated,
+		Status:  metav1.ConditionTrue,
+		Reason:  ReasonCreated,
+		Message: "pod is created",
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonCreateFailed
+		condition.Message = err.Error()
+		// ResourceQuota 准入拒绝时返回 Forbidden: exceeded quota
+		if apierrors.IsForbidden(err) && strings.Contains(err.Error(), "exceeded quota") {
+			condition.Reason = corev1.ReasonQuotaExceeded
+		}
+	}
+	return setCondition(unit, condition)
+}
+
+// setPodConditions 根据 Pod 的状态设置 PodScheduled 与 ImagePulled 条件
+func setPodConditions(unit *corev1.Unit, pod *v1.Pod) bool {
+	scheduled := metav1.Condition{
+		Type:    corev1.ConditionPodScheduled,
+		Status:  metav1.ConditionUnknown,
+		Reason:  string(v1.PodPending),
+		Message: "pod is waiting to be scheduled",
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type != v1.PodScheduled {
+			continue
+		}
+		switch {
+		case c.Status == v1.ConditionTrue:
+			scheduled.Status = metav1.ConditionTrue
+			scheduled.Reason = ReasonScheduled
+			scheduled.Message = "pod is scheduled to " + pod.Spec.NodeName
+		case c.Reason == v1.PodReasonUnschedulable:
+			scheduled.Status = metav1.ConditionFalse
+			scheduled.Reason = corev1.ReasonUnschedulable
+			scheduled.Message = c.Message
+		}
+	}
+	if pod.Spec.NodeName != "" && scheduled.Status != metav1.ConditionTrue {
+		scheduled.Status = metav1.ConditionTrue
+		scheduled.Reason = ReasonScheduled
+		scheduled.Message = "pod is scheduled to " + pod.Spec.NodeName
+	}
+
+	pulled := metav1.Condition{
+		Type:    corev1.ConditionImagePulled,
+		Status:  metav1.ConditionUnknown,
+		Reason:  ReasonPulling,
+		Message: "image is being pulled",
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if waiting := status.State.Waiting; waiting != nil && imagePullFailures[waiting.Reason] {
+			pulled.Status = metav1.ConditionFalse
+			pulled.Reason = corev1.ReasonImagePullFailed
+			pulled.Message = waiting.Reason + ": " + waiting.Message
+			break
+		}
+		if status.ImageID != "" {
+			pulled.Status = metav1.ConditionTrue
+			pulled.Reason = ReasonPulled
+			pulled.Message = "image " + status.Image + " is pulled"
+		}
+	}
+
+	changed := setCondition(unit, scheduled)
+	// 未调度的 Pod 还不会拉取镜像
+	if scheduled.Status == metav1.ConditionTrue {
+		changed = setCondition(unit, pulled) || changed
+	}
+	return changed
+}
@@ -0,0 +1,79 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestSetPodCreatedCondition(t *testing.T) {
+	unit := &corev1.Unit{}
+	quota := apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "train",
+		errors.New("exceeded quota: zero-quota, requested: requests.nvidia.com/gpu=1"))
+	if !setPodCreatedCondition(unit, quota) {
+		t.Fatal("expected the condition to change")
+	}
+	condition := meta.FindStatusCondition(unit.Status.Conditions, corev1.ConditionPodCreated)
+	if condition.Reason != corev1.ReasonQuotaExceeded {
+		t.Errorf("expected QuotaExceeded, got %q", condition.Reason)
+	}
+	if setPodCreatedCondition(unit, quota) {
+		t.Error("expected the same error not to change the condition")
+	}
+
+	setPodCreatedCondition(unit, errors.New("connection refused"))
+	if condition := meta.FindStatusCondition(unit.Status.Conditions, corev1.ConditionPodCreated); condition.Reason != ReasonCreateFailed {
+		t.Errorf("expected CreateFailed, got %q", condition.Reason)
+	}
+	setPodCreatedCondition(unit, nil)
+	if !meta.IsStatusConditionTrue(unit.Status.Conditions, corev1.ConditionPodCreated) {
+		t.Error("expected PodCreated to be true")
+	}
+}
+
+func TestSetPodConditions(t *testing.T) {
+	unit := &corev1.Unit{}
+	pod := &v1.Pod{}
+	pod.Status.Conditions = []v1.PodCondition{{
+		Type:    v1.PodScheduled,
+		Status:  v1.ConditionFalse,
+		Reason:  v1.PodReasonUnschedulable,
+		Message: "0/3 nodes are available: 3 Insufficient nvidia.com/gpu.",
+	}}
+	setPodConditions(unit, pod)
+	scheduled := meta.FindStatusCondition(unit.Status.Conditions, corev1.ConditionPodScheduled)
+	if scheduled.Reason != corev1.ReasonUnschedulable || scheduled.Message != pod.Status.Conditions[0].Message {
+		t.Errorf("unexpected condition %+v", scheduled)
+	}
+	if meta.FindStatusCondition(unit.Status.Conditions, corev1.ConditionImagePulled) != nil {
+		t.Error("expected no ImagePulled condition before scheduling")
+	}
+
+	pod.Spec.NodeName = "gpu-1"
+	pod.Status.Conditions[0].Status = v1.ConditionTrue
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{{
+		State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "not found"}},
+	}}
+	setPodConditions(unit, pod)
+	if !meta.IsStatusConditionTrue(unit.Status.Conditions, corev1.ConditionPodScheduled) {
+		t.Error("expected PodScheduled to be true")
+	}
+	pulled := meta.FindStatusCondition(unit.Status.Conditions, corev1.ConditionImagePulled)
+	if pulled.Reason != corev1.ReasonImagePullFailed {
+		t.Errorf("expected ImagePullFailed, got %q", pulled.Reason)
+	}
+
+	pod.Status.ContainerStatuses[0] = v1.ContainerStatus{
+		Image:   "pytorch:1.9",
+		ImageID: "docker-pullable://pytorch@sha256:abc",
+		State:   v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+	}
+	if !setPodConditions(unit, pod) || !meta.IsStatusConditionTrue(unit.Status.Conditions, corev1.ConditionImagePulled) {
+		t.Error("expected ImagePulled to be true")
+	}
+}
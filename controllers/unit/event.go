@@ -0,0 +1,14 @@
+package unit
+
+const (
+	ReasonDeleting         = "Deleting"
+	ReasonDeleteFailed     = "DeleteFailed"
+	ReasonSuspended        = "Suspended"
+	ReasonRecreating       = "Recreating"
+	ReasonUpdateFailed     = "UpdateFailed"
+	ReasonMissingReference = "MissingReference"
+	ReasonNetworkPolicy    = "NetworkPolicyFailed"
+	ReasonSyncFailed       = "SyncFailed"
+	ReasonLogsArchived     = "LogsArchived"
+	ReasonArchiveFailed    = "ArchiveFailed"
+)
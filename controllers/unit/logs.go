@@ -0,0 +1,125 @@
+package unit
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/controllers/events"
+	"github.com/cokeos/zero/controllers/logging"
+	"github.com/cokeos/zero/controllers/metrics"
+	"github.com/cokeos/zero/logarchive"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// MaxLogArchives UnitStatus 中保留的归档数，更早的归档仍在存储中
+	MaxLogArchives = 10
+	// MaxLogBytes 单个 Pod 归档的日志上限，超出部分丢弃
+	MaxLogBytes = 64 << 20
+)
+
+// LogArchiver 在 Pod 结束或被删除前归档 Unit 容器的日志
+type LogArchiver struct {
+	// Kubernetes 读取 Pod 日志，controller-runtime 的客户端不支持子资源 log
+	Kubernetes kubernetes.Interface
+	Store      logarchive.Store
+}
+
+// archiveLogs 归档 Pod 的日志，容器未启动或已归档时返回 nil，unit 为空表示 Unit 已删除
+func (r *UnitReconciler) archiveLogs(ctx context.Context, unit *corev1.Unit, pod *v1.Pod) (*corev1.LogArchive, error) {
+	if r.LogArchiver == nil || !containerStarted(pod) {
+		return nil, nil
+	}
+	if unit != nil && logArchived(unit, pod.UID) {
+		return nil, nil
+	}
+	limit := int64(MaxLogBytes)
+	stream, err := r.LogArchiver.Kubernetes.CoreV1().Pods(pod.Namespace).
+		GetLogs(pod.Name, &v1.PodLogOptions{Container: pod.Name, LimitBytes: &limit}).Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, err
+	}
+	location, err := r.LogArchiver.Store.Put(ctx, pod.Namespace, pod.Name, logName(pod), data)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.LogArchive{
+		Location:    location,
+		PodUID:      pod.UID,
+		Phase:       pod.Status.Phase,
+		ArchiveTime: metav1.Now(),
+	}, nil
+}
+
+// archiveBeforeDelete 删除 Pod 前归档日志，归档失败不阻止删除，避免长期占用 GPU
+func (r *UnitReconciler) archiveBeforeDelete(ctx context.Context, unit *corev1.Unit, pod *v1.Pod) {
+	archive, err := r.archiveLogs(ctx, unit, pod)
+	if err != nil {
+		if unit == nil {
+			metrics.LogError(logging.FromContext(ctx), "unit", err, "Failed to archive logs of deleted Unit")
+		} else {
+			_ = events.Warning(ctx, r.Recorder, "unit", unit, ReasonArchiveFailed, err)
+		}
+		return
+	}
+	if archive == nil || unit == nil {
+		return
+	}
+	r.recordLogArchive(unit, pod, archive)
+	logging.FromContext(ctx).Info("Archived logs", "location", archive.Location)
+	if err := r.Status().Update(ctx, unit); err != nil {
+		_ = events.Warning(ctx, r.Recorder, "unit", unit, ReasonUpdateFailed, err)
+	}
+}
+
+// recordLogArchive 将归档加入 UnitStatus，只保留最近的 MaxLogArchives 条
+func (r *UnitReconciler) recordLogArchive(unit *corev1.Unit, pod *v1.Pod, archive *corev1.LogArchive) {
+	r.Recorder.Eventf(unit, v1.EventTypeNormal, ReasonLogsArchived, "Archived logs of Pod %s to %s", pod.Name, archive.Location)
+	unit.Status.LogArchives = append(unit.Status.LogArchives, *archive)
+	if n := len(unit.Status.LogArchives); n > MaxLogArchives {
+		unit.Status.LogArchives = unit.Status.LogArchives[n-MaxLogArchives:]
+	}
+}
+
+func logArchived(unit *corev1.Unit, uid types.UID) bool {
+	for _, archive := range unit.Status.LogArchives {
+		if archive.PodUID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// containerStarted 容器未启动时没有日志，例如仍在拉取镜像
+func containerStarted(pod *v1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running != nil || status.State.Terminated != nil || status.LastTerminationState.Terminated != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// logName 例如 20211019-090000-1a2b3c4d.log，按 Pod 创建时间排序
+func logName(pod *v1.Pod) string {
+	uid := strings.ReplaceAll(string(pod.UID), "-", "")
+	if len(uid) > 8 {
+		uid = uid[:8]
+	}
+	return pod.CreationTimestamp.UTC().Format("20060102-150405") + "-" + uid + ".log"
+}
+
+// podFinished 批处理 Unit 的 Pod 不会重启，结束后即可归档
+func podFinished(pod *v1.Pod) bool {
+	return pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed
+}
@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+type memoryStore map[string]string
+
+func (s memoryStore) Put(ctx context.Context, namespace, unit, name string, data []byte) (string, error) {
+	location := "mem://" + namespace + "/" + unit + "/" + name
+	s[location] = string(data)
+	return location, nil
+}
+
+func newFinishedPod(phase v1.PodPhase) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "alice",
+			Name:              "train",
+			UID:               "1a2b3c4d-0000-0000-0000-000000000000",
+			CreationTimestamp: metav1.Date(2021, 10, 19, 9, 0, 0, 0, time.UTC),
+			Labels:            map[string]string{configv1alpha1.DefaultLabelKey: LabelValue},
+		},
+		Status: v1.PodStatus{
+			Phase: phase,
+			ContainerStatuses: []v1.ContainerStatus{{
+				Name:  "train",
+				State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+			}},
+		},
+	}
+}
+
+func TestSyncPodsArchivesFinishedPods(t *testing.T) {
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"}}
+	r, _ := newTestReconciler(unit, newFinishedPod(v1.PodSucceeded))
+	store := memoryStore{}
+	r.LogArchiver = &LogArchiver{Kubernetes: kubefake.NewSimpleClientset(), Store: store}
+
+	r.SyncPods()
+	r.SyncPods()
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: "alice", Name: "train"}, unit); err != nil {
+		t.Fatal(err)
+	}
+	if len(unit.Status.LogArchives) != 1 {
+		t.Fatalf("expected one archive, got %+v", unit.Status.LogArchives)
+	}
+	archive := unit.Status.LogArchives[0]
+	if archive.Phase != v1.PodSucceeded || store[archive.Location] != "fake logs" {
+		t.Errorf("unexpected archive %+v in %v", archive, store)
+	}
+}
+
+func TestReconcileArchivesBeforeSuspend(t *testing.T) {
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"}}
+	unit.Spec.Suspend = true
+	r, _ := newTestReconciler(unit, newFinishedPod(v1.PodRunning))
+	store := memoryStore{}
+	r.LogArchiver = &LogArchiver{Kubernetes: kubefake.NewSimpleClientset(), Store: store}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "alice", Name: "train"}}
+
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(context.TODO(), req.NamespacedName, &v1.Pod{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the Pod to be deleted, got %v", err)
+	}
+	if err := r.Get(context.TODO(), req.NamespacedName, unit); err != nil {
+		t.Fatal(err)
+	}
+	location := "mem://alice/train/20211019-090000-1a2b3c4d.log"
+	if len(unit.Status.LogArchives) != 1 || unit.Status.LogArchives[0].Location != location || store[location] != "fake logs" {
+		t.Errorf("unexpected archives %+v in %v", unit.Status.LogArchives, store)
+	}
+}
@@ -0,0 +1,311 @@
+package unit
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/controllers/logging"
+	"github.com/cokeos/zero/controllers/metrics"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// NamespaceNameLabelKey Kubernetes 为每个命名空间自动设置的名称标签
+	NamespaceNameLabelKey = "kubernetes.io/metadata.name"
+
+	// DNSNamespace 集群 DNS 所在的命名空间
+	DNSNamespace = "kube-system"
+	DNSPort      = 53
+)
+
+// generateNetworkPolicy 只允许同一命名空间、allowedNamespaces 及经节点转发到 Tunnel 端口的访问
+func generateNetworkPolicy(unit *corev1.Unit, unitConfig configv1alpha1.UnitConfig, tunnels []corev1.Tunnel, allowedNamespaces, nodeCIDRs []string) *networkingv1.NetworkPolicy {
+	ingress := []networkingv1.NetworkPolicyIngressRule{
+		{
+			From: []networkingv1.NetworkPolicyPeer{
+				{
+					PodSelector: &metav1.LabelSelector{},
+				},
+			},
+		},
+	}
+	// SSH 网关、Ingress Controller 等所在的命名空间
+	if len(allowedNamespaces) > 0 {
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			From: []networkingv1.NetworkPolicyPeer{
+				{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{
+								Key:      NamespaceNameLabelKey,
+								Operator: metav1.LabelSelectorOpIn,
+								Values:   allowedNamespaces,
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+	// NodePort 与 LoadBalancer 的流量经节点转发，只放行来自节点的对外端口，其他命名空间的 Pod 仍无法访问
+	if ports := exposedPorts(tunnels); len(ports) > 0 && len(nodeCIDRs) > 0 {
+		peers := make([]networkingv1.NetworkPolicyPeer, 0, len(nodeCIDRs))
+		for _, cidr := range nodeCIDRs {
+			peers = append(peers, networkingv1.NetworkPolicyPeer{
+				IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+			})
+		}
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			From:  peers,
+			Ports: ports,
+		})
+	}
+
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: unit.Namespace,
+			Name:      unit.Name,
+			Labels: map[string]string{
+				unitConfig.LabelKey:     LabelValue,
+				unitConfig.UniqLabelKey: unit.Namespace + "." + unit.Name,
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					unitConfig.UniqLabelKey: unit.Namespace + "." + unit.Name,
+				},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     ingress,
+		},
+	}
+
+	if np := unit.Spec.NetworkPolicy; np != nil && np.RestrictEgress {
+		var (
+			udp = v1.ProtocolUDP
+			tcp = v1.ProtocolTCP
+			dns = intstr.FromInt(DNSPort)
+		)
+		egress := []networkingv1.NetworkPolicyEgressRule{
+			{
+				To: []networkingv1.NetworkPolicyPeer{
+					{
+						PodSelector: &metav1.LabelSelector{},
+					},
+				},
+			},
+			{
+				To: []networkingv1.NetworkPolicyPeer{
+					{
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								NamespaceNameLabelKey: DNSNamespace,
+							},
+						},
+					},
+				},
+				Ports: []networkingv1.NetworkPolicyPort{
+					{Protocol: &udp, Port: &dns},
+					{Protocol: &tcp, Port: &dns},
+				},
+			},
+		}
+		if len(np.AllowedEgressCIDRs) > 0 {
+			peers := make([]networkingv1.NetworkPolicyPeer, 0, len(np.AllowedEgressCIDRs))
+			for _, cidr := range np.AllowedEgressCIDRs {
+				peers = append(peers, networkingv1.NetworkPolicyPeer{
+					IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+				})
+			}
+			egress = append(egress, networkingv1.NetworkPolicyEgressRule{To: peers})
+		}
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		policy.Spec.Egress = egress
+	}
+	return policy
+}
+
+// exposedPorts 返回经 NodePort 或 LoadBalancer 对外暴露的 Pod 端口，ClusterIP 的 Tunnel 由 SSH 网关转发
+func exposedPorts(tunnels []corev1.Tunnel) []networkingv1.NetworkPolicyPort {
+	ports := make([]networkingv1.NetworkPolicyPort, 0)
+	for _, tunnel := range tunnels {
+		if tunnel.Spec.Type == v1.ServiceTypeClusterIP {
+			continue
+		}
+		for _, port := range tunnel.Spec.Ports {
+			protocol := port.Protocol
+			if protocol == "" {
+				protocol = v1.ProtocolTCP
+			}
+			target := port.TargetPort
+			if target.Type == intstr.Int && target.IntVal == 0 {
+				target = intstr.FromInt(int(port.Port))
+			}
+			ports = append(ports, networkingv1.NetworkPolicyPort{
+				Protocol: &protocol,
+				Port:     &target,
+			})
+		}
+	}
+	return ports
+}
+
+// syncNetworkPolicy 根据 Unit 及选中它的 Tunnel 创建或更新网络策略，关闭隔离时删除
+func (r *UnitReconciler) syncNetworkPolicy(ctx context.Context, unit *corev1.Unit) error {
+	policy := &networkingv1.NetworkPolicy{}
+	policyConfig := r.Config.Get().NetworkPolicy
+	if policyConfig.Disabled || (unit.Spec.NetworkPolicy != nil && unit.Spec.NetworkPolicy.Disabled) {
+		err := r.Get(ctx, client.ObjectKeyFromObject(unit), policy)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		return r.Delete(ctx, policy)
+	}
+
+	list := &corev1.TunnelList{}
+	if err := r.List(ctx, list, client.InNamespace(unit.Namespace)); err != nil {
+		return err
+	}
+	tunnels := make([]corev1.Tunnel, 0)
+	for _, tunnel := range list.Items {
+		if tunnel.Spec.UnitName == unit.Namespace+"."+unit.Name {
+			tunnels = append(tunnels, tunnel)
+		}
+	}
+
+	var nodeCIDRs []string
+	if len(exposedPorts(tunnels)) > 0 {
+		var err error
+		if nodeCIDRs, err = r.nodeCIDRs(ctx, policyConfig.NodeCIDRs); err != nil {
+			return err
+		}
+	}
+
+	desired := generateNetworkPolicy(unit, r.Config.Get().Unit, tunnels, policyConfig.AllowedNamespaces, nodeCIDRs)
+	policy.ObjectMeta = desired.ObjectMeta
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, policy, func() error {
+		policy.Labels = desired.Labels
+		policy.Spec = desired.Spec
+		return controllerutil.SetControllerReference(unit, policy, r.Scheme)
+	})
+	return err
+}
+
+// nodeCIDRs 返回配置的网段，未配置时使用各节点的 InternalIP 与 PodCIDR
+func (r *UnitReconciler) nodeCIDRs(ctx context.Context, configured []string) ([]string, error) {
+	if len(configured) > 0 {
+		return configured, nil
+	}
+	nodes := &v1.NodeList{}
+	if err := r.List(ctx, nodes); err != nil {
+		return nil, err
+	}
+	cidrs := make([]string, 0, 2*len(nodes.Items))
+	for i := range nodes.Items {
+		cidrs = append(cidrs, nodeSourceCIDRs(&nodes.Items[i])...)
+	}
+	return cidrs, nil
+}
+
+// nodeSourceCIDRs 经节点转发的流量的来源网段：InternalIP 的单地址网段，以及节点的 PodCIDR，
+// 许多 CNI 会将转发的流量 SNAT 为节点上隧道或网桥设备的地址，该地址属于 PodCIDR
+func nodeSourceCIDRs(node *v1.Node) []string {
+	cidrs := make([]string, 0)
+	for _, address := range node.Status.Addresses {
+		if address.Type != v1.NodeInternalIP {
+			continue
+		}
+		if ip := net.ParseIP(address.Address); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+			cidrs = append(cidrs, (&net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}).String())
+		}
+	}
+	podCIDRs := node.Spec.PodCIDRs
+	if len(podCIDRs) == 0 && node.Spec.PodCIDR != "" {
+		podCIDRs = []string{node.Spec.PodCIDR}
+	}
+	for _, cidr := range podCIDRs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil {
+			cidrs = append(cidrs, network.String())
+		}
+	}
+	return cidrs
+}
+
+// nodeCIDRsChanged 只有节点增删或来源网段变化时才需要重新生成网络策略，忽略节点状态的定期更新
+var nodeCIDRsChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		old, ok := e.ObjectOld.(*v1.Node)
+		if !ok {
+			return false
+		}
+		node, ok := e.ObjectNew.(*v1.Node)
+		if !ok {
+			return false
+		}
+		return !equality.Semantic.DeepEqual(nodeSourceCIDRs(old), nodeSourceCIDRs(node))
+	},
+}
+
+// nodeRequests 节点变化后重新生成对外暴露端口的 Unit 的网络策略，配置了 NodeCIDRs 时不受节点影响
+func (r *UnitReconciler) nodeRequests(obj client.Object) []reconcile.Request {
+	if len(r.Config.Get().NetworkPolicy.NodeCIDRs) > 0 {
+		return nil
+	}
+	list := &corev1.TunnelList{}
+	if err := r.List(context.TODO(), list); err != nil {
+		metrics.LogError(logging.Named("unit"), "unit", err, "Failed to list Tunnels")
+		return nil
+	}
+	seen := make(map[types.NamespacedName]bool)
+	requests := make([]reconcile.Request, 0)
+	for i := range list.Items {
+		if len(exposedPorts(list.Items[i:i+1])) == 0 {
+			continue
+		}
+		for _, request := range r.tunnelRequests(&list.Items[i]) {
+			if !seen[request.NamespacedName] {
+				seen[request.NamespacedName] = true
+				requests = append(requests, request)
+			}
+		}
+	}
+	return requests
+}
+
+// tunnelRequests Tunnel 变化后重新生成其 Unit 的网络策略
+func (r *UnitReconciler) tunnelRequests(obj client.Object) []reconcile.Request {
+	tunnel, ok := obj.(*corev1.Tunnel)
+	if !ok {
+		return nil
+	}
+	// UnitName 为 <namespace>.<name>
+	parts := strings.SplitN(tunnel.Spec.UnitName, ".", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: parts[0], Name: parts[1]}},
+	}
+}
@@ -0,0 +1,183 @@
+package unit
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/controllers/config"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// origin 访问 Unit 的一方，Namespace 为空表示集群外部或节点转发的流量
+type origin struct {
+	Namespace string
+	IP        string
+}
+
+// admits 按 NetworkPolicy 的语义判断 origin 能否访问 policy 所在命名空间 Pod 的 port 端口
+func admits(t *testing.T, policy *networkingv1.NetworkPolicy, from origin, port int) bool {
+	for _, rule := range policy.Spec.Ingress {
+		if !matchPorts(rule.Ports, port) {
+			continue
+		}
+		if len(rule.From) == 0 {
+			return true
+		}
+		for _, peer := range rule.From {
+			if matchPeer(t, policy.Namespace, peer, from) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchPorts(ports []networkingv1.NetworkPolicyPort, port int) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, p := range ports {
+		if p.Port == nil || p.Port.IntValue() == port {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPeer(t *testing.T, namespace string, peer networkingv1.NetworkPolicyPeer, from origin) bool {
+	if peer.IPBlock != nil {
+		_, cidr, err := net.ParseCIDR(peer.IPBlock.CIDR)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cidr.Contains(net.ParseIP(from.IP))
+	}
+	if from.Namespace == "" {
+		return false
+	}
+	if peer.NamespaceSelector == nil {
+		return from.Namespace == namespace
+	}
+	selector, err := metav1.LabelSelectorAsSelector(peer.NamespaceSelector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return selector.Matches(labels.Set{NamespaceNameLabelKey: from.Namespace})
+}
+
+func newNodePortTunnel() corev1.Tunnel {
+	return corev1.Tunnel{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"},
+		Spec: corev1.TunnelSpec{
+			UnitName: "alice.train",
+			Type:     v1.ServiceTypeNodePort,
+			Ports:    []v1.ServicePort{{Name: "ssh", Port: 2222, TargetPort: intstr.FromInt(22)}},
+		},
+	}
+}
+
+func TestGenerateNetworkPolicy(t *testing.T) {
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"}}
+	policy := generateNetworkPolicy(unit, newTestPodOptions().Unit, []corev1.Tunnel{newNodePortTunnel()}, []string{"zero-gateway"}, []string{"192.168.0.0/24"})
+	cases := []struct {
+		name     string
+		from     origin
+		port     int
+		expected bool
+	}{
+		{"same namespace", origin{Namespace: "alice", IP: "10.244.1.5"}, 8888, true},
+		{"gateway namespace", origin{Namespace: "zero-gateway", IP: "10.244.2.5"}, 22, true},
+		{"other namespace on the exposed port", origin{Namespace: "bob", IP: "10.244.3.5"}, 22, false},
+		{"other namespace on other ports", origin{Namespace: "bob", IP: "10.244.3.5"}, 8888, false},
+		{"node on the exposed port", origin{IP: "192.168.0.10"}, 22, true},
+		{"node on other ports", origin{IP: "192.168.0.10"}, 8888, false},
+		{"outside the node network", origin{IP: "172.16.0.1"}, 22, false},
+	}
+	for _, c := range cases {
+		if actual := admits(t, policy, c.from, c.port); actual != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, actual)
+		}
+	}
+	for _, rule := range policy.Spec.Ingress {
+		if len(rule.From) == 0 {
+			t.Errorf("expected every ingress rule to restrict its peers, got %+v", rule)
+		}
+	}
+
+	// 未找到节点地址时不放行对外端口
+	policy = generateNetworkPolicy(unit, newTestPodOptions().Unit, []corev1.Tunnel{newNodePortTunnel()}, nil, nil)
+	if admits(t, policy, origin{IP: "192.168.0.10"}, 22) || admits(t, policy, origin{Namespace: "bob", IP: "10.244.3.5"}, 22) {
+		t.Errorf("expected the exposed port to be closed without node CIDRs")
+	}
+}
+
+func TestNodeCIDRs(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu-1"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "192.168.0.10"},
+			{Type: v1.NodeInternalIP, Address: "fd00::10"},
+			{Type: v1.NodeExternalIP, Address: "1.2.3.4"},
+			{Type: v1.NodeHostName, Address: "gpu-1"},
+		}},
+		Spec: v1.NodeSpec{PodCIDR: "10.244.1.0/24", PodCIDRs: []string{"10.244.1.0/24", "fd00:244:1::/64"}},
+	}
+	r, _ := newTestReconciler(node)
+	cidrs, err := r.nodeCIDRs(context.TODO(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"192.168.0.10/32", "fd00::10/128", "10.244.1.0/24", "fd00:244:1::/64"}
+	if !reflect.DeepEqual(cidrs, expected) {
+		t.Errorf("expected %v, got %v", expected, cidrs)
+	}
+
+	if cidrs, _ := r.nodeCIDRs(context.TODO(), []string{"192.168.0.0/16"}); len(cidrs) != 1 || cidrs[0] != "192.168.0.0/16" {
+		t.Errorf("expected the configured CIDRs, got %v", cidrs)
+	}
+}
+
+func TestNodeRequests(t *testing.T) {
+	clusterIP := newNodePortTunnel()
+	clusterIP.Name, clusterIP.Spec.UnitName, clusterIP.Spec.Type = "notebook", "alice.notebook", v1.ServiceTypeClusterIP
+	second := newNodePortTunnel()
+	second.Name = "tensorboard"
+	tunnel := newNodePortTunnel()
+	r, _ := newTestReconciler(&tunnel, &second, &clusterIP)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "gpu-2"}}
+	requests := r.nodeRequests(node)
+	if len(requests) != 1 || requests[0].Name != "train" {
+		t.Errorf("expected the exposed Unit to be requeued once, got %v", requests)
+	}
+
+	updated := node.DeepCopy()
+	updated.ResourceVersion = "2"
+	updated.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+	if nodeCIDRsChanged.Update(event.UpdateEvent{ObjectOld: node, ObjectNew: updated}) {
+		t.Error("expected status updates to be ignored")
+	}
+	updated.Spec.PodCIDRs = []string{"10.244.2.0/24"}
+	if !nodeCIDRsChanged.Update(event.UpdateEvent{ObjectOld: node, ObjectNew: updated}) {
+		t.Error("expected a PodCIDR change to requeue Units")
+	}
+
+	store, err := config.NewStore("", func(c *configv1alpha1.ZeroConfig) {
+		c.NetworkPolicy.NodeCIDRs = []string{"192.168.0.0/16"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Config = store
+	if requests := r.nodeRequests(node); len(requests) != 0 {
+		t.Errorf("expected configured CIDRs to ignore nodes, got %v", requests)
+	}
+}
@@ -1,12 +1,16 @@
 package unit
 
 import (
+	"encoding/json"
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
 	corev1 "github.com/cokeos/zero/api/v1"
+	"hash/fnv"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/klog/v2"
+	"path"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -18,43 +22,49 @@ const (
 
 	DeafaultShmMountPath = "/dev/shm"
 
-	DefaultMountPath   = "/data"
-	DefaultGlusterPath = "/data"
+	DefaultMountPath = "/data"
 
-	LabelKey   = "cokeos.io/zero-managed"
+	// LabelValue Unit Pod 上 LabelKey 标签的取值，标签键来自配置文件
 	LabelValue = "true"
 
-	UniqLabelKey = "cokeos.io/zero-id"
-
-	NodeGPUModelKey = "cokeos.io/gpu-model"
+	// SpecHashAnnotation 生成 Pod 时 Unit 规格的摘要，用户修改 Unit 规格后据此重建 Pod
+	//
+	// 只覆盖用户填写的规格，工作区、管理员安全默认值与配置文件的变化只影响之后创建的 Pod。
+	SpecHashAnnotation = "cokeos.io/unit-spec-hash"
 
 	DefaultGPUNumber = "0"
 
-	DefaultGPUModel = "GTX-1660"
-	GPUModelTitan   = "GTX-Titan-Xp"
-	GPUModel3090    = "RTX-3090"
+	GPUModelTitan = "GTX-Titan-Xp"
+	GPUModel3090  = "RTX-3090"
 )
 
-func defaultGpuModelList() []string {
-	return []string{
-		DefaultGPUModel,
+func getPodImage(unit *corev1.Unit, registry string) string {
+	if unit.Spec.Image != "" {
+		return unit.Spec.Image
 	}
-}
-
-func getPodImage(unit *corev1.Unit) string {
 	if unit.Spec.GPUPolicy.GPU {
-		return "ccr.ccs.tencentyun.com/njupt-isl/" +
+		return registry + "/" +
 			unit.Spec.Framework.Name +
 			"-gpu:" +
 			unit.Spec.Framework.Version
 	}
-	return "ccr.ccs.tencentyun.com/njupt-isl/" +
+	return registry + "/" +
 		unit.Spec.Framework.Name +
 		"-cpu:" +
 		unit.Spec.Framework.Version
 }
 
-func generatePod(unit *corev1.Unit) *v1.Pod {
+// podOptions 由控制器决定的 Pod 参数
+type podOptions struct {
+	// WorkspaceClaim 项目工作区的 PVC，为空时挂载节点上的共享目录
+	WorkspaceClaim string
+	// Security 合并管理员配置后的安全选项
+	Security *corev1.UnitSecurity
+	// Unit 配置文件中的镜像仓库、GPU 型号、共享内存与工作区参数
+	Unit configv1alpha1.UnitConfig
+}
+
+func generatePod(unit *corev1.Unit, options podOptions) *v1.Pod {
 	// 环境变量检测
 	env := unit.Spec.Execution.Env
 	if len(env) == 0 {
@@ -69,7 +79,7 @@ func generatePod(unit *corev1.Unit) *v1.Pod {
 	if unit.Spec.GPUPolicy.GPU {
 
 		if unit.Spec.GPUPolicy.Model == "" {
-			model = defaultGpuModelList()
+			model = append(model, options.Unit.GPUModels...)
 		} else {
 			model = append(model, unit.Spec.GPUPolicy.Model)
 		}
@@ -81,7 +91,7 @@ func generatePod(unit *corev1.Unit) *v1.Pod {
 					{
 						MatchExpressions: []v1.NodeSelectorRequirement{
 							{
-								Key:      NodeGPUModelKey,
+								Key:      options.Unit.GPUModelLabel,
 								Operator: v1.NodeSelectorOpIn,
 								Values:   model,
 							},
@@ -93,15 +103,9 @@ func generatePod(unit *corev1.Unit) *v1.Pod {
 	}
 
 	// gpu 检测
-	gpu, err := resource.ParseQuantity(strconv.Itoa(unit.Spec.GPUPolicy.Number))
-	if err != nil {
-		klog.Error(err)
-	}
-	if !unit.Spec.GPUPolicy.GPU {
-		gpu, err = resource.ParseQuantity(DefaultGPUNumber)
-		if err != nil {
-			klog.Error(err)
-		}
+	gpu := resource.MustParse(DefaultGPUNumber)
+	if unit.Spec.GPUPolicy.GPU {
+		gpu = *resource.NewQuantity(int64(unit.Spec.GPUPolicy.Number), resource.DecimalSI)
 	}
 
 	// 端口检测
@@ -122,9 +126,77 @@ func generatePod(unit *corev1.Unit) *v1.Pod {
 	}
 
 	// 默认Shm 共享内存大小
-	shmSharedMemory := resource.MustParse("32Gi")
+	shmSharedMemory := options.Unit.ShmSize.DeepCopy()
+	if unit.Spec.ShmSize != nil {
+		shmSharedMemory = unit.Spec.ShmSize.DeepCopy()
+	}
+
+	// 生命周期
+	var activeDeadlineSeconds *int64
+	if lifeCycle := unit.Spec.LifeCycle; lifeCycle != nil && !lifeCycle.Forever && lifeCycle.Days > 0 {
+		seconds := int64(lifeCycle.Days) * 24 * 60 * 60
+		activeDeadlineSeconds = &seconds
+	}
+
+	volumeMounts := []v1.VolumeMount{
+		{
+			Name:      unit.Name + "-vol",
+			MountPath: DefaultMountPath,
+		},
+		{
+			Name:      unit.Name + "-shm",
+			MountPath: DeafaultShmMountPath,
+		},
+	}
+	workspace := v1.VolumeSource{
+		HostPath: &v1.HostPathVolumeSource{
+			Path: path.Join(options.Unit.WorkspaceRoot, unit.Namespace),
+		},
+	}
+	if options.WorkspaceClaim != "" {
+		workspace = v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+				ClaimName: options.WorkspaceClaim,
+			},
+		}
+	}
+	volumes := []v1.Volume{
+		{
+			Name:         unit.Name + "-vol",
+			VolumeSource: workspace,
+		},
+		{
+			Name: unit.Name + "-shm",
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{
+					Medium:    v1.StorageMediumMemory,
+					SizeLimit: &shmSharedMemory,
+				},
+			},
+		},
+	}
+
+	// Secret/ConfigMap 文件挂载
+	for i, file := range unit.Spec.Execution.Files {
+		name := unit.Name + "-file-" + strconv.Itoa(i)
+		volumeMounts = append(volumeMounts, v1.VolumeMount{
+			Name:      name,
+			MountPath: file.MountPath,
+			SubPath:   file.SubPath,
+			ReadOnly:  true,
+		})
+		volumes = append(volumes, v1.Volume{
+			Name: name,
+			VolumeSource: v1.VolumeSource{
+				Projected: &v1.ProjectedVolumeSource{
+					Sources:     fileSources(file),
+					DefaultMode: file.DefaultMode,
+				},
+			},
+		})
+	}
 
-	return &v1.Pod{
+	pod := &v1.Pod{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
 			Kind:       "Pod",
@@ -133,59 +205,98 @@ func generatePod(unit *corev1.Unit) *v1.Pod {
 			Namespace: unit.Namespace,
 			Name:      unit.Name,
 			Labels: map[string]string{
-				LabelKey:     LabelValue,
-				UniqLabelKey: unit.Namespace + "." + unit.Name,
+				options.Unit.LabelKey:     LabelValue,
+				options.Unit.UniqLabelKey: unit.Namespace + "." + unit.Name,
 			},
 		},
 		Spec: v1.PodSpec{
-			Affinity:      affinity,
-			RestartPolicy: v1.RestartPolicyNever,
+			Affinity:         affinity,
+			RestartPolicy:    v1.RestartPolicyNever,
+			ImagePullSecrets: unit.Spec.ImagePullSecrets,
+			// 超过生命周期后 Pod 被终止
+			ActiveDeadlineSeconds: activeDeadlineSeconds,
 			Containers: []v1.Container{
 				{
-					Name:    unit.Name,
-					Image:   getPodImage(unit),
-					Env:     env,
-					Ports:   ports,
-					Command: command,
-					Args:    args,
+					Name:            unit.Name,
+					Image:           getPodImage(unit, strings.TrimSuffix(options.Unit.Registry, "/")),
+					ImagePullPolicy: unit.Spec.ImagePullPolicy,
+					Env:             env,
+					EnvFrom:         unit.Spec.Execution.EnvFrom,
+					Ports:           ports,
+					Command:         command,
+					Args:            args,
 					Resources: v1.ResourceRequirements{
 						Limits: map[v1.ResourceName]resource.Quantity{
 							v1.ResourceCPU:           unit.Spec.ResourceList.Cpu().DeepCopy(),
 							v1.ResourceMemory:        unit.Spec.ResourceList.Memory().DeepCopy(),
 							corev1.ResourceNvidiaGPU: gpu,
 						},
+						Requests: resourceRequests(unit.Spec.ResourceRequests),
 					},
-					VolumeMounts: []v1.VolumeMount{
-						{
-							Name:      unit.Name + "-vol",
-							MountPath: DefaultMountPath,
-						},
-						{
-							Name:      unit.Name + "-shm",
-							MountPath: DeafaultShmMountPath,
-						},
-					},
-				},
-			},
-			Volumes: []v1.Volume{
-				{
-					Name: unit.Name + "-vol",
-					VolumeSource: v1.VolumeSource{
-						HostPath: &v1.HostPathVolumeSource{
-							Path: DefaultGlusterPath + "/" + unit.Namespace,
-						},
-					},
-				},
-				{
-					Name: unit.Name + "-shm",
-					VolumeSource: v1.VolumeSource{
-						EmptyDir: &v1.EmptyDirVolumeSource{
-							Medium:    v1.StorageMediumMemory,
-							SizeLimit: &shmSharedMemory,
-						},
-					},
+					VolumeMounts: volumeMounts,
 				},
 			},
+			Volumes: volumes,
 		},
 	}
+	applySecurity(pod, unit, options.Security)
+	pod.Annotations = map[string]string{
+		SpecHashAnnotation: unitSpecHash(&unit.Spec),
+	}
+	return pod
+}
+
+// fileSources 每个文件只有一个来源，webhook 拒绝同时指定 Secret 与 ConfigMap，
+// 绕过 webhook 时与引用检查一致只使用 Secret
+func fileSources(file corev1.FileProjection) []v1.VolumeProjection {
+	if file.Secret != nil {
+		return []v1.VolumeProjection{{Secret: file.Secret}}
+	}
+	if file.ConfigMap != nil {
+		return []v1.VolumeProjection{{ConfigMap: file.ConfigMap}}
+	}
+	return nil
+}
+
+// resourceRequests 只取 CPU 与内存，未设置的 requests 由 Kubernetes 按 limits 补全
+func resourceRequests(requests v1.ResourceList) v1.ResourceList {
+	var list v1.ResourceList
+	for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		if quantity, ok := requests[name]; ok {
+			if list == nil {
+				list = v1.ResourceList{}
+			}
+			list[name] = quantity.DeepCopy()
+		}
+	}
+	return list
+}
+
+// podImage 返回 Pod 容器的镜像及运行中容器解析出的 digest
+func podImage(pod *v1.Pod) (image, digest string) {
+	if len(pod.Spec.Containers) > 0 {
+		image = pod.Spec.Containers[0].Image
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if i := strings.LastIndex(status.ImageID, "@"); i >= 0 {
+			return image, status.ImageID[i+1:]
+		}
+		if strings.HasPrefix(status.ImageID, "sha256:") {
+			return image, status.ImageID
+		}
+	}
+	return image, ""
+}
+
+// unitSpecHash 影响 Pod 的 Unit 规格的摘要，挂起、空闲策略与网络策略不需要重建 Pod
+func unitSpecHash(spec *corev1.UnitSpec) string {
+	spec = spec.DeepCopy()
+	spec.Suspend = false
+	spec.IdlePolicy = nil
+	spec.NetworkPolicy = nil
+	// UnitSpec 总能序列化
+	data, _ := json.Marshal(spec)
+	hash := fnv.New32a()
+	_, _ = hash.Write(data)
+	return strconv.FormatUint(uint64(hash.Sum32()), 16)
 }
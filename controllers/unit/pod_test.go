@@ -0,0 +1,69 @@
+package unit
+
+import (
+	"strconv"
+	"testing"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestPodOptions() podOptions {
+	return podOptions{Unit: configv1alpha1.NewDefault().Unit}
+}
+
+// fileVolume 返回第 i 个挂载文件的卷
+func fileVolume(t *testing.T, pod *v1.Pod, i int) v1.Volume {
+	name := pod.Name + "-file-" + strconv.Itoa(i)
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == name {
+			return volume
+		}
+	}
+	t.Fatalf("volume %s not found", name)
+	return v1.Volume{}
+}
+
+func TestGeneratePodFiles(t *testing.T) {
+	secret := &v1.SecretProjection{LocalObjectReference: v1.LocalObjectReference{Name: "netrc"}}
+	configMap := &v1.ConfigMapProjection{LocalObjectReference: v1.LocalObjectReference{Name: "settings"}}
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"}}
+	unit.Spec.Execution.Files = []corev1.FileProjection{
+		{MountPath: "/root/.netrc", SubPath: ".netrc", Secret: secret},
+		{MountPath: "/etc/train", Secret: secret, ConfigMap: configMap},
+		{MountPath: "/etc/settings", ConfigMap: configMap},
+	}
+
+	pod := generatePod(unit, newTestPodOptions())
+	sources := fileVolume(t, pod, 0).Projected.Sources
+	if len(sources) != 1 || sources[0].Secret == nil || sources[0].ConfigMap != nil {
+		t.Errorf("expected a single Secret source, got %+v", sources)
+	}
+	// webhook 拒绝同时指定两个来源，绕过 webhook 时只使用 Secret
+	sources = fileVolume(t, pod, 1).Projected.Sources
+	if len(sources) != 1 || sources[0].Secret == nil || sources[0].ConfigMap != nil {
+		t.Errorf("expected only the Secret source, got %+v", sources)
+	}
+	sources = fileVolume(t, pod, 2).Projected.Sources
+	if len(sources) != 1 || sources[0].ConfigMap == nil || sources[0].Secret != nil {
+		t.Errorf("expected a single ConfigMap source, got %+v", sources)
+	}
+}
+
+func TestGeneratePodLabels(t *testing.T) {
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"}}
+	options := newTestPodOptions()
+	options.Unit.LabelKey = "example.com/managed"
+	options.Unit.UniqLabelKey = "example.com/id"
+
+	pod := generatePod(unit, options)
+	if len(pod.Labels) != 2 || pod.Labels["example.com/managed"] != LabelValue || pod.Labels["example.com/id"] != "alice.train" {
+		t.Errorf("expected the configured label keys, got %v", pod.Labels)
+	}
+	policy := generateNetworkPolicy(unit, options.Unit, nil, nil, nil)
+	if selector := policy.Spec.PodSelector.MatchLabels; len(selector) != 1 || selector["example.com/id"] != "alice.train" {
+		t.Errorf("expected the policy to select the Pod by the configured key, got %v", selector)
+	}
+}
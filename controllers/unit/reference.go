@@ -0,0 +1,151 @@
+package unit
+
+import (
+	"context"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	KindSecret    = "Secret"
+	KindConfigMap = "ConfigMap"
+
+	ReasonResolved = "Resolved"
+)
+
+type reference struct {
+	kind string
+	name string
+	// key 必须存在的键，为空时只要求对象存在
+	key string
+}
+
+// describe 返回引用在命名空间中的描述，例如 key token of Secret alice/netrc
+func (ref reference) describe(namespace string) string {
+	object := ref.kind + " " + namespace + "/" + ref.name
+	if ref.key == "" {
+		return object
+	}
+	return "key " + ref.key + " of " + object
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
+
+// executionReferences 收集 Execution 中必须存在的 Secret/ConfigMap 及其中的键
+func executionReferences(execution *corev1.Execution) []reference {
+	refs := make([]reference, 0)
+	for _, env := range execution.Env {
+		if env.ValueFrom == nil {
+			continue
+		}
+		if ref := env.ValueFrom.SecretKeyRef; ref != nil && !isOptional(ref.Optional) {
+			refs = append(refs, reference{kind: KindSecret, name: ref.Name, key: ref.Key})
+		}
+		if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil && !isOptional(ref.Optional) {
+			refs = append(refs, reference{kind: KindConfigMap, name: ref.Name, key: ref.Key})
+		}
+	}
+	for _, from := range execution.EnvFrom {
+		if ref := from.SecretRef; ref != nil && !isOptional(ref.Optional) {
+			refs = append(refs, reference{kind: KindSecret, name: ref.Name})
+		}
+		if ref := from.ConfigMapRef; ref != nil && !isOptional(ref.Optional) {
+			refs = append(refs, reference{kind: KindConfigMap, name: ref.Name})
+		}
+	}
+	for _, file := range execution.Files {
+		if ref := file.Secret; ref != nil && !isOptional(ref.Optional) {
+			refs = append(refs, projectionReferences(KindSecret, ref.Name, ref.Items)...)
+		} else if ref := file.ConfigMap; ref != nil && !isOptional(ref.Optional) {
+			refs = append(refs, projectionReferences(KindConfigMap, ref.Name, ref.Items)...)
+		}
+	}
+	return refs
+}
+
+// projectionReferences 指定 Items 时每个键都必须存在，否则卷无法挂载
+func projectionReferences(kind, name string, items []v1.KeyToPath) []reference {
+	refs := []reference{{kind: kind, name: name}}
+	for _, item := range items {
+		refs = append(refs, reference{kind: kind, name: name, key: item.Key})
+	}
+	return refs
+}
+
+// missingReference 返回第一个不存在的引用，全部存在时返回 nil
+//
+// 经 Reader 直接读取 API Server，只读取引用的对象，不为整个集群的 Secret 建立缓存。
+func (r *UnitReconciler) missingReference(ctx context.Context, unit *corev1.Unit) (*reference, error) {
+	keys := make(map[reference]map[string]bool)
+	for _, ref := range executionReferences(&unit.Spec.Execution) {
+		object := reference{kind: ref.kind, name: ref.name}
+		data, fetched := keys[object]
+		if !fetched {
+			var err error
+			if data, err = r.referencedKeys(ctx, unit.Namespace, object); err != nil {
+				return nil, err
+			}
+			keys[object] = data
+		}
+		if data == nil || (ref.key != "" && !data[ref.key]) {
+			ref := ref
+			if data == nil {
+				ref.key = ""
+			}
+			return &ref, nil
+		}
+	}
+	return nil, nil
+}
+
+// referencedKeys 返回 Secret/ConfigMap 中的键，对象不存在时返回 nil
+func (r *UnitReconciler) referencedKeys(ctx context.Context, namespace string, ref reference) (map[string]bool, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: ref.name}
+	keys := make(map[string]bool)
+	if ref.kind == KindConfigMap {
+		configMap := &v1.ConfigMap{}
+		if err := r.Reader.Get(ctx, key, configMap); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		for k := range configMap.Data {
+			keys[k] = true
+		}
+		for k := range configMap.BinaryData {
+			keys[k] = true
+		}
+		return keys, nil
+	}
+	secret := &v1.Secret{}
+	if err := r.Reader.Get(ctx, key, secret); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	for k := range secret.Data {
+		keys[k] = true
+	}
+	return keys, nil
+}
+
+// setReferencesCondition 根据引用检查结果设置 ReferencesResolved 条件，返回条件是否发生变化
+func setReferencesCondition(unit *corev1.Unit, missing *reference) bool {
+	condition := metav1.Condition{
+		Type:    corev1.ConditionReferencesResolved,
+		Status:  metav1.ConditionTrue,
+		Reason:  ReasonResolved,
+		Message: "all referenced secrets and configmaps exist",
+	}
+	if missing != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = missing.kind + "NotFound"
+		if missing.key != "" {
+			condition.Reason = missing.kind + "KeyNotFound"
+		}
+		condition.Message = missing.describe(unit.Namespace) + " not found"
+	}
+	return setCondition(unit, condition)
+}
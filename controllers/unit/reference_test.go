@@ -0,0 +1,84 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMissingReference(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "netrc"},
+		Data:       map[string][]byte{".netrc": []byte("machine example.com")},
+	}
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "settings"},
+		Data:       map[string]string{"epochs": "10"},
+		BinaryData: map[string][]byte{"weights": {0}},
+	}
+	optional := true
+	cases := []struct {
+		name      string
+		execution corev1.Execution
+		expected  string
+	}{
+		{"all present", corev1.Execution{
+			Env: []v1.EnvVar{
+				{Name: "NETRC", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: "netrc"}, Key: ".netrc"}}},
+				{Name: "EPOCHS", ValueFrom: &v1.EnvVarSource{ConfigMapKeyRef: &v1.ConfigMapKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: "settings"}, Key: "epochs"}}},
+			},
+			Files: []corev1.FileProjection{{MountPath: "/etc/train", ConfigMap: &v1.ConfigMapProjection{
+				LocalObjectReference: v1.LocalObjectReference{Name: "settings"},
+				Items:                []v1.KeyToPath{{Key: "weights", Path: "weights.bin"}}}}},
+		}, ""},
+		{"missing secret", corev1.Execution{
+			EnvFrom: []v1.EnvFromSource{{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "token"}}}},
+		}, "Secret alice/token"},
+		{"missing env key", corev1.Execution{
+			Env: []v1.EnvVar{{Name: "TOKEN", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+				LocalObjectReference: v1.LocalObjectReference{Name: "netrc"}, Key: "token"}}}},
+		}, "key token of Secret alice/netrc"},
+		{"missing item key", corev1.Execution{
+			Files: []corev1.FileProjection{{MountPath: "/etc/train", ConfigMap: &v1.ConfigMapProjection{
+				LocalObjectReference: v1.LocalObjectReference{Name: "settings"},
+				Items:                []v1.KeyToPath{{Key: "lr", Path: "lr"}}}}},
+		}, "key lr of ConfigMap alice/settings"},
+		{"optional key", corev1.Execution{
+			Env: []v1.EnvVar{{Name: "TOKEN", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+				LocalObjectReference: v1.LocalObjectReference{Name: "netrc"}, Key: "token", Optional: &optional}}}},
+		}, ""},
+		{"items of a missing secret", corev1.Execution{
+			Files: []corev1.FileProjection{{MountPath: "/root/.ssh", Secret: &v1.SecretProjection{
+				LocalObjectReference: v1.LocalObjectReference{Name: "ssh"},
+				Items:                []v1.KeyToPath{{Key: "id_rsa", Path: "id_rsa"}}}}},
+		}, "Secret alice/ssh"},
+	}
+	r, _ := newTestReconciler(secret, configMap)
+	for _, c := range cases {
+		unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"}}
+		unit.Spec.Execution = c.execution
+		missing, err := r.missingReference(context.TODO(), unit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual := ""
+		if missing != nil {
+			actual = missing.describe(unit.Namespace)
+		}
+		if actual != c.expected {
+			t.Errorf("%s: expected %q missing, got %q", c.name, c.expected, actual)
+		}
+	}
+
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"}}
+	setReferencesCondition(unit, &reference{kind: KindSecret, name: "netrc", key: "token"})
+	if condition := unit.Status.Conditions[0]; condition.Reason != "SecretKeyNotFound" ||
+		condition.Message != "key token of Secret alice/netrc not found" {
+		t.Errorf("unexpected condition %+v", condition)
+	}
+}
@@ -0,0 +1,134 @@
+package unit
+
+import (
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+const (
+	DefaultTmpMountPath = "/tmp"
+)
+
+// effectiveSecurity 合并 Unit 与控制器配置的安全选项，均未设置时返回 nil
+func effectiveSecurity(security *corev1.UnitSecurity, config configv1alpha1.UnitConfig) *corev1.UnitSecurity {
+	if security == nil {
+		security = &corev1.UnitSecurity{}
+	}
+	var merged *corev1.UnitSecurity
+	if config.EnforceSecurity {
+		merged = mergeSecurity(&config.Security, security)
+		merged.DropCapabilities = unionCapabilities(config.Security.DropCapabilities, security.DropCapabilities)
+	} else {
+		merged = mergeSecurity(security, &config.Security)
+	}
+	if equality.Semantic.DeepEqual(merged, &corev1.UnitSecurity{}) {
+		return nil
+	}
+	return merged
+}
+
+// mergeSecurity 以 primary 为准，未设置的字段取 fallback
+func mergeSecurity(primary, fallback *corev1.UnitSecurity) *corev1.UnitSecurity {
+	merged := primary.DeepCopy()
+	fallback = fallback.DeepCopy()
+	if merged.RunAsUser == nil {
+		merged.RunAsUser = fallback.RunAsUser
+	}
+	if merged.RunAsGroup == nil {
+		merged.RunAsGroup = fallback.RunAsGroup
+	}
+	if merged.FSGroup == nil {
+		merged.FSGroup = fallback.FSGroup
+	}
+	if merged.RunAsNonRoot == nil {
+		merged.RunAsNonRoot = fallback.RunAsNonRoot
+	}
+	if len(merged.DropCapabilities) == 0 {
+		merged.DropCapabilities = fallback.DropCapabilities
+	}
+	if merged.AllowPrivilegeEscalation == nil {
+		merged.AllowPrivilegeEscalation = fallback.AllowPrivilegeEscalation
+	}
+	if merged.ReadOnlyRootFilesystem == nil {
+		merged.ReadOnlyRootFilesystem = fallback.ReadOnlyRootFilesystem
+	}
+	if merged.SeccompProfile == nil {
+		merged.SeccompProfile = fallback.SeccompProfile
+	}
+	return merged
+}
+
+func unionCapabilities(a, b []v1.Capability) []v1.Capability {
+	seen := make(map[v1.Capability]bool)
+	union := make([]v1.Capability, 0, len(a)+len(b))
+	for _, capability := range append(append([]v1.Capability{}, a...), b...) {
+		if !seen[capability] {
+			seen[capability] = true
+			union = append(union, capability)
+		}
+	}
+	if len(union) == 0 {
+		return nil
+	}
+	return union
+}
+
+// applySecurity 将安全选项写入 Pod
+//
+// 控制器不再以 root 调整工作目录的属主：命名空间内的 Unit 共享同一个工作目录，
+// 按各自的 UID chown 会让属主来回变化，也无法通过 restricted 级别的 Pod Security。
+// 非 root 用户运行时 FSGroup 默认取 RunAsGroup，kubelet 据此为 PVC 等支持的卷设置属组，
+// 且只在卷根目录的属组不一致时才递归调整；hostPath 工作目录不受 FSGroup 影响，
+// 需要管理员预先将 <WorkspaceRoot>/<namespace> 设为该组可写（例如 chgrp 后 chmod 2775）。
+func applySecurity(pod *v1.Pod, unit *corev1.Unit, security *corev1.UnitSecurity) {
+	if security == nil {
+		return
+	}
+	fsGroup := security.FSGroup
+	if fsGroup == nil && security.RunAsUser != nil && *security.RunAsUser != 0 {
+		fsGroup = security.RunAsGroup
+	}
+	if security.RunAsUser != nil || security.RunAsGroup != nil || fsGroup != nil ||
+		security.RunAsNonRoot != nil || security.SeccompProfile != nil {
+		pod.Spec.SecurityContext = &v1.PodSecurityContext{
+			RunAsUser:      security.RunAsUser,
+			RunAsGroup:     security.RunAsGroup,
+			FSGroup:        fsGroup,
+			RunAsNonRoot:   security.RunAsNonRoot,
+			SeccompProfile: security.SeccompProfile,
+		}
+		if fsGroup != nil {
+			policy := v1.FSGroupChangeOnRootMismatch
+			pod.Spec.SecurityContext.FSGroupChangePolicy = &policy
+		}
+	}
+
+	container := &pod.Spec.Containers[0]
+	if len(security.DropCapabilities) > 0 || security.AllowPrivilegeEscalation != nil || security.ReadOnlyRootFilesystem != nil {
+		container.SecurityContext = &v1.SecurityContext{
+			AllowPrivilegeEscalation: security.AllowPrivilegeEscalation,
+			ReadOnlyRootFilesystem:   security.ReadOnlyRootFilesystem,
+		}
+		if len(security.DropCapabilities) > 0 {
+			container.SecurityContext.Capabilities = &v1.Capabilities{
+				Drop: security.DropCapabilities,
+			}
+		}
+	}
+	// 只读根文件系统下 /tmp 仍需可写
+	if security.ReadOnlyRootFilesystem != nil && *security.ReadOnlyRootFilesystem {
+		name := unit.Name + "-tmp"
+		container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+			Name:      name,
+			MountPath: DefaultTmpMountPath,
+		})
+		pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+			Name: name,
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{},
+			},
+		})
+	}
+}
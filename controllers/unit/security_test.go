@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"reflect"
+	"testing"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int64Ptr(i int64) *int64 { return &i }
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestEffectiveSecurity(t *testing.T) {
+	defaults := corev1.UnitSecurity{
+		RunAsUser:        int64Ptr(1000),
+		RunAsNonRoot:     boolPtr(true),
+		DropCapabilities: []v1.Capability{"NET_RAW"},
+	}
+	unit := &corev1.UnitSecurity{
+		RunAsUser:        int64Ptr(0),
+		RunAsGroup:       int64Ptr(100),
+		DropCapabilities: []v1.Capability{"SYS_ADMIN", "NET_RAW"},
+	}
+	cases := []struct {
+		name     string
+		security *corev1.UnitSecurity
+		config   configv1alpha1.UnitConfig
+		expected *corev1.UnitSecurity
+	}{
+		{"nothing set", nil, configv1alpha1.UnitConfig{}, nil},
+		{"defaults only", nil, configv1alpha1.UnitConfig{Security: defaults}, &defaults},
+		{"unit only", unit, configv1alpha1.UnitConfig{}, unit},
+		{"unit overrides defaults", unit, configv1alpha1.UnitConfig{Security: defaults}, &corev1.UnitSecurity{
+			RunAsUser:        int64Ptr(0),
+			RunAsGroup:       int64Ptr(100),
+			RunAsNonRoot:     boolPtr(true),
+			DropCapabilities: []v1.Capability{"SYS_ADMIN", "NET_RAW"},
+		}},
+		{"defaults enforced", unit, configv1alpha1.UnitConfig{Security: defaults, EnforceSecurity: true}, &corev1.UnitSecurity{
+			RunAsUser:        int64Ptr(1000),
+			RunAsGroup:       int64Ptr(100),
+			RunAsNonRoot:     boolPtr(true),
+			DropCapabilities: []v1.Capability{"NET_RAW", "SYS_ADMIN"},
+		}},
+		{"nothing to enforce", nil, configv1alpha1.UnitConfig{EnforceSecurity: true}, nil},
+	}
+	for _, c := range cases {
+		if actual := effectiveSecurity(c.security, c.config); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %+v, got %+v", c.name, c.expected, actual)
+		}
+	}
+	if *unit.RunAsUser != 0 || len(unit.DropCapabilities) != 2 {
+		t.Errorf("expected the Unit security not to be modified, got %+v", unit)
+	}
+}
+
+func TestApplySecurity(t *testing.T) {
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"}}
+	options := newTestPodOptions()
+	options.Security = &corev1.UnitSecurity{
+		RunAsUser:              int64Ptr(1000),
+		RunAsGroup:             int64Ptr(100),
+		DropCapabilities:       []v1.Capability{"ALL"},
+		ReadOnlyRootFilesystem: boolPtr(true),
+		SeccompProfile:         &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+	}
+	pod := generatePod(unit, options)
+
+	securityContext := pod.Spec.SecurityContext
+	if securityContext == nil || *securityContext.RunAsUser != 1000 || *securityContext.RunAsGroup != 100 || securityContext.SeccompProfile == nil ||
+		securityContext.FSGroup == nil || *securityContext.FSGroup != 100 ||
+		*securityContext.FSGroupChangePolicy != v1.FSGroupChangeOnRootMismatch {
+		t.Errorf("unexpected pod security context %+v", securityContext)
+	}
+	container := pod.Spec.Containers[0]
+	if container.SecurityContext == nil || !*container.SecurityContext.ReadOnlyRootFilesystem ||
+		!reflect.DeepEqual(container.SecurityContext.Capabilities.Drop, []v1.Capability{"ALL"}) {
+		t.Errorf("unexpected container security context %+v", container.SecurityContext)
+	}
+	tmp := false
+	for _, mount := range container.VolumeMounts {
+		tmp = tmp || mount.MountPath == DefaultTmpMountPath
+	}
+	if !tmp {
+		t.Errorf("expected a writable %s with a read-only root filesystem", DefaultTmpMountPath)
+	}
+	// 不再以 root 调整工作目录的属主，Pod 可以通过 restricted 级别的 Pod Security
+	if len(pod.Spec.InitContainers) != 0 {
+		t.Errorf("expected no init containers, got %+v", pod.Spec.InitContainers)
+	}
+
+	// 显式设置的 FSGroup 优先，root 用户不默认设置 FSGroup，未设置安全选项时保持镜像默认
+	options.Security.FSGroup = int64Ptr(2000)
+	if pod = generatePod(unit, options); *pod.Spec.SecurityContext.FSGroup != 2000 {
+		t.Errorf("expected fsGroup 2000, got %+v", pod.Spec.SecurityContext)
+	}
+	options.Security = &corev1.UnitSecurity{RunAsUser: int64Ptr(0), RunAsGroup: int64Ptr(100)}
+	if pod = generatePod(unit, options); pod.Spec.SecurityContext.FSGroup != nil || pod.Spec.Containers[0].SecurityContext != nil {
+		t.Errorf("unexpected pod for root %+v", pod.Spec)
+	}
+	options.Security = nil
+	if pod = generatePod(unit, options); pod.Spec.SecurityContext != nil || len(pod.Spec.InitContainers) != 0 {
+		t.Errorf("expected no security context, got %+v", pod.Spec.SecurityContext)
+	}
+}
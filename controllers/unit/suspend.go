@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ActiveResolution 运行中的 Pod 累计满这么久才写回 ActiveDuration，避免每次同步都更新 Unit
+const ActiveResolution = time.Minute
+
+// updateSuspendStatus 在挂起/恢复发生时累计挂起时长并切换 Phase，挂起前将 Pod 的运行时长全部计入，返回状态是否发生变化
+//
+// 运行时长只统计 pod 中容器实际运行的时间，等待调度与拉取镜像的 Pending 时间既不计入运行也不计入挂起。
+func updateSuspendStatus(unit *corev1.Unit, pod *v1.Pod, now metav1.Time) bool {
+	suspended := unit.Status.Phase == corev1.UnitSuspended
+	if suspended == unit.Spec.Suspend {
+		return false
+	}
+
+	last := unit.CreationTimestamp
+	if unit.Status.LastSuspendTransitionTime != nil {
+		last = *unit.Status.LastSuspendTransitionTime
+	}
+	if suspended {
+		unit.Status.SuspendedDuration.Duration += elapsed(last.Time, now.Time)
+		unit.Status.Phase = v1.PodPending
+	} else {
+		accumulateActive(unit, pod, now, true)
+		unit.Status.Phase = corev1.UnitSuspended
+	}
+	unit.Status.LastSuspendTransitionTime = &now
+	return true
+}
+
+// accumulateActive 将 pod 自上次统计以来的运行时间计入 ActiveDuration，返回状态是否发生变化
+//
+// 已结束的 Pod 立即计入；仍在运行时不足 ActiveResolution 不写回，force 为 true 时总是计入，
+// 用于挂起或重建 Pod 之前。ActiveDurationTime 记录统计截止的时间，同一段时间不会重复计入。
+func accumulateActive(unit *corev1.Unit, pod *v1.Pod, now metav1.Time, force bool) bool {
+	start, end, ok := runningPeriod(pod, now.Time)
+	if !ok {
+		return false
+	}
+	for _, t := range []*metav1.Time{unit.Status.ActiveDurationTime, unit.Status.LastSuspendTransitionTime} {
+		if t != nil && start.Before(t.Time) {
+			start = t.Time
+		}
+	}
+	d := elapsed(start, end)
+	running := end.Equal(now.Time)
+	if d == 0 || (running && !force && d < ActiveResolution) {
+		return false
+	}
+	unit.Status.ActiveDuration.Duration += d
+	unit.Status.ActiveDurationTime = &metav1.Time{Time: end}
+	return true
+}
+
+// runningPeriod 容器开始运行到结束（仍在运行时为 now）的时间段，容器从未运行时返回 false
+func runningPeriod(pod *v1.Pod, now time.Time) (start, end time.Time, ok bool) {
+	if pod == nil {
+		return start, end, false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		switch {
+		case status.State.Running != nil:
+			return status.State.Running.StartedAt.Time, now, true
+		case status.State.Terminated != nil && !status.State.Terminated.StartedAt.IsZero():
+			return status.State.Terminated.StartedAt.Time, status.State.Terminated.FinishedAt.Time, true
+		}
+	}
+	return start, end, false
+}
+
+func elapsed(from, to time.Time) time.Duration {
+	if d := to.Sub(from); d > 0 {
+		return d
+	}
+	return 0
+}
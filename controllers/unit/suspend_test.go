@@ -0,0 +1,132 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/controllers/events"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// conflictClient 更新状态时返回冲突
+type conflictClient struct {
+	client.Client
+}
+
+func (c *conflictClient) Status() client.StatusWriter {
+	return conflictStatusWriter{}
+}
+
+type conflictStatusWriter struct{}
+
+func (conflictStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return apierrors.NewConflict(schema.GroupResource{Group: "core.cokeos.io", Resource: "units"}, obj.GetName(), nil)
+}
+
+func (conflictStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
+
+func TestUpdateSuspendStatus(t *testing.T) {
+	created := time.Date(2021, 10, 19, 9, 0, 0, 0, time.UTC)
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)}}
+	unit.Status.Phase = v1.PodRunning
+	// 拉取镜像用了 10 分钟，运行 1 小时后挂起
+	pod := &v1.Pod{Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+		State: v1.ContainerState{Running: &v1.ContainerStateRunning{StartedAt: metav1.NewTime(created.Add(10 * time.Minute))}},
+	}}}}
+
+	if updateSuspendStatus(unit, pod, metav1.NewTime(created.Add(70*time.Minute))) {
+		t.Fatal("expected no change before suspend")
+	}
+	unit.Spec.Suspend = true
+	if !updateSuspendStatus(unit, pod, metav1.NewTime(created.Add(70*time.Minute))) {
+		t.Fatal("expected the Unit to be suspended")
+	}
+	if unit.Status.Phase != corev1.UnitSuspended || unit.Status.ActiveDuration.Duration != time.Hour {
+		t.Errorf("expected an hour of active time, got %v in phase %s", unit.Status.ActiveDuration.Duration, unit.Status.Phase)
+	}
+
+	// 挂起 2 小时后恢复，新的 Pod 未运行就再次挂起
+	unit.Spec.Suspend = false
+	if !updateSuspendStatus(unit, nil, metav1.NewTime(created.Add(190*time.Minute))) {
+		t.Fatal("expected the Unit to be resumed")
+	}
+	if unit.Status.Phase != v1.PodPending || unit.Status.SuspendedDuration.Duration != 2*time.Hour {
+		t.Errorf("expected two hours of suspended time, got %v in phase %s", unit.Status.SuspendedDuration.Duration, unit.Status.Phase)
+	}
+	unit.Spec.Suspend = true
+	pending := &v1.Pod{Status: v1.PodStatus{Phase: v1.PodPending}}
+	if !updateSuspendStatus(unit, pending, metav1.NewTime(created.Add(200*time.Minute))) {
+		t.Fatal("expected the Unit to be suspended")
+	}
+	if unit.Status.ActiveDuration.Duration != time.Hour {
+		t.Errorf("expected Pending time not to be counted, got %v", unit.Status.ActiveDuration.Duration)
+	}
+}
+
+func TestAccumulateActive(t *testing.T) {
+	created := time.Date(2021, 10, 19, 9, 0, 0, 0, time.UTC)
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)}}
+	unit.Status.Phase = v1.PodRunning
+	started := metav1.NewTime(created.Add(10 * time.Minute))
+	pod := &v1.Pod{Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+		State: v1.ContainerState{Running: &v1.ContainerStateRunning{StartedAt: started}},
+	}}}}
+
+	// 运行中不足一分钟不写回，之后按统计截止时间累计而不重复
+	if accumulateActive(unit, pod, metav1.NewTime(started.Add(30*time.Second)), false) {
+		t.Error("expected less than a minute not to be written back")
+	}
+	if !accumulateActive(unit, pod, metav1.NewTime(started.Add(2*time.Minute)), false) ||
+		unit.Status.ActiveDuration.Duration != 2*time.Minute {
+		t.Errorf("expected two minutes of active time, got %v", unit.Status.ActiveDuration.Duration)
+	}
+	if !accumulateActive(unit, pod, metav1.NewTime(started.Add(150*time.Second)), true) ||
+		unit.Status.ActiveDuration.Duration != 150*time.Second {
+		t.Errorf("expected a forced update to count the remainder, got %v", unit.Status.ActiveDuration.Duration)
+	}
+
+	// Pod 结束后立即计入到结束时间，再次同步不变
+	pod.Status.ContainerStatuses[0].State = v1.ContainerState{Terminated: &v1.ContainerStateTerminated{
+		StartedAt: started, FinishedAt: metav1.NewTime(started.Add(3 * time.Minute)),
+	}}
+	if !accumulateActive(unit, pod, metav1.NewTime(started.Add(time.Hour)), false) ||
+		unit.Status.ActiveDuration.Duration != 3*time.Minute {
+		t.Errorf("expected the finished Pod to be counted, got %v", unit.Status.ActiveDuration.Duration)
+	}
+	if accumulateActive(unit, pod, metav1.NewTime(started.Add(2*time.Hour)), false) {
+		t.Error("expected the finished Pod not to be counted twice")
+	}
+	if !unit.Status.ActiveDurationTime.Time.Equal(started.Add(3 * time.Minute)) {
+		t.Errorf("unexpected active duration time %v", unit.Status.ActiveDurationTime)
+	}
+}
+
+func TestReconcileSuspendConflict(t *testing.T) {
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"}}
+	unit.Spec.Suspend = true
+	r, recorder := newTestReconciler(unit, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"}})
+	r.Client = &conflictClient{Client: r.Client}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "alice", Name: "train"}}
+
+	if _, err := r.Reconcile(context.TODO(), req); !apierrors.IsConflict(err) {
+		t.Fatalf("expected a conflict to be returned for requeue, got %v", err)
+	}
+	if e := nextEvent(t, recorder); !strings.HasPrefix(e, "Warning "+events.ReasonConflict) {
+		t.Errorf("unexpected event %q", e)
+	}
+	// 状态未保存前不删除 Pod，重试时仍能统计运行时长
+	if err := r.Get(context.TODO(), req.NamespacedName, &v1.Pod{}); err != nil {
+		t.Errorf("expected the Pod to be kept, got %v", err)
+	}
+}
@@ -18,37 +18,62 @@ package unit
 
 import (
 	"context"
+	"github.com/cokeos/zero/controllers/config"
+	"github.com/cokeos/zero/controllers/events"
+	"github.com/cokeos/zero/controllers/logging"
+	"github.com/cokeos/zero/controllers/metrics"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/klog/v2"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	corev1 "github.com/cokeos/zero/api/v1"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // UnitReconciler reconciles a Unit object
 type UnitReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Recorder 记录 Unit 生命周期与错误的事件
+	Recorder record.EventRecorder
+	// LogArchiver 归档 Pod 的日志，为空时不归档
+	LogArchiver *LogArchiver
+	// Config 当前生效的配置，为空时使用默认配置
+	Config *config.Store
+	// Reader 直接读取 API Server，引用的 Secret/ConfigMap 不经过 Manager 的缓存
+	Reader client.Reader
 }
 
 //+kubebuilder:rbac:groups=core.cokeos.io,resources=units,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.cokeos.io,resources=units/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core.cokeos.io,resources=units/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets;configmaps,verbs=get
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=tunnels,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=pods/log,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.10.0/pkg/reconcile
 func (r *UnitReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, log := logging.Reconcile(ctx, "Unit")
 	var (
 		pod  = &v1.Pod{}
 		unit = &corev1.Unit{}
@@ -57,64 +82,185 @@ func (r *UnitReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	// Unit 查询
 	unitErr := r.Get(ctx, req.NamespacedName, unit)
 	podErr := r.Get(ctx, req.NamespacedName, pod)
+	if podErr != nil && !apierrors.IsNotFound(podErr) {
+		metrics.LogError(log, "unit", podErr, "Failed to get Pod")
+		return ctrl.Result{}, podErr
+	}
+	podExists := podErr == nil
 
 	// 删除逻辑
 	if unitErr != nil {
-		if apierrors.IsNotFound(unitErr) {
-			if err := r.Delete(ctx, pod); err != nil {
-				klog.Error(err)
+		if !apierrors.IsNotFound(unitErr) {
+			metrics.LogError(log, "unit", unitErr, "Failed to get Unit")
+			return ctrl.Result{}, unitErr
+		}
+		if podExists {
+			r.archiveBeforeDelete(ctx, nil, pod)
+			if err := r.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+				metrics.LogError(log, "unit", err, "Failed to delete Pod of deleted Unit")
+				return ctrl.Result{}, err
 			}
-		} else {
-			klog.Error(unitErr)
+			log.V(logging.Debug).Info("Deleted Pod of deleted Unit")
 		}
 		return ctrl.Result{}, nil
 	}
+	ctx, log = logging.WithObject(ctx, unit)
 	if unit.DeletionTimestamp != nil {
-		if err := r.Delete(ctx, pod); err != nil {
-			klog.Error(err)
+		if podExists && pod.DeletionTimestamp == nil {
+			r.archiveBeforeDelete(ctx, unit, pod)
+			if err := r.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, events.Warning(ctx, r.Recorder, "unit", unit, ReasonDeleteFailed, err)
+			}
+			r.Recorder.Eventf(unit, v1.EventTypeNormal, ReasonDeleting, "Deleting Pod %s", pod.Name)
+			log.V(logging.Debug).Info("Deleting Pod of terminating Unit")
 		}
 		return ctrl.Result{}, nil
 	}
 
-	// 创建逻辑
-	if podErr != nil {
-		if apierrors.IsNotFound(podErr) {
-			pod = generatePod(unit)
-			return ctrl.Result{}, r.Create(ctx, pod)
-		} else {
-			klog.Error(podErr)
+	// 网络隔离，策略通过 OwnerReference 随 Unit 一起回收，未生效前不创建 Pod
+	if err := r.syncNetworkPolicy(ctx, unit); err != nil {
+		return ctrl.Result{}, events.Warning(ctx, r.Recorder, "unit", unit, ReasonNetworkPolicy, err)
+	}
+
+	// 挂起逻辑，Tunnel 的 Service 与 NodePort 保留，Pod 删除后 Endpoints 随之释放
+	var current *v1.Pod
+	if podExists && pod.DeletionTimestamp == nil {
+		current = pod
+	}
+	now := metav1.Now()
+	changed := updateSuspendStatus(unit, current, now)
+	if accumulateActive(unit, current, now, false) {
+		changed = true
+	}
+	// 创建者由准入 webhook 写入注解，状态中保留一份便于查询
+	if createdBy := unit.Annotations[corev1.CreatedByAnnotation]; unit.Status.CreatedBy != createdBy {
+		unit.Status.CreatedBy = createdBy
+		changed = true
+	}
+	if changed {
+		if err := r.Status().Update(ctx, unit); err != nil {
+			return ctrl.Result{}, events.Warning(ctx, r.Recorder, "unit", unit, ReasonUpdateFailed, err)
+		}
+	}
+	if unit.Spec.Suspend {
+		if podExists && pod.DeletionTimestamp == nil {
+			r.archiveBeforeDelete(ctx, unit, pod)
+			if err := r.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, events.Warning(ctx, r.Recorder, "unit", unit, ReasonDeleteFailed, err)
+			}
+			r.Recorder.Eventf(unit, v1.EventTypeNormal, ReasonSuspended, "Suspended, deleted Pod %s", pod.Name)
+			log.Info("Suspended Unit")
 		}
+		return ctrl.Result{}, nil
+	}
+	// 旧 Pod 仍在终止中，等待其删除后再重建
+	if podExists && pod.DeletionTimestamp != nil {
+		log.V(logging.Trace).Info("Waiting for the old Pod to terminate")
+		return ctrl.Result{RequeueAfter: r.Config.Get().SyncPeriods.Unit.Duration}, nil
+	}
+	// 用户修改 Unit 规格后重建 Pod，没有摘要的旧 Pod 保持不变
+	if hash, ok := pod.Annotations[SpecHashAnnotation]; podExists && ok && hash != unitSpecHash(&unit.Spec) {
+		// 删除前计入旧 Pod 的运行时长
+		if accumulateActive(unit, pod, metav1.Now(), true) {
+			if err := r.Status().Update(ctx, unit); err != nil {
+				return ctrl.Result{}, events.Warning(ctx, r.Recorder, "unit", unit, ReasonUpdateFailed, err)
+			}
+		}
+		r.archiveBeforeDelete(ctx, unit, pod)
+		if err := r.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, events.Warning(ctx, r.Recorder, "unit", unit, ReasonDeleteFailed, err)
+		}
+		r.Recorder.Eventf(unit, v1.EventTypeNormal, ReasonRecreating, "Spec changed, recreating Pod %s", pod.Name)
+		log.Info("Spec changed, recreating Pod")
+		return ctrl.Result{RequeueAfter: r.Config.Get().SyncPeriods.Unit.Duration}, nil
+	}
+	if podExists {
+		return ctrl.Result{}, nil
 	}
 
-	return ctrl.Result{}, nil
+	// 创建逻辑，引用检查避免 Pod 卡在 CreateContainerConfigError
+	missing, err := r.missingReference(ctx, unit)
+	if err != nil {
+		return ctrl.Result{}, events.Warning(ctx, r.Recorder, "unit", unit, ReasonSyncFailed, err)
+	}
+	if setReferencesCondition(unit, missing) {
+		if missing != nil {
+			r.Recorder.Eventf(unit, v1.EventTypeWarning, ReasonMissingReference, "%s not found", missing.describe(unit.Namespace))
+		}
+		if err := r.Status().Update(ctx, unit); err != nil {
+			return ctrl.Result{}, events.Warning(ctx, r.Recorder, "unit", unit, ReasonUpdateFailed, err)
+		}
+	}
+	if missing != nil {
+		log.V(logging.Debug).Info("Waiting for references", "missingKind", missing.kind, "missingName", missing.name, "missingKey", missing.key)
+		return ctrl.Result{RequeueAfter: r.Config.Get().SyncPeriods.Unit.Duration}, nil
+	}
+	options, err := r.podOptions(ctx, unit)
+	if err != nil {
+		return ctrl.Result{}, events.Warning(ctx, r.Recorder, "unit", unit, ReasonSyncFailed, err)
+	}
+	pod = generatePod(unit, options)
+	createErr := r.Create(ctx, pod)
+	if createErr != nil {
+		events.Warning(ctx, r.Recorder, "unit", unit, ReasonCreateFailed, createErr)
+	} else {
+		r.Recorder.Eventf(unit, v1.EventTypeNormal, ReasonCreated, "Created Pod %s", pod.Name)
+		log.Info("Created Pod", "image", pod.Spec.Containers[0].Image)
+	}
+	if setPodCreatedCondition(unit, createErr) {
+		if err := r.Status().Update(ctx, unit); err != nil && createErr == nil {
+			return ctrl.Result{}, events.Warning(ctx, r.Recorder, "unit", unit, ReasonUpdateFailed, err)
+		}
+	}
+	return ctrl.Result{}, createErr
 }
 
-const (
-	UpdatePeriod = time.Second * 10
-)
+// podOptions 查询项目工作区并合并安全选项
+func (r *UnitReconciler) podOptions(ctx context.Context, unit *corev1.Unit) (podOptions, error) {
+	options := podOptions{
+		Security: effectiveSecurity(unit.Spec.Security, r.Config.Get().Unit),
+		Unit:     r.Config.Get().Unit,
+	}
+	claim := &v1.PersistentVolumeClaim{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: unit.Namespace, Name: corev1.WorkspaceClaimName}, claim)
+	if err == nil {
+		options.WorkspaceClaim = claim.Name
+	} else if !apierrors.IsNotFound(err) {
+		return options, err
+	}
+	return options, nil
+}
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *UnitReconciler) SetupWithManager(mgr ctrl.Manager, ctx context.Context) error {
-	go func() {
-		if mgr.GetCache().WaitForCacheSync(ctx) {
-			go wait.Until(r.SyncPods, UpdatePeriod, ctx.Done())
-		}
-	}()
+func (r *UnitReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	if r.Reader == nil {
+		r.Reader = mgr.GetAPIReader()
+	}
+	if err := mgr.Add(config.Loop{Sync: r.SyncPods, Period: func() time.Duration {
+		return r.Config.Get().SyncPeriods.Unit.Duration
+	}}); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Unit{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Watches(&source.Kind{Type: &corev1.Tunnel{}}, handler.EnqueueRequestsFromMapFunc(r.tunnelRequests)).
+		Watches(&source.Kind{Type: &v1.Node{}}, handler.EnqueueRequestsFromMapFunc(r.nodeRequests),
+			builder.WithPredicates(nodeCIDRsChanged)).
 		Complete(r)
 }
 
 func (r *UnitReconciler) SyncPods() {
 	var (
 		ctx  = context.TODO()
+		log  = logging.Named("unit")
 		list = &v1.PodList{}
 	)
 	err := r.List(ctx, list, &client.ListOptions{LabelSelector: labels.SelectorFromSet(map[string]string{
-		LabelKey: LabelValue,
+		r.Config.Get().Unit.LabelKey: LabelValue,
 	})})
 	if err != nil {
-		klog.Errorf("List Pods Error: %v", err)
+		metrics.LogError(log, "unit", err, "Failed to list Pods")
 		return
 	}
 	for _, pod := range list.Items {
@@ -124,13 +270,37 @@ func (r *UnitReconciler) SyncPods() {
 			Namespace: pod.GetNamespace(),
 		}, unit)
 		if err != nil {
-			klog.Errorf("Get Unit Error: %v", err)
+			metrics.LogError(log, "unit", err, "Failed to get Unit", "namespace", pod.Namespace, "name", pod.Name)
 			continue
 		}
+		log := logging.ForObject("unit", "Unit", unit)
+		ctx := logging.IntoContext(ctx, log)
+		if unit.Spec.Suspend {
+			continue
+		}
+		if unit.Status.Phase != pod.Status.Phase {
+			log.V(logging.Debug).Info("Phase changed", "from", unit.Status.Phase, "to", pod.Status.Phase)
+		}
+		if unit.Status.Phase != v1.PodRunning && pod.Status.Phase == v1.PodRunning {
+			metrics.ObserveTimeToRunning(unit, &pod)
+		}
 		unit.Status.Phase = pod.Status.Phase
+		unit.Status.Image, unit.Status.ImageDigest = podImage(&pod)
+		unit.Status.NodeName = pod.Spec.NodeName
+		unit.Status.HostIP = pod.Status.HostIP
+		setPodConditions(unit, &pod)
+		// 批处理 Unit 结束后归档日志，Pod 随后可能被删除
+		if podFinished(&pod) {
+			if archive, err := r.archiveLogs(ctx, unit, &pod); err != nil {
+				_ = events.Warning(ctx, r.Recorder, "unit", unit, ReasonArchiveFailed, err)
+			} else if archive != nil {
+				r.recordLogArchive(unit, &pod, archive)
+				log.Info("Archived logs", "location", archive.Location)
+			}
+		}
 		err = r.Status().Update(ctx, unit)
 		if err != nil {
-			klog.Errorf("Update Unit Phase Error: %v", err)
+			metrics.LogError(log, "unit", err, "Failed to update Unit phase")
 		}
 	}
 }
@@ -0,0 +1,145 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/controllers/config"
+	"github.com/cokeos/zero/internal/zerotest"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// failingClient 创建 Pod 时返回错误
+type failingClient struct {
+	client.Client
+	err error
+}
+
+func (c *failingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if _, ok := obj.(*v1.Pod); ok {
+		return c.err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func newTestReconciler(objs ...client.Object) (*UnitReconciler, *record.FakeRecorder) {
+	recorder := record.NewFakeRecorder(10)
+	c := zerotest.NewClient(objs...)
+	return &UnitReconciler{
+		Client:   c,
+		Scheme:   zerotest.Scheme,
+		Recorder: recorder,
+		Reader:   c,
+	}, recorder
+}
+
+func nextEvent(t *testing.T, recorder *record.FakeRecorder) string {
+	select {
+	case e := <-recorder.Events:
+		return e
+	default:
+		t.Fatal("expected an event")
+		return ""
+	}
+}
+
+func TestReconcileEvents(t *testing.T) {
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"}}
+	r, recorder := newTestReconciler(unit)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "alice", Name: "train"}}
+
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	if e := nextEvent(t, recorder); e != "Normal Created Created Pod train" {
+		t.Errorf("unexpected event %q", e)
+	}
+
+	updated := &corev1.Unit{}
+	if err := r.Get(context.TODO(), req.NamespacedName, updated); err != nil {
+		t.Fatal(err)
+	}
+	updated.Spec.Suspend = true
+	if err := r.Update(context.TODO(), updated); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	if e := nextEvent(t, recorder); !strings.HasPrefix(e, "Normal "+ReasonSuspended) {
+		t.Errorf("unexpected event %q", e)
+	}
+}
+
+func TestReconcileCreateFailed(t *testing.T) {
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"}}
+	r, recorder := newTestReconciler(unit)
+	r.Client = &failingClient{Client: r.Client, err: errors.New("admission denied")}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "alice", Name: "train"}}
+
+	if _, err := r.Reconcile(context.TODO(), req); err == nil {
+		t.Fatal("expected the error to be returned for backoff")
+	}
+	if e := nextEvent(t, recorder); e != "Warning CreateFailed admission denied" {
+		t.Errorf("unexpected event %q", e)
+	}
+}
+
+func TestReconcileRecreatesPodOnlyForUserChanges(t *testing.T) {
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"}}
+	r, recorder := newTestReconciler(unit)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "alice", Name: "train"}}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	nextEvent(t, recorder)
+
+	// 管理员启用工作区与安全默认值不影响已创建的 Pod
+	claim := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: corev1.WorkspaceClaimName}}
+	if err := r.Create(context.TODO(), claim); err != nil {
+		t.Fatal(err)
+	}
+	nonRoot := true
+	store, err := config.NewStore("", func(c *configv1alpha1.ZeroConfig) {
+		c.Unit.Security.RunAsNonRoot = &nonRoot
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Config = store
+	updated := &corev1.Unit{}
+	if err := r.Get(context.TODO(), req.NamespacedName, updated); err != nil {
+		t.Fatal(err)
+	}
+	updated.Spec.IdlePolicy = &corev1.IdlePolicy{}
+	if err := r.Update(context.TODO(), updated); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(context.TODO(), req.NamespacedName, &v1.Pod{}); err != nil {
+		t.Fatalf("expected the Pod to be kept, got %v", err)
+	}
+
+	// 用户修改镜像后重建
+	updated.Spec.Image = "pytorch/pytorch:1.9"
+	if err := r.Update(context.TODO(), updated); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	if e := nextEvent(t, recorder); !strings.HasPrefix(e, "Normal "+ReasonRecreating) {
+		t.Errorf("unexpected event %q", e)
+	}
+}
@@ -0,0 +1,158 @@
+package usage
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// csvHeader CSV 文件的列
+var csvHeader = []string{"namespace", "unit", "uid", "user", "gpu_model", "gpus", "milli_cpu", "memory", "start", "end"}
+
+// CSVSink 保存区间到 CSV 文件，便于直接导入表格处理
+//
+// 每次保存都会重写整个文件，只适合区间数量不多的场景。
+type CSVSink struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (s *CSVSink) Save(ctx context.Context, intervals []Interval) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, err := s.read()
+	if err != nil {
+		return err
+	}
+	byKey := make(map[string]Interval, len(existing)+len(intervals))
+	for _, interval := range existing {
+		byKey[interval.Key()] = interval
+	}
+	for _, interval := range intervals {
+		byKey[interval.Key()] = interval
+	}
+	all := make([]Interval, 0, len(byKey))
+	for _, interval := range byKey {
+		all = append(all, interval)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].Start.Equal(all[j].Start) {
+			return all[i].Start.Before(all[j].Start)
+		}
+		return all[i].Key() < all[j].Key()
+	})
+
+	// 先写临时文件再替换，避免写入中断时损坏原文件
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := WriteCSV(tmp, all); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+func (s *CSVSink) List(ctx context.Context, namespace string, from, to time.Time) ([]Interval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	var intervals []Interval
+	for _, interval := range all {
+		if (namespace == "" || interval.Namespace == namespace) && interval.overlaps(from, to) {
+			intervals = append(intervals, interval)
+		}
+	}
+	return intervals, nil
+}
+
+func (s *CSVSink) read() ([]Interval, error) {
+	f, err := os.Open(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadCSV(f)
+}
+
+// WriteCSV 以 CSV 导出区间，时间为 RFC 3339 格式
+func WriteCSV(w io.Writer, intervals []Interval) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, i := range intervals {
+		if err := writer.Write([]string{
+			i.Namespace, i.Unit, string(i.UID), i.User, i.GPUModel,
+			strconv.Itoa(i.GPUs),
+			strconv.FormatInt(i.MilliCPU, 10),
+			strconv.FormatInt(i.Memory, 10),
+			i.Start.Format(time.RFC3339),
+			i.End.Format(time.RFC3339),
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadCSV 读取 WriteCSV 导出的区间
+func ReadCSV(r io.Reader) ([]Interval, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(csvHeader)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	intervals := make([]Interval, 0, len(records))
+	for n, record := range records {
+		if n == 0 {
+			continue
+		}
+		i := Interval{
+			Namespace: record[0],
+			Unit:      record[1],
+			UID:       types.UID(record[2]),
+			User:      record[3],
+			GPUModel:  record[4],
+		}
+		if i.GPUs, err = strconv.Atoi(record[5]); err != nil {
+			return nil, err
+		}
+		if i.MilliCPU, err = strconv.ParseInt(record[6], 10, 64); err != nil {
+			return nil, err
+		}
+		if i.Memory, err = strconv.ParseInt(record[7], 10, 64); err != nil {
+			return nil, err
+		}
+		if i.Start, err = time.Parse(time.RFC3339, record[8]); err != nil {
+			return nil, err
+		}
+		if i.End, err = time.Parse(time.RFC3339, record[9]); err != nil {
+			return nil, err
+		}
+		intervals = append(intervals, i)
+	}
+	return intervals, nil
+}
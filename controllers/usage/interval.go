@@ -0,0 +1,149 @@
+package usage
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Interval 一个 Unit 以相同资源连续运行的区间
+type Interval struct {
+	Namespace string    `json:"namespace"`
+	Unit      string    `json:"unit"`
+	UID       types.UID `json:"uid"`
+	// User 创建 Unit 的用户，来自 cokeos.io/created-by 注解
+	User string `json:"user,omitempty"`
+	// GPUModel Unit 所在节点的 GPU 型号，未使用 GPU 时为空
+	GPUModel string `json:"gpuModel,omitempty"`
+	GPUs     int    `json:"gpus"`
+	// MilliCPU CPU 配额，单位为千分之一核
+	MilliCPU int64 `json:"milliCPU"`
+	// Memory 内存配额，单位为字节
+	Memory int64     `json:"memory"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+}
+
+// Key 区间的唯一标识，同一 Unit 的区间以开始时间区分
+func (i *Interval) Key() string {
+	return string(i.UID) + "." + strconv.FormatInt(i.Start.Unix(), 10)
+}
+
+// sameResources 资源不变时延长原区间，否则开始新的区间
+func (i *Interval) sameResources(other *Interval) bool {
+	return i.User == other.User && i.GPUModel == other.GPUModel && i.GPUs == other.GPUs &&
+		i.MilliCPU == other.MilliCPU && i.Memory == other.Memory
+}
+
+// overlap 区间与 [from, to) 重叠的时长
+func (i *Interval) overlap(from, to time.Time) time.Duration {
+	start, end := i.Start, i.End
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// overlaps 区间是否与 [from, to) 有交集，刚开始的空区间也算在内
+func (i *Interval) overlaps(from, to time.Time) bool {
+	return i.Start.Before(to) && !i.End.Before(from)
+}
+
+// periodStart 返回 t 所在统计周期的开始与结束
+func periodStart(period corev1.UsagePeriod, t time.Time) (time.Time, time.Time) {
+	if period == corev1.UsageMonthly {
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 1, 0)
+	}
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 0, 1)
+}
+
+// reportName 例如 daily-2021-10-19 与 monthly-2021-10
+func reportName(period corev1.UsagePeriod, start time.Time) string {
+	if period == corev1.UsageMonthly {
+		return "monthly-" + start.Format("2006-01")
+	}
+	return "daily-" + start.Format("2006-01-02")
+}
+
+const gib = 1 << 30
+
+// hours 保留两位小数
+func hours(seconds float64) string {
+	return fmt.Sprintf("%.2f", seconds/3600)
+}
+
+type entryKey struct {
+	user     string
+	gpuModel string
+}
+
+type entrySum struct {
+	units   map[types.UID]bool
+	running float64
+	gpu     float64
+	cpu     float64
+	memory  float64
+}
+
+// aggregate 按用户与 GPU 型号汇总 [from, to) 内的用量
+func aggregate(intervals []Interval, from, to time.Time) corev1.UsageReportStatus {
+	sums := map[entryKey]*entrySum{}
+	var gpu, cpu, memory float64
+	for i := range intervals {
+		interval := &intervals[i]
+		if !interval.overlaps(from, to) {
+			continue
+		}
+		key := entryKey{interval.User, interval.GPUModel}
+		sum, ok := sums[key]
+		if !ok {
+			sum = &entrySum{units: map[types.UID]bool{}}
+			sums[key] = sum
+		}
+		seconds := interval.overlap(from, to).Seconds()
+		sum.units[interval.UID] = true
+		sum.running += seconds
+		sum.gpu += seconds * float64(interval.GPUs)
+		sum.cpu += seconds * float64(interval.MilliCPU) / 1000
+		sum.memory += seconds * float64(interval.Memory) / gib
+		gpu += seconds * float64(interval.GPUs)
+		cpu += seconds * float64(interval.MilliCPU) / 1000
+		memory += seconds * float64(interval.Memory) / gib
+	}
+
+	status := corev1.UsageReportStatus{
+		GPUHours:       hours(gpu),
+		CPUCoreHours:   hours(cpu),
+		MemoryGiBHours: hours(memory),
+	}
+	for key, sum := range sums {
+		status.Entries = append(status.Entries, corev1.UsageEntry{
+			User:           key.user,
+			GPUModel:       key.gpuModel,
+			Units:          int32(len(sum.units)),
+			RunningHours:   hours(sum.running),
+			GPUHours:       hours(sum.gpu),
+			CPUCoreHours:   hours(sum.cpu),
+			MemoryGiBHours: hours(sum.memory),
+		})
+	}
+	sort.Slice(status.Entries, func(i, j int) bool {
+		if status.Entries[i].User != status.Entries[j].User {
+			return status.Entries[i].User < status.Entries[j].User
+		}
+		return status.Entries[i].GPUModel < status.Entries[j].GPUModel
+	})
+	return status
+}
@@ -0,0 +1,190 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Sink 保存 Unit 运行区间的存储
+type Sink interface {
+	// Save 新增或更新区间，以 Key 标识
+	Save(ctx context.Context, intervals []Interval) error
+	// List 返回与 [from, to) 有交集的区间，namespace 为空时返回全部命名空间
+	List(ctx context.Context, namespace string, from, to time.Time) ([]Interval, error)
+}
+
+const (
+	// ConfigMapPrefix 每月一组 ConfigMap，例如 zero-usage-2021-10、zero-usage-2021-10-1
+	ConfigMapPrefix = "zero-usage-"
+	LabelKey        = "cokeos.io/zero-usage"
+	LabelValue      = "true"
+
+	// DefaultConfigMapSize 单个 ConfigMap 保存数据的上限，低于 1MiB 以留出元数据的空间
+	DefaultConfigMapSize = 900 << 10
+)
+
+// ConfigMapSink 以 JSON 保存区间到指定命名空间的 ConfigMap，每月一组
+//
+// 记录器会在月初切分区间，区间总是保存在其开始时间所在月份的 ConfigMap 中。
+// 一个 ConfigMap 写满 MaxSize 后新的区间写入同月的下一个 ConfigMap，已有区间原地更新。
+// 每次保存与查询都会读取整月的数据，规模较大时应使用 SQLiteSink。
+type ConfigMapSink struct {
+	// Client 不能使用缓存，否则需要额外监听 ConfigMap
+	Client    client.Client
+	Namespace string
+	// MaxSize 单个 ConfigMap 中数据的字节数上限，为 0 时使用 DefaultConfigMapSize
+	MaxSize int
+}
+
+// name 返回 t 所在月份的第 shard 个 ConfigMap 的名称，第一个不带序号以兼容旧数据
+func (s *ConfigMapSink) name(t time.Time, shard int) string {
+	name := ConfigMapPrefix + t.Format("2006-01")
+	if shard > 0 {
+		name += "-" + strconv.Itoa(shard)
+	}
+	return name
+}
+
+func (s *ConfigMapSink) maxSize() int {
+	if s.MaxSize > 0 {
+		return s.MaxSize
+	}
+	return DefaultConfigMapSize
+}
+
+// shards 按序号返回 month 所在月份已有的 ConfigMap
+func (s *ConfigMapSink) shards(ctx context.Context, month time.Time) ([]*v1.ConfigMap, error) {
+	var shards []*v1.ConfigMap
+	for i := 0; ; i++ {
+		cm := &v1.ConfigMap{}
+		err := s.Client.Get(ctx, types.NamespacedName{Namespace: s.Namespace, Name: s.name(month, i)}, cm)
+		if apierrors.IsNotFound(err) {
+			return shards, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, cm)
+	}
+}
+
+func (s *ConfigMapSink) Save(ctx context.Context, intervals []Interval) error {
+	months := map[string][]Interval{}
+	for _, interval := range intervals {
+		month := interval.Start.Format("2006-01")
+		months[month] = append(months[month], interval)
+	}
+	for _, intervals := range months {
+		data := make(map[string]string, len(intervals))
+		for _, interval := range intervals {
+			value, err := json.Marshal(interval)
+			if err != nil {
+				return err
+			}
+			data[interval.Key()] = string(value)
+		}
+		if err := s.save(ctx, intervals[0].Start, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// save 将 data 写入 month 所在月份的 ConfigMap，已有的键原地更新，新键写入未满的最后一个 ConfigMap
+func (s *ConfigMapSink) save(ctx context.Context, month time.Time, data map[string]string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		shards, err := s.shards(ctx, month)
+		if err != nil {
+			return err
+		}
+		sizes := make([]int, len(shards))
+		owners := make(map[string]int)
+		for i, cm := range shards {
+			for key, value := range cm.Data {
+				sizes[i] += len(key) + len(value)
+				owners[key] = i
+			}
+		}
+
+		changed := make(map[int]bool)
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := data[key]
+			i, ok := owners[key]
+			if ok {
+				sizes[i] += len(value) - len(shards[i].Data[key])
+			} else {
+				i = len(shards) - 1
+				if i < 0 || sizes[i]+len(key)+len(value) > s.maxSize() {
+					shards = append(shards, &v1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: s.Namespace,
+							Name:      s.name(month, len(shards)),
+							Labels:    map[string]string{LabelKey: LabelValue},
+						},
+					})
+					sizes = append(sizes, 0)
+					i++
+				}
+				sizes[i] += len(key) + len(value)
+			}
+			if shards[i].Data == nil {
+				shards[i].Data = make(map[string]string)
+			}
+			shards[i].Data[key] = value
+			changed[i] = true
+		}
+
+		for i, cm := range shards {
+			if !changed[i] {
+				continue
+			}
+			if cm.ResourceVersion == "" {
+				err = s.Client.Create(ctx, cm)
+			} else {
+				err = s.Client.Update(ctx, cm)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *ConfigMapSink) List(ctx context.Context, namespace string, from, to time.Time) ([]Interval, error) {
+	var intervals []Interval
+	for month, _ := periodStart(corev1.UsageMonthly, from); month.Before(to); month = month.AddDate(0, 1, 0) {
+		shards, err := s.shards(ctx, month)
+		if err != nil {
+			return nil, err
+		}
+		for _, cm := range shards {
+			for _, value := range cm.Data {
+				interval := Interval{}
+				if err := json.Unmarshal([]byte(value), &interval); err != nil {
+					return nil, err
+				}
+				if (namespace == "" || interval.Namespace == namespace) && interval.overlaps(from, to) {
+					intervals = append(intervals, interval)
+				}
+			}
+		}
+	}
+	return intervals, nil
+}
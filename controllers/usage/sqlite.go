@@ -0,0 +1,97 @@
+package usage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	// 纯 Go 实现，manager 以 CGO_ENABLED=0 构建
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS intervals (
+	key        TEXT PRIMARY KEY,
+	namespace  TEXT NOT NULL,
+	unit       TEXT NOT NULL,
+	uid        TEXT NOT NULL,
+	user       TEXT NOT NULL,
+	gpu_model  TEXT NOT NULL,
+	gpus       INTEGER NOT NULL,
+	milli_cpu  INTEGER NOT NULL,
+	memory     INTEGER NOT NULL,
+	start_time INTEGER NOT NULL,
+	end_time   INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS intervals_time ON intervals (start_time, end_time);`
+
+// SQLiteSink 保存区间到 SQLite 数据库文件，时间以 Unix 秒保存
+type SQLiteSink struct {
+	DB *sql.DB
+	// Location 读取时间使用的时区
+	Location *time.Location
+}
+
+// NewSQLiteSink 打开 path 处的数据库，不存在时创建
+func NewSQLiteSink(path string, location *time.Location) (*SQLiteSink, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite 同时只允许一个写入者
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteSink{DB: db, Location: location}, nil
+}
+
+func (s *SQLiteSink) Save(ctx context.Context, intervals []Interval) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO intervals
+		(key, namespace, unit, uid, user, gpu_model, gpus, milli_cpu, memory, start_time, end_time)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET end_time = excluded.end_time`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, i := range intervals {
+		if _, err := stmt.ExecContext(ctx, i.Key(), i.Namespace, i.Unit, string(i.UID), i.User, i.GPUModel,
+			i.GPUs, i.MilliCPU, i.Memory, i.Start.Unix(), i.End.Unix()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteSink) List(ctx context.Context, namespace string, from, to time.Time) ([]Interval, error) {
+	rows, err := s.DB.QueryContext(ctx, `SELECT namespace, unit, uid, user, gpu_model, gpus, milli_cpu, memory, start_time, end_time
+		FROM intervals WHERE start_time < ? AND end_time >= ? AND (? = '' OR namespace = ?)`,
+		to.Unix(), from.Unix(), namespace, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var intervals []Interval
+	for rows.Next() {
+		var (
+			i          Interval
+			uid        string
+			start, end int64
+		)
+		if err := rows.Scan(&i.Namespace, &i.Unit, &uid, &i.User, &i.GPUModel, &i.GPUs, &i.MilliCPU, &i.Memory, &start, &end); err != nil {
+			return nil, err
+		}
+		i.UID = types.UID(uid)
+		i.Start, i.End = time.Unix(start, 0).In(s.Location), time.Unix(end, 0).In(s.Location)
+		intervals = append(intervals, i)
+	}
+	return intervals, rows.Err()
+}
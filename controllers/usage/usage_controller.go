@@ -0,0 +1,285 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"context"
+	"time"
+
+	"github.com/cokeos/zero/controllers/config"
+	"github.com/cokeos/zero/controllers/logging"
+	"github.com/cokeos/zero/controllers/metrics"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+)
+
+const (
+	// UpdatePeriod 采样间隔，也是区间起止时间的精度
+	UpdatePeriod = time.Minute
+
+	DefaultReportPeriod = time.Minute * 10
+)
+
+// UsageReconciler 周期性记录运行中 Unit 的资源区间，并汇总为每个命名空间每天与每月的 UsageReport
+//
+// 相邻两次采样资源不变时延长原区间；控制器中断超过两个采样间隔后开始新的区间，
+// 中断期间的用量不计入。区间在月初切分，保证每个区间只属于一个月。
+type UsageReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Sink   Sink
+	Clock  clock.Clock
+	// Location 按该时区划分天与月，为空时使用本地时区
+	Location *time.Location
+	// ReportPeriod 汇总 UsageReport 的间隔
+	ReportPeriod time.Duration
+	// Config 当前生效的配置，为空时使用默认配置
+	Config *config.Store
+
+	// open 各 Unit 正在延长的区间
+	open       map[types.UID]*Interval
+	restored   bool
+	lastReport time.Time
+}
+
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=units,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=usagereports,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core.cokeos.io,resources=usagereports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=nodes;namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update
+
+// SetupWithManager sets up the usage accounting loop with the Manager.
+func (r *UsageReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	if r.Clock == nil {
+		r.Clock = clock.RealClock{}
+	}
+	if r.Location == nil {
+		r.Location = time.Local
+	}
+	if r.ReportPeriod == 0 {
+		r.ReportPeriod = DefaultReportPeriod
+	}
+	// 只在主副本上记录，否则每个副本都会写入同一段用量
+	return mgr.Add(config.Loop{Sync: r.SyncUsage, Period: func() time.Duration {
+		return UpdatePeriod
+	}})
+}
+
+func (r *UsageReconciler) SyncUsage() {
+	ctx := context.TODO()
+	now := r.Clock.Now().In(r.Location)
+	if err := r.record(ctx, now); err != nil {
+		metrics.LogError(logging.Named("usage"), "usage", err, "Failed to record usage")
+		return
+	}
+	if now.Sub(r.lastReport) < r.ReportPeriod {
+		return
+	}
+	if err := r.report(ctx, now); err != nil {
+		metrics.LogError(logging.Named("usage"), "usage", err, "Failed to report usage")
+		return
+	}
+	r.lastReport = now
+}
+
+// record 延长或开始运行中 Unit 的区间并保存
+func (r *UsageReconciler) record(ctx context.Context, now time.Time) error {
+	if !r.restored {
+		// 重启后接上中断前正在延长的区间
+		intervals, err := r.Sink.List(ctx, "", now.Add(-2*UpdatePeriod), now)
+		if err != nil {
+			return err
+		}
+		r.open = make(map[types.UID]*Interval, len(intervals))
+		for i := range intervals {
+			if prev := r.open[intervals[i].UID]; prev == nil || intervals[i].End.After(prev.End) {
+				r.open[intervals[i].UID] = &intervals[i]
+			}
+		}
+		r.restored = true
+	}
+
+	units := &corev1.UnitList{}
+	if err := r.List(ctx, units); err != nil {
+		return err
+	}
+	nodes := &v1.NodeList{}
+	if err := r.List(ctx, nodes); err != nil {
+		return err
+	}
+	models := make(map[string]string, len(nodes.Items))
+	for _, node := range nodes.Items {
+		models[node.Name] = node.Labels[r.Config.Get().Unit.GPUModelLabel]
+	}
+
+	month, _ := periodStart(corev1.UsageMonthly, now)
+	running := make(map[types.UID]bool, len(units.Items))
+	var changed []Interval
+	for i := range units.Items {
+		u := &units.Items[i]
+		if u.Status.Phase != v1.PodRunning {
+			continue
+		}
+		running[u.UID] = true
+		current := sample(u, models[u.Status.NodeName])
+		current.Start, current.End = now, now
+		if prev := r.open[u.UID]; prev != nil && prev.sameResources(current) && now.Sub(prev.End) <= 2*UpdatePeriod {
+			observe(prev, now)
+			if !prev.Start.Before(month) {
+				prev.End = now
+				changed = append(changed, *prev)
+				continue
+			}
+			// 跨月时在月初结束原区间
+			prev.End = month
+			changed = append(changed, *prev)
+			current.Start = month
+		}
+		r.open[u.UID] = current
+		changed = append(changed, *current)
+	}
+	for uid := range r.open {
+		if !running[uid] {
+			delete(r.open, uid)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return r.Sink.Save(ctx, changed)
+}
+
+// sample Unit 当前的资源，节点上有 GPU 型号标签时以标签为准
+func sample(u *corev1.Unit, nodeModel string) *Interval {
+	interval := &Interval{
+		Namespace: u.Namespace,
+		Unit:      u.Name,
+		UID:       u.UID,
+		User:      u.Annotations[corev1.CreatedByAnnotation],
+		MilliCPU:  u.Spec.ResourceList.Cpu().MilliValue(),
+		Memory:    u.Spec.ResourceList.Memory().Value(),
+	}
+	if u.Spec.GPUPolicy.GPU {
+		interval.GPUs = u.Spec.GPUPolicy.Number
+		interval.GPUModel = nodeModel
+		if interval.GPUModel == "" {
+			interval.GPUModel = u.Spec.GPUPolicy.Model
+		}
+	}
+	return interval
+}
+
+// observe 将区间延长的部分计入 GPU 卡秒
+func observe(interval *Interval, now time.Time) {
+	if interval.GPUs == 0 || !now.After(interval.End) {
+		return
+	}
+	metrics.UsageGPUSeconds.WithLabelValues(interval.Namespace, interval.User, interval.GPUModel).
+		Add(now.Sub(interval.End).Seconds() * float64(interval.GPUs))
+}
+
+// report 汇总当前的天与月，跨天或跨月后再最后汇总一次上一个周期
+func (r *UsageReconciler) report(ctx context.Context, now time.Time) error {
+	times := []time.Time{now}
+	if !r.lastReport.IsZero() {
+		times = append(times, r.lastReport)
+	}
+	for _, period := range []corev1.UsagePeriod{corev1.UsageDaily, corev1.UsageMonthly} {
+		reported := map[time.Time]bool{}
+		for _, t := range times {
+			start, end := periodStart(period, t)
+			if reported[start] {
+				continue
+			}
+			reported[start] = true
+			if err := r.reportPeriod(ctx, period, start, end, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *UsageReconciler) reportPeriod(ctx context.Context, period corev1.UsagePeriod, start, end, now time.Time) error {
+	intervals, err := r.Sink.List(ctx, "", start, end)
+	if err != nil {
+		return err
+	}
+	namespaces := map[string][]Interval{}
+	for _, interval := range intervals {
+		namespaces[interval.Namespace] = append(namespaces[interval.Namespace], interval)
+	}
+	for namespace, intervals := range namespaces {
+		status := aggregate(intervals, start, end)
+		ns := &v1.Namespace{}
+		if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); apierrors.IsNotFound(err) {
+			// 命名空间已删除，其用量保留在存储中
+			continue
+		} else if err != nil {
+			return err
+		}
+		status.Project = ns.Labels[corev1.ProjectLabelKey]
+		if err := r.updateReport(ctx, namespace, period, start, end, status, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *UsageReconciler) updateReport(ctx context.Context, namespace string, period corev1.UsagePeriod,
+	start, end time.Time, status corev1.UsageReportStatus, now time.Time) error {
+	report := &corev1.UsageReport{}
+	name := reportName(period, start)
+	err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, report)
+	if apierrors.IsNotFound(err) {
+		report = &corev1.UsageReport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+				Labels:    map[string]string{LabelKey: LabelValue},
+			},
+			Spec: corev1.UsageReportSpec{
+				Period: period,
+				Start:  metav1.NewTime(start),
+				End:    metav1.NewTime(end),
+			},
+		}
+		if err := r.Create(ctx, report); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	status.LastUpdateTime = report.Status.LastUpdateTime
+	if equality.Semantic.DeepEqual(status, report.Status) {
+		return nil
+	}
+	updated := metav1.NewTime(now)
+	status.LastUpdateTime = &updated
+	report.Status = status
+	return r.Status().Update(ctx, report)
+}
@@ -0,0 +1,226 @@
+package usage
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/internal/zerotest"
+)
+
+func newTestReconciler(now time.Time, objs ...client.Object) (*UsageReconciler, *clock.FakeClock) {
+	c := zerotest.NewClient(objs...)
+	fakeClock := clock.NewFakeClock(now)
+	return &UsageReconciler{
+		Client:       c,
+		Scheme:       zerotest.Scheme,
+		Sink:         &ConfigMapSink{Client: c, Namespace: "zero-system"},
+		Clock:        fakeClock,
+		Location:     time.UTC,
+		ReportPeriod: DefaultReportPeriod,
+	}, fakeClock
+}
+
+func newRunningUnit(name string, gpus int) *corev1.Unit {
+	u := &corev1.Unit{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "alice",
+			Name:        name,
+			UID:         types.UID(name + "-uid"),
+			Annotations: map[string]string{corev1.CreatedByAnnotation: "alice"},
+		},
+		Spec: corev1.UnitSpec{
+			GPUPolicy: corev1.GPUPolicy{GPU: gpus > 0, Number: gpus},
+			ResourceList: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("4"),
+				v1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+		},
+	}
+	u.Status.Phase = v1.PodRunning
+	u.Status.NodeName = "gpu-1"
+	return u
+}
+
+func TestSyncUsage(t *testing.T) {
+	start := time.Date(2021, 10, 19, 9, 0, 0, 0, time.UTC)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "gpu-1", Labels: map[string]string{configv1alpha1.DefaultGPUModelLabel: "RTX-3090"}}}
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "alice", Labels: map[string]string{corev1.ProjectLabelKey: "vision"}}}
+	r, fakeClock := newTestReconciler(start, node, ns, newRunningUnit("train", 2))
+
+	// 运行两小时
+	for i := 0; i <= 120; i++ {
+		r.SyncUsage()
+		fakeClock.Step(UpdatePeriod)
+	}
+
+	report := &corev1.UsageReport{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: "alice", Name: "daily-2021-10-19"}, report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Status.Project != "vision" || len(report.Status.Entries) != 1 {
+		t.Fatalf("unexpected report %+v", report.Status)
+	}
+	entry := report.Status.Entries[0]
+	if entry.User != "alice" || entry.GPUModel != "RTX-3090" || entry.Units != 1 {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if entry.RunningHours != "2.00" || entry.GPUHours != "4.00" || entry.CPUCoreHours != "8.00" || entry.MemoryGiBHours != "32.00" {
+		t.Errorf("unexpected usage %+v", entry)
+	}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: "alice", Name: "monthly-2021-10"}, report); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重启后接上原区间
+	restarted, _ := newTestReconciler(fakeClock.Now())
+	restarted.Client, restarted.Sink = r.Client, r.Sink
+	restarted.SyncUsage()
+	intervals, err := r.Sink.List(context.TODO(), "alice", start, fakeClock.Now().Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) != 1 || !intervals[0].Start.Equal(start) || !intervals[0].End.Equal(fakeClock.Now()) {
+		t.Errorf("expected one extended interval, got %+v", intervals)
+	}
+}
+
+func TestSyncUsageSplitsMonths(t *testing.T) {
+	start := time.Date(2021, 10, 31, 23, 59, 0, 0, time.UTC)
+	r, fakeClock := newTestReconciler(start, newRunningUnit("train", 1))
+	for i := 0; i < 3; i++ {
+		r.SyncUsage()
+		fakeClock.Step(UpdatePeriod)
+	}
+	intervals, err := r.Sink.List(context.TODO(), "", start, fakeClock.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) != 2 {
+		t.Fatalf("expected the interval to be split, got %+v", intervals)
+	}
+	month := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+	for _, interval := range intervals {
+		if interval.Start.Before(month) != interval.End.Equal(month) {
+			t.Errorf("unexpected interval %+v", interval)
+		}
+	}
+}
+
+func TestSinks(t *testing.T) {
+	dir := t.TempDir()
+	sqlite, err := NewSQLiteSink(filepath.Join(dir, "usage.db"), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite.DB.Close()
+	r, _ := newTestReconciler(time.Now())
+	sinks := map[string]Sink{
+		"sqlite":    sqlite,
+		"csv":       &CSVSink{Path: filepath.Join(dir, "usage.csv")},
+		"configmap": r.Sink,
+	}
+	start := time.Date(2021, 10, 19, 9, 0, 0, 0, time.UTC)
+	interval := Interval{
+		Namespace: "alice", Unit: "train", UID: "uid", User: "alice", GPUModel: "RTX-3090",
+		GPUs: 2, MilliCPU: 4000, Memory: 16 << 30, Start: start, End: start.Add(time.Minute),
+	}
+	for name, sink := range sinks {
+		t.Run(name, func(t *testing.T) {
+			if err := sink.Save(context.TODO(), []Interval{interval}); err != nil {
+				t.Fatal(err)
+			}
+			extended := interval
+			extended.End = start.Add(time.Hour)
+			other := interval
+			other.Namespace, other.UID = "bob", "other"
+			if err := sink.Save(context.TODO(), []Interval{extended, other}); err != nil {
+				t.Fatal(err)
+			}
+			intervals, err := sink.List(context.TODO(), "alice", start.Add(30*time.Minute), start.Add(2*time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(intervals) != 1 || intervals[0] != extended {
+				t.Errorf("expected %+v, got %+v", extended, intervals)
+			}
+			if intervals, _ := sink.List(context.TODO(), "", start.Add(2*time.Hour), start.Add(3*time.Hour)); len(intervals) != 0 {
+				t.Errorf("expected no intervals, got %+v", intervals)
+			}
+		})
+	}
+}
+
+func TestConfigMapSinkRollsOver(t *testing.T) {
+	r, _ := newTestReconciler(time.Now())
+	sink := &ConfigMapSink{Client: r.Client, Namespace: "zero-system", MaxSize: 2000}
+	start := time.Date(2021, 10, 19, 9, 0, 0, 0, time.UTC)
+	intervals := make([]Interval, 0, 40)
+	for i := 0; i < 40; i++ {
+		intervals = append(intervals, Interval{
+			Namespace: "alice", Unit: "train", UID: types.UID(strconv.Itoa(i)),
+			GPUs: 1, Start: start.Add(time.Duration(i) * time.Minute), End: start.Add(time.Duration(i+1) * time.Minute),
+		})
+	}
+	// 分两次保存，第二次需要写入已有 ConfigMap 之后新建的 ConfigMap
+	if err := sink.Save(context.TODO(), intervals[:10]); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Save(context.TODO(), intervals[10:]); err != nil {
+		t.Fatal(err)
+	}
+
+	shards, err := sink.shards(context.TODO(), start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shards) < 2 || shards[0].Name != "zero-usage-2021-10" || shards[1].Name != "zero-usage-2021-10-1" {
+		t.Fatalf("expected the intervals to roll over to more ConfigMaps, got %d", len(shards))
+	}
+	for _, cm := range shards {
+		size := 0
+		for key, value := range cm.Data {
+			size += len(key) + len(value)
+		}
+		if size > sink.MaxSize {
+			t.Errorf("expected %s to stay within %d bytes, got %d", cm.Name, sink.MaxSize, size)
+		}
+	}
+
+	// 已有区间原地更新，不再新建 ConfigMap
+	extended := intervals[0]
+	extended.End = start.Add(2 * time.Hour)
+	if err := sink.Save(context.TODO(), []Interval{extended}); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := sink.shards(context.TODO(), start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated) != len(shards) {
+		t.Errorf("expected %d ConfigMaps after updating, got %d", len(shards), len(updated))
+	}
+	listed, err := sink.List(context.TODO(), "alice", start, start.Add(3*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != len(intervals) {
+		t.Fatalf("expected %d intervals, got %d", len(intervals), len(listed))
+	}
+	for _, interval := range listed {
+		if interval.UID == extended.UID && interval != extended {
+			t.Errorf("expected %+v, got %+v", extended, interval)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// HostKeySecretSuffix 保存 Unit 主机密钥的 Secret 名称后缀，Secret 名称为 <tiny>-ssh-host-key
+	HostKeySecretSuffix = "-ssh-host-key"
+	// HostKeyKey Secret 中 sshd 主机私钥所在的键，与 OpenSSH 默认的 ECDSA 主机密钥文件同名
+	HostKeyKey = "ssh_host_ecdsa_key"
+	// HostPublicKeyKey Secret 中 authorized_keys 格式主机公钥所在的键，网关据此校验 Unit
+	HostPublicKeyKey = "ssh_host_ecdsa_key.pub"
+	// HostKeyPath Unit 中 sshd 读取 ECDSA 主机密钥的路径
+	HostKeyPath = "/etc/ssh/" + HostKeyKey
+)
+
+// HostKeySecretName Tiny 的 Unit 主机密钥所在的 Secret
+func HostKeySecretName(tiny string) string {
+	return tiny + HostKeySecretSuffix
+}
+
+// GenerateKey 生成 PEM 编码的 ed25519 私钥及 authorized_keys 格式的公钥
+func GenerateKey() (privateKey []byte, authorizedKey []byte, err error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, nil, err
+	}
+	sshPublic, err := ssh.NewPublicKey(public)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKey = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	return privateKey, ssh.MarshalAuthorizedKey(sshPublic), nil
+}
+
+// GenerateHostKey 生成 sshd 可直接读取的 PEM 编码 ECDSA P-256 私钥及 authorized_keys 格式的公钥
+//
+// OpenSSH 不读取 PKCS#8 格式的 ed25519 私钥，主机密钥使用 SEC 1 格式的 ECDSA。
+func GenerateHostKey() (privateKey []byte, authorizedKey []byte, err error) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(private)
+	if err != nil {
+		return nil, nil, err
+	}
+	sshPublic, err := ssh.NewPublicKey(&private.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKey = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	return privateKey, ssh.MarshalAuthorizedKey(sshPublic), nil
+}
+
+// ParseHostKey 解析 Secret 中的主机公钥
+func ParseHostKey(data map[string][]byte) (ssh.PublicKey, error) {
+	public, _, _, _, err := ssh.ParseAuthorizedKey(data[HostPublicKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", HostPublicKeyKey, err)
+	}
+	return public, nil
+}
+
+// LoadSigner 读取 PEM 编码的私钥文件
+func LoadSigner(path string) (ssh.Signer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.ParsePrivateKey(data)
+}
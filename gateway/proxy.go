@@ -0,0 +1,79 @@
+package gateway
+
+import (
+	"io"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// proxyRequests 将全局请求转发到另一端并回传结果
+func proxyRequests(in <-chan *ssh.Request, out ssh.Conn) {
+	for req := range in {
+		ok, payload, err := out.SendRequest(req.Type, req.WantReply, req.Payload)
+		if err != nil {
+			ok = false
+		}
+		if req.WantReply {
+			_ = req.Reply(ok, payload)
+		}
+	}
+}
+
+// proxyChannels 为每个新通道在另一端打开同类型的通道
+func proxyChannels(in <-chan ssh.NewChannel, out ssh.Conn) {
+	for newChannel := range in {
+		go proxyChannel(newChannel, out)
+	}
+}
+
+func proxyChannel(newChannel ssh.NewChannel, out ssh.Conn) {
+	outChannel, outRequests, err := out.OpenChannel(newChannel.ChannelType(), newChannel.ExtraData())
+	if err != nil {
+		if openErr, ok := err.(*ssh.OpenChannelError); ok {
+			_ = newChannel.Reject(openErr.Reason, openErr.Message)
+		} else {
+			_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		}
+		return
+	}
+	inChannel, inRequests, err := newChannel.Accept()
+	if err != nil {
+		_ = outChannel.Close()
+		return
+	}
+	defer inChannel.Close()
+	defer outChannel.Close()
+
+	go proxyChannelRequests(inRequests, outChannel)
+	go func() {
+		_, _ = io.Copy(outChannel, inChannel)
+		_ = outChannel.CloseWrite()
+	}()
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(inChannel, outChannel)
+		_ = inChannel.CloseWrite()
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(inChannel.Stderr(), outChannel.Stderr())
+	}()
+	// exit-status 等请求在对端关闭通道前送达，转发完再关闭
+	proxyChannelRequests(outRequests, inChannel)
+	wg.Wait()
+}
+
+func proxyChannelRequests(in <-chan *ssh.Request, out ssh.Channel) {
+	for req := range in {
+		ok, err := out.SendRequest(req.Type, req.WantReply, req.Payload)
+		if err != nil {
+			ok = false
+		}
+		if req.WantReply {
+			_ = req.Reply(ok, nil)
+		}
+	}
+}
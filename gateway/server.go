@@ -0,0 +1,268 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"golang.org/x/crypto/ssh"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultKeysSecretName 各命名空间中保存用户公钥的 Secret
+	DefaultKeysSecretName = "zero-ssh"
+	// AuthorizedKeysKey Secret 中 authorized_keys 格式公钥所在的键
+	AuthorizedKeysKey = "authorized_keys"
+
+	DefaultUpstreamUser = "root"
+	DefaultDialTimeout  = time.Second * 10
+
+	SSH     = "ssh"
+	SSHPort = 22
+
+	extensionNamespace = "zero-namespace"
+	extensionName      = "zero-name"
+)
+
+// Server 按用户名将 SSH 连接转发到对应 Tiny 的 Unit
+//
+// 用户名为 Tiny 名称，多个命名空间中存在同名 Tiny 时使用 <tiny>.<namespace> 区分。
+// 客户端公钥需出现在 Tiny 所在命名空间的 KeysSecretName Secret 中，
+// 网关再以 UpstreamKey 通过 Tunnel 的 ClusterIP Service 登录 Unit，并以 <tiny>-ssh-host-key Secret 校验 Unit 的主机密钥。
+type Server struct {
+	Client client.Reader
+
+	// HostKey 网关自身的主机密钥
+	HostKey ssh.Signer
+	// UpstreamKey 网关登录 Unit 使用的密钥
+	UpstreamKey ssh.Signer
+	// UpstreamUser 网关登录 Unit 使用的用户
+	UpstreamUser string
+	// KeysSecretName 各命名空间中保存用户公钥的 Secret
+	KeysSecretName string
+	// DialTimeout 连接 Unit 的超时时间
+	DialTimeout time.Duration
+}
+
+// Serve 接受 listener 上的连接直到 ctx 结束
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	if s.UpstreamUser == "" {
+		s.UpstreamUser = DefaultUpstreamUser
+	}
+	if s.KeysSecretName == "" {
+		s.KeysSecretName = DefaultKeysSecretName
+	}
+	if s.DialTimeout == 0 {
+		s.DialTimeout = DefaultDialTimeout
+	}
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.handle(ctx, conn)
+	}
+}
+
+func (s *Server) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, s.serverConfig(ctx))
+	if err != nil {
+		klog.Errorf("SSH Handshake Error from %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+	defer serverConn.Close()
+
+	target := types.NamespacedName{
+		Namespace: serverConn.Permissions.Extensions[extensionNamespace],
+		Name:      serverConn.Permissions.Extensions[extensionName],
+	}
+	upstream, upstreamChannels, upstreamRequests, err := s.dial(ctx, target)
+	if err != nil {
+		// 拒绝客户端的所有通道，让客户端看到失败原因
+		klog.Errorf("Dial Tiny %s Error: %v", target, err)
+		go ssh.DiscardRequests(requests)
+		for channel := range channels {
+			_ = channel.Reject(ssh.ConnectionFailed, fmt.Sprintf("tiny %s is not reachable: %v", target, err))
+		}
+		return
+	}
+	defer upstream.Close()
+	klog.Infof("Proxy %s from %s to Tiny %s", serverConn.User(), conn.RemoteAddr(), target)
+
+	go func() {
+		_ = upstream.Wait()
+		_ = serverConn.Close()
+	}()
+	go proxyRequests(requests, upstream)
+	go proxyRequests(upstreamRequests, serverConn)
+	go proxyChannels(upstreamChannels, serverConn)
+	proxyChannels(channels, upstream)
+}
+
+func (s *Server) serverConfig(ctx context.Context) *ssh.ServerConfig {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			target, err := s.resolve(ctx, meta.User(), key)
+			if err != nil {
+				return nil, err
+			}
+			return &ssh.Permissions{
+				Extensions: map[string]string{
+					extensionNamespace: target.Namespace,
+					extensionName:      target.Name,
+				},
+			}, nil
+		},
+	}
+	config.AddHostKey(s.HostKey)
+	return config
+}
+
+// resolve 找到用户名对应且接受该公钥的 Tiny
+func (s *Server) resolve(ctx context.Context, user string, key ssh.PublicKey) (types.NamespacedName, error) {
+	candidates := make([]types.NamespacedName, 0)
+	list := &corev1.TinyList{}
+	if err := s.Client.List(ctx, list, client.MatchingFields{"metadata.name": user}); err != nil {
+		return types.NamespacedName{}, err
+	}
+	for _, tiny := range list.Items {
+		candidates = append(candidates, types.NamespacedName{Namespace: tiny.Namespace, Name: tiny.Name})
+	}
+	// <tiny>.<namespace>
+	if i := strings.LastIndex(user, "."); i > 0 && i < len(user)-1 {
+		name := types.NamespacedName{Namespace: user[i+1:], Name: user[:i]}
+		err := s.Client.Get(ctx, name, &corev1.Tiny{})
+		if err == nil {
+			candidates = append(candidates, name)
+		} else if !apierrors.IsNotFound(err) {
+			return types.NamespacedName{}, err
+		}
+	}
+
+	matched := make([]types.NamespacedName, 0, 1)
+	for _, candidate := range candidates {
+		ok, err := s.authorized(ctx, candidate.Namespace, key)
+		if err != nil {
+			return types.NamespacedName{}, err
+		}
+		if ok {
+			matched = append(matched, candidate)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return types.NamespacedName{}, fmt.Errorf("no tiny %q accepts the key", user)
+	case 1:
+		return matched[0], nil
+	}
+	return types.NamespacedName{}, fmt.Errorf("tiny %q is ambiguous, use <tiny>.<namespace> as the user", user)
+}
+
+// authorized 判断公钥是否在命名空间的 authorized_keys 中
+func (s *Server) authorized(ctx context.Context, namespace string, key ssh.PublicKey) (bool, error) {
+	secret := &v1.Secret{}
+	err := s.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: s.KeysSecretName}, secret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	rest := secret.Data[AuthorizedKeysKey]
+	for len(rest) > 0 {
+		var authorized ssh.PublicKey
+		authorized, _, _, rest, err = ssh.ParseAuthorizedKey(rest)
+		if err != nil {
+			// 没有可解析的公钥了
+			return false, nil
+		}
+		if bytes.Equal(authorized.Marshal(), key.Marshal()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// dial 通过 Tunnel 的 ClusterIP Service 登录 Unit
+func (s *Server) dial(ctx context.Context, target types.NamespacedName) (ssh.Conn, <-chan ssh.NewChannel, <-chan *ssh.Request, error) {
+	service := &v1.Service{}
+	if err := s.Client.Get(ctx, target, service); err != nil {
+		return nil, nil, nil, err
+	}
+	if service.Spec.ClusterIP == "" || service.Spec.ClusterIP == v1.ClusterIPNone {
+		return nil, nil, nil, fmt.Errorf("service %s has no cluster ip", target)
+	}
+	port := int32(SSHPort)
+	for _, p := range service.Spec.Ports {
+		if p.Name == SSH {
+			port = p.Port
+		}
+	}
+	address := net.JoinHostPort(service.Spec.ClusterIP, strconv.Itoa(int(port)))
+	hostKey, err := s.hostKey(ctx, target)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	conn, err := net.DialTimeout("tcp", address, s.DialTimeout)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	upstream, channels, requests, err := ssh.NewClientConn(conn, address, &ssh.ClientConfig{
+		User: s.UpstreamUser,
+		Auth: []ssh.AuthMethod{ssh.PublicKeys(s.UpstreamKey)},
+		// 只接受控制器为 Tiny 生成的主机密钥，镜像自带的其他类型主机密钥不参与协商
+		HostKeyCallback:   ssh.FixedHostKey(hostKey),
+		HostKeyAlgorithms: []string{hostKey.Type()},
+		Timeout:           s.DialTimeout,
+	})
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, nil, err
+	}
+	return upstream, channels, requests, nil
+}
+
+// hostKey 读取控制器为 Tiny 生成并挂载到 Unit 的主机公钥
+func (s *Server) hostKey(ctx context.Context, target types.NamespacedName) (ssh.PublicKey, error) {
+	secret := &v1.Secret{}
+	name := types.NamespacedName{Namespace: target.Namespace, Name: HostKeySecretName(target.Name)}
+	if err := s.Client.Get(ctx, name, secret); err != nil {
+		return nil, fmt.Errorf("get host key of tiny %s: %w", target, err)
+	}
+	return ParseHostKey(secret.Data)
+}
@@ -0,0 +1,236 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/internal/zerotest"
+	"golang.org/x/crypto/ssh"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nameReader 代替 API Server 处理 metadata.name 字段选择器，fake client 会忽略它
+type nameReader struct {
+	client.Reader
+}
+
+func (r nameReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	options := (&client.ListOptions{}).ApplyOptions(opts)
+	if err := r.Reader.List(ctx, list); err != nil {
+		return err
+	}
+	if options.FieldSelector == nil {
+		return nil
+	}
+	name, _ := options.FieldSelector.RequiresExactMatch("metadata.name")
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	matched := make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		if item.(client.Object).GetName() == name {
+			matched = append(matched, item)
+		}
+	}
+	return meta.SetList(list, matched)
+}
+
+func newSigner(t *testing.T, generate func() ([]byte, []byte, error)) (ssh.Signer, []byte) {
+	private, public, err := generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.ParsePrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, public
+}
+
+func newReader(objs ...client.Object) client.Reader {
+	return nameReader{zerotest.NewClient(objs...)}
+}
+
+func newTiny(namespace, name string) *corev1.Tiny {
+	return &corev1.Tiny{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+func newKeysSecret(namespace string, keys ...[]byte) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: DefaultKeysSecretName},
+		Data:       map[string][]byte{AuthorizedKeysKey: []byte(strings.Join(toStrings(keys), ""))},
+	}
+}
+
+func toStrings(keys [][]byte) []string {
+	s := make([]string, 0, len(keys))
+	for _, key := range keys {
+		s = append(s, string(key))
+	}
+	return s
+}
+
+func TestResolve(t *testing.T) {
+	alice, alicePublic := newSigner(t, GenerateKey)
+	bob, bobPublic := newSigner(t, GenerateKey)
+	s := &Server{
+		Client: newReader(
+			newTiny("alice", "demo"), newTiny("bob", "demo"), newTiny("bob", "train"),
+			newKeysSecret("alice", alicePublic), newKeysSecret("bob", bobPublic, alicePublic),
+		),
+		KeysSecretName: DefaultKeysSecretName,
+	}
+	cases := []struct {
+		user     string
+		key      ssh.Signer
+		expected string
+	}{
+		{"train", alice, "bob/train"},
+		{"train", bob, "bob/train"},
+		{"demo", bob, "bob/demo"},
+		{"demo.alice", alice, "alice/demo"},
+		{"demo", alice, ""},
+		{"demo.alice", bob, ""},
+		{"missing", alice, ""},
+	}
+	for _, c := range cases {
+		target, err := s.resolve(context.TODO(), c.user, c.key.PublicKey())
+		if c.expected == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %s", c.user, target)
+			}
+			continue
+		}
+		if err != nil || target.String() != c.expected {
+			t.Errorf("%s: expected %s, got %s, %v", c.user, c.expected, target, err)
+		}
+	}
+}
+
+// serveUpstream 模拟 Unit 中的 sshd，exec 请求输出 "hello <command>"
+func serveUpstream(t *testing.T, hostKey ssh.Signer, authorized ssh.PublicKey) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(authorized.Marshal()) {
+				return nil, ssh.ErrNoAuth
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, channels, requests, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(requests)
+				for newChannel := range channels {
+					channel, requests, err := newChannel.Accept()
+					if err != nil {
+						continue
+					}
+					go func() {
+						defer channel.Close()
+						for req := range requests {
+							if req.Type != "exec" {
+								_ = req.Reply(false, nil)
+								continue
+							}
+							_ = req.Reply(true, nil)
+							_, _ = channel.Write([]byte("hello " + string(req.Payload[4:])))
+							_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+							return
+						}
+					}()
+				}
+			}()
+		}
+	}()
+	return listener
+}
+
+func TestServerProxy(t *testing.T) {
+	gatewayKey, _ := newSigner(t, GenerateKey)
+	upstreamKey, _ := newSigner(t, GenerateKey)
+	unitKey, unitPublic := newSigner(t, GenerateHostKey)
+	user, userPublic := newSigner(t, GenerateKey)
+
+	upstream := serveUpstream(t, unitKey, upstreamKey.PublicKey())
+	defer upstream.Close()
+	port, _ := strconv.Atoi(upstream.Addr().(*net.TCPAddr).String()[len("127.0.0.1:"):])
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "demo"},
+		Spec: v1.ServiceSpec{
+			ClusterIP: "127.0.0.1",
+			Ports:     []v1.ServicePort{{Name: SSH, Port: int32(port)}},
+		},
+	}
+	hostKeySecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: HostKeySecretName("demo")},
+		Data:       map[string][]byte{HostPublicKeyKey: unitPublic},
+	}
+
+	run := func(hostKeySecret *v1.Secret) (string, error) {
+		s := &Server{
+			Client:      newReader(newTiny("alice", "demo"), newKeysSecret("alice", userPublic), service, hostKeySecret),
+			HostKey:     gatewayKey,
+			UpstreamKey: upstreamKey,
+		}
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		go func() { _ = s.Serve(ctx, listener) }()
+
+		conn, err := ssh.Dial("tcp", listener.Addr().String(), &ssh.ClientConfig{
+			User:            "demo",
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(user)},
+			HostKeyCallback: ssh.FixedHostKey(gatewayKey.PublicKey()),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		session, err := conn.NewSession()
+		if err != nil {
+			return "", err
+		}
+		defer session.Close()
+		output, err := session.Output("whoami")
+		return string(output), err
+	}
+
+	output, err := run(hostKeySecret)
+	if err != nil || output != "hello whoami" {
+		t.Fatalf("expected the command to be proxied, got %q, %v", output, err)
+	}
+
+	// Unit 出示的主机密钥与 Secret 不一致时拒绝连接
+	_, otherPublic := newSigner(t, GenerateHostKey)
+	forged := hostKeySecret.DeepCopy()
+	forged.Data[HostPublicKeyKey] = otherPublic
+	if _, err := run(forged); err == nil || !strings.Contains(err.Error(), "not reachable") {
+		t.Errorf("expected an unknown host key to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+#!/usr/bin/env bash
+
+# Generates the typed clientset, listers and informers under client/.
+# Requires client-gen, lister-gen and informer-gen from k8s.io/code-generator:
+#   go install k8s.io/code-generator/cmd/{client-gen,lister-gen,informer-gen}@v0.22.1
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+MODULE=github.com/cokeos/zero
+OUTPUT=${MODULE}/client
+ROOT=$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)
+BOILERPLATE=${ROOT}/hack/boilerplate.go.txt
+TMP=$(mktemp -d)
+trap 'rm -rf "${TMP}"' EXIT
+
+# client-gen treats a group directory named "api" as the legacy core group,
+# so generate from a copy of api/v1 laid out as apis/core/v1.
+SRC=${TMP}/src
+mkdir -p "${SRC}/apis/core"
+cp "${ROOT}/go.mod" "${ROOT}/go.sum" "${SRC}/"
+cp -r "${ROOT}/api/v1" "${SRC}/apis/core/v1"
+APIS=${MODULE}/apis/core/v1
+
+cd "${SRC}"
+
+client-gen \
+  --clientset-name versioned \
+  --input-base "" \
+  --input "${APIS}" \
+  --output-package "${OUTPUT}/clientset" \
+  --output-base "${TMP}/out" \
+  --go-header-file "${BOILERPLATE}"
+
+lister-gen \
+  --input-dirs "${APIS}" \
+  --output-package "${OUTPUT}/listers" \
+  --output-base "${TMP}/out" \
+  --go-header-file "${BOILERPLATE}"
+
+informer-gen \
+  --input-dirs "${APIS}" \
+  --versioned-clientset-package "${OUTPUT}/clientset/versioned" \
+  --listers-package "${OUTPUT}/listers" \
+  --output-package "${OUTPUT}/informers" \
+  --output-base "${TMP}/out" \
+  --go-header-file "${BOILERPLATE}"
+
+find "${TMP}/out" -name '*.go' -exec sed -i "s#\"${APIS}\"#\"${MODULE}/api/v1\"#" {} +
+
+rm -rf "${ROOT}/client/clientset" "${ROOT}/client/listers" "${ROOT}/client/informers"
+cp -r "${TMP}/out/${OUTPUT}/." "${ROOT}/client/"
+gofmt -w "${ROOT}/client"
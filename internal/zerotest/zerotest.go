@@ -0,0 +1,33 @@
+// Package zerotest 提供单元测试共用的 scheme 与 fake client
+package zerotest
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+)
+
+// Scheme 注册了 Kubernetes 内置类型与 core.cokeos.io/v1，测试中只读使用
+var Scheme = NewScheme()
+
+// NewScheme 返回注册了 Kubernetes 内置类型与 core.cokeos.io/v1 的 scheme
+func NewScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	return scheme
+}
+
+// NewClientBuilder 返回使用 Scheme 的 fake client builder
+func NewClientBuilder() *fake.ClientBuilder {
+	return fake.NewClientBuilder().WithScheme(Scheme)
+}
+
+// NewClient 返回预先包含 objs 的 fake client
+func NewClient(objs ...client.Object) client.Client {
+	return NewClientBuilder().WithObjects(objs...).Build()
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logarchive 保存 Unit 结束或被删除前的容器日志，存放在工作区或 S3 兼容的对象存储中
+package logarchive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// DefaultMountPath Unit 中工作区的挂载路径
+	DefaultMountPath = "/data"
+	// LogDir 工作区中保存日志的目录
+	LogDir = ".zero/logs"
+
+	DefaultRegion = "us-east-1"
+)
+
+// Store 日志的存储位置
+type Store interface {
+	// Put 保存命名空间 namespace 中 Unit unit 名为 name 的日志，返回日志位置
+	Put(ctx context.Context, namespace, unit, name string, data []byte) (string, error)
+}
+
+// WorkspaceStore 将日志写入命名空间的工作区，用户可在 Unit 中直接读取
+//
+// 控制器需要将节点上的共享工作区根目录挂载到 Root，使用项目 PVC 作为工作区的命名空间无法写入。
+type WorkspaceStore struct {
+	// Root 控制器中共享工作区根目录的路径，其下每个命名空间一个目录
+	Root string
+	// MountPath Unit 中工作区的挂载路径，为空时为 /data
+	MountPath string
+}
+
+// Put 从命名空间目录起逐级打开日志目录且不跟随符号链接，日志目录由用户控制，
+// 其中的符号链接不能把控制器的写入重定向到命名空间之外；重新归档时先删除同名文件再新建，不写入用户放置的链接
+func (s *WorkspaceStore) Put(ctx context.Context, namespace, unit, name string, data []byte) (string, error) {
+	for _, element := range []string{namespace, unit, name} {
+		if element == "" || element == "." || element == ".." || strings.ContainsRune(element, '/') {
+			return "", fmt.Errorf("invalid path element %q", element)
+		}
+	}
+	// 命名空间目录本身作为工作区挂载到 Unit 中，用户无法替换
+	if err := os.MkdirAll(filepath.Join(s.Root, namespace), 0755); err != nil {
+		return "", err
+	}
+	dir, err := unix.Open(filepath.Join(s.Root, namespace), unix.O_RDONLY|unix.O_DIRECTORY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return "", &os.PathError{Op: "open", Path: filepath.Join(s.Root, namespace), Err: err}
+	}
+	defer func() { unix.Close(dir) }()
+	for _, element := range append(strings.Split(LogDir, "/"), unit) {
+		next, err := openDir(dir, element)
+		if err != nil {
+			return "", &os.PathError{Op: "open", Path: filepath.Join(s.Root, namespace, LogDir, unit), Err: err}
+		}
+		unix.Close(dir)
+		dir = next
+	}
+	if err := unix.Unlinkat(dir, name, 0); err != nil && err != unix.ENOENT {
+		return "", &os.PathError{Op: "unlink", Path: filepath.Join(s.Root, namespace, LogDir, unit, name), Err: err}
+	}
+	fd, err := unix.Openat(dir, name, unix.O_WRONLY|unix.O_CREAT|unix.O_EXCL|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0644)
+	if err != nil {
+		return "", &os.PathError{Op: "open", Path: filepath.Join(s.Root, namespace, LogDir, unit, name), Err: err}
+	}
+	file := os.NewFile(uintptr(fd), filepath.Join(s.Root, namespace, LogDir, unit, name))
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	mountPath := s.MountPath
+	if mountPath == "" {
+		mountPath = DefaultMountPath
+	}
+	return path.Join(mountPath, LogDir, unit, name), nil
+}
+
+// openDir 打开 dir 下的目录 name，不存在时创建，name 为符号链接时返回 ELOOP
+func openDir(dir int, name string) (int, error) {
+	if err := unix.Mkdirat(dir, name, 0755); err != nil && err != unix.EEXIST {
+		return -1, err
+	}
+	return unix.Openat(dir, name, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+}
+
+// S3Store 将日志上传到 S3 兼容的对象存储，键为 <Prefix>/<namespace>/<unit>/<name>
+type S3Store struct {
+	Client *minio.Client
+	Bucket string
+	Prefix string
+}
+
+// S3Options 连接对象存储的参数，凭据为空时从环境变量 AWS_ACCESS_KEY_ID 与 AWS_SECRET_ACCESS_KEY 读取
+type S3Options struct {
+	Endpoint        string
+	Bucket          string
+	Prefix          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Insecure        bool
+}
+
+func NewS3Store(options S3Options) (*S3Store, error) {
+	creds := credentials.NewEnvAWS()
+	if options.AccessKeyID != "" {
+		creds = credentials.NewStaticV4(options.AccessKeyID, options.SecretAccessKey, "")
+	}
+	region := options.Region
+	if region == "" {
+		region = DefaultRegion
+	}
+	client, err := minio.New(options.Endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: !options.Insecure,
+		// 指定区域，避免每次上传前查询存储桶所在区域
+		Region: region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &S3Store{Client: client, Bucket: options.Bucket, Prefix: options.Prefix}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, namespace, unit, name string, data []byte) (string, error) {
+	key := path.Join(s.Prefix, namespace, unit, name)
+	_, err := s.Client.PutObject(ctx, s.Bucket, key, bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: "text/plain; charset=utf-8"})
+	if err != nil {
+		return "", err
+	}
+	return "s3://" + s.Bucket + "/" + key, nil
+}
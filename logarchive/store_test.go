@@ -0,0 +1,138 @@
+package logarchive
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// fakeS3 代替 MinIO，只支持上传与下载单个对象
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.objects[r.URL.Path] = data
+		w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+	case http.MethodGet:
+		data, ok := s.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewTLSServer(fake)
+	defer server.Close()
+	endpoint, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := minio.New(endpoint.Host, &minio.Options{
+		Creds:     credentials.NewStaticV4("minio", "minio123", ""),
+		Secure:    true,
+		Region:    DefaultRegion,
+		Transport: server.Client().Transport,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &S3Store{Client: client, Bucket: "logs", Prefix: "zero"}
+	location, err := store.Put(context.TODO(), "alice", "train", "20211019-090000-1a2b3c4d.log", []byte("epoch 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location != "s3://logs/zero/alice/train/20211019-090000-1a2b3c4d.log" {
+		t.Errorf("unexpected location %s", location)
+	}
+	if data := fake.objects["/logs/zero/alice/train/20211019-090000-1a2b3c4d.log"]; string(data) != "epoch 1\n" {
+		t.Errorf("unexpected object %q", data)
+	}
+}
+
+func TestWorkspaceStore(t *testing.T) {
+	root := t.TempDir()
+	store := &WorkspaceStore{Root: root}
+	location, err := store.Put(context.TODO(), "alice", "train", "20211019-090000-1a2b3c4d.log", []byte("epoch 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location != "/data/.zero/logs/train/20211019-090000-1a2b3c4d.log" {
+		t.Errorf("unexpected location %s", location)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "alice", ".zero", "logs", "train", "20211019-090000-1a2b3c4d.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "epoch 1\n" {
+		t.Errorf("unexpected logs %q", data)
+	}
+}
+
+func TestWorkspaceStoreRejectsSymlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	store := &WorkspaceStore{Root: root}
+	if err := os.MkdirAll(filepath.Join(root, "alice"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "alice", ".zero")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Put(context.TODO(), "alice", "train", "1.log", []byte("epoch 1\n")); err == nil {
+		t.Error("expected a symlinked log directory to be rejected")
+	}
+
+	// 用户指向命名空间之外的同名文件被替换而不是写入
+	if err := os.Remove(filepath.Join(root, "alice", ".zero")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "alice", ".zero", "logs", "train"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(outside, "passwd")
+	if err := os.WriteFile(target, []byte("root\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, filepath.Join(root, "alice", ".zero", "logs", "train", "1.log")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Put(context.TODO(), "alice", "train", "1.log", []byte("epoch 1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "root\n" {
+		t.Errorf("expected the file outside the workspace to be kept, got %q", data)
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 1 {
+		t.Errorf("expected nothing written outside the workspace, got %v", entries)
+	}
+
+	if _, err := store.Put(context.TODO(), "alice", "../bob", "1.log", nil); err == nil {
+		t.Error("expected an invalid Unit name to be rejected")
+	}
+}
@@ -18,12 +18,26 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"github.com/cokeos/zero/controllers/gateway"
+	"github.com/cokeos/zero/controllers/idle"
+	"github.com/cokeos/zero/controllers/metrics"
+	"github.com/cokeos/zero/controllers/project"
+	"github.com/cokeos/zero/controllers/scheduledunit"
+	"github.com/cokeos/zero/controllers/snapshot"
 	"github.com/cokeos/zero/controllers/tiny"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/cokeos/zero/audit"
+	zeroconfig "github.com/cokeos/zero/controllers/config"
 	"github.com/cokeos/zero/controllers/tunnel"
+	"github.com/cokeos/zero/logarchive"
 
 	"github.com/cokeos/zero/controllers/unit"
+	"github.com/cokeos/zero/controllers/usage"
+	"github.com/cokeos/zero/webhooks"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -31,12 +45,17 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
 	corev1 "github.com/cokeos/zero/api/v1"
+	corev1v2 "github.com/cokeos/zero/api/v2"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -49,34 +68,86 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(corev1v2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
 func main() {
+	var configFile string
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var allowedRegistries string
+	var trustedUsers string
+	var logFormat string
+	flag.StringVar(&configFile, "config", "",
+		"The path of the ZeroConfig file. Flags set on the command line take precedence over the file.")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&allowedRegistries, "allowed-registries", "",
+		"Comma separated registries (or registry prefixes) custom Unit images may be pulled from. Any registry is allowed if empty.")
+	flag.StringVar(&trustedUsers, "trusted-users", webhooks.DefaultTrustedUser,
+		"Comma-separated users allowed to set the cokeos.io/created-by annotation on behalf of others.")
+	flag.StringVar(&logFormat, "log-format", "console",
+		"The log format, console for development or json for production. Use --zap-log-level=debug or 2 for more verbose logs.")
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
-	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	logOpts := []zap.Opts{zap.UseFlagOptions(&opts)}
+	switch logFormat {
+	case "console":
+	case "json":
+		// 生产模式默认输出 Info 及以上，Error 才附带调用栈
+		opts.Development = false
+		logOpts = append(logOpts, zap.JSONEncoder())
+	default:
+		fmt.Fprintf(os.Stderr, "unknown log format %q\n", logFormat)
+		os.Exit(1)
+	}
+	logger := zap.New(logOpts...)
+	ctrl.SetLogger(logger)
+	// client-go 等依赖仍使用 klog，转到同一个日志以保持格式一致
+	klog.SetLogger(logger.WithName("klog"))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
-		MetricsBindAddress:     metricsAddr,
-		Port:                   9443,
-		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "d4987a89.cokeos.io",
+	// 命令行中显式指定的参数覆盖配置文件
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	store, err := zeroconfig.NewStore(configFile, func(c *configv1alpha1.ZeroConfig) {
+		if set["allowed-registries"] {
+			c.Admission.AllowedRegistries = splitList(allowedRegistries)
+		}
+		if set["trusted-users"] {
+			c.Admission.TrustedUsers = splitList(trustedUsers)
+		}
 	})
+	if err != nil {
+		setupLog.Error(err, "unable to load config", "path", configFile)
+		os.Exit(1)
+	}
+	options, err := ctrl.Options{Scheme: scheme}.AndFrom(store.Get())
+	if err != nil {
+		setupLog.Error(err, "unable to load config", "path", configFile)
+		os.Exit(1)
+	}
+	if set["metrics-bind-address"] {
+		options.MetricsBindAddress = metricsAddr
+	}
+	if set["health-probe-bind-address"] {
+		options.HealthProbeBindAddress = probeAddr
+	}
+	if set["leader-elect"] {
+		options.LeaderElection = enableLeaderElection
+	}
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
@@ -85,29 +156,209 @@ func main() {
 	// 终止信号
 	stopCh := ctrl.SetupSignalHandler()
 
+	// 除 Admission 与 SyncPeriods 外的配置只在启动时读取
+	c := store.Get()
+
+	var logArchiver *unit.LogArchiver
+	if c.LogArchive.Store != "" {
+		var archive logarchive.Store
+		switch c.LogArchive.Store {
+		case "workspace":
+			archive = &logarchive.WorkspaceStore{Root: c.LogArchive.Root}
+		case "s3":
+			if archive, err = logarchive.NewS3Store(logarchive.S3Options{
+				Endpoint: c.LogArchive.S3.Endpoint,
+				Bucket:   c.LogArchive.S3.Bucket,
+				Prefix:   c.LogArchive.S3.Prefix,
+				Region:   c.LogArchive.S3.Region,
+				Insecure: c.LogArchive.S3.Insecure,
+			}); err != nil {
+				setupLog.Error(err, "unable to create log archive")
+				os.Exit(1)
+			}
+		}
+		logArchiver = &unit.LogArchiver{
+			Kubernetes: kubernetes.NewForConfigOrDie(mgr.GetConfig()),
+			Store:      archive,
+		}
+	}
 	if err = (&unit.UnitReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr, stopCh); err != nil {
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    mgr.GetEventRecorderFor("unit-controller"),
+		LogArchiver: logArchiver,
+		Config:      store,
+	}).SetupWithManager(stopCh, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Unit")
 		os.Exit(1)
 	}
 	if err = (&tunnel.TunnelReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("tunnel-controller"),
+		Config:   store,
+	}).SetupWithManager(stopCh, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Tunnel")
+		os.Exit(1)
+	}
+	var tinyGateway *tiny.Gateway
+	if c.Gateway.SSH {
+		gatewayReconciler := &gateway.GatewayReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+			Config: store,
+		}
+		if err = gatewayReconciler.SetupWithManager(stopCh, mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Gateway")
+			os.Exit(1)
+		}
+		tinyGateway = &tiny.Gateway{
+			Host:          c.Gateway.Host,
+			Port:          c.Gateway.Port,
+			AuthorizedKey: gatewayReconciler.AuthorizedKey(),
+		}
+	}
+	tinyReconciler := &tiny.TinyReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Gateway:  tinyGateway,
+		Recorder: mgr.GetEventRecorderFor("tiny-controller"),
+		Config:   store,
+	}
+	if err = tinyReconciler.SetupWithManager(stopCh, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Tiny")
+		os.Exit(1)
+	}
+	if err = metrics.RegisterNodePortPool(tinyReconciler.UsedPorts, tinyReconciler.PortPoolSize); err != nil {
+		setupLog.Error(err, "unable to register metrics", "metrics", "NodePortPool")
+		os.Exit(1)
+	}
+	if err = metrics.RegisterStateCollector(mgr.GetClient()); err != nil {
+		setupLog.Error(err, "unable to register metrics", "metrics", "State")
+		os.Exit(1)
+	}
+	if err = (&snapshot.SnapshotReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr, stopCh); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Tunnel")
+		Config: store,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Snapshot")
 		os.Exit(1)
 	}
-	if err = (&tiny.TinyReconciler{
+	if err = (&scheduledunit.ScheduledUnitReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("scheduledunit-controller"),
+		Config:   store,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ScheduledUnit")
+		os.Exit(1)
+	}
+	if err = (&project.ProjectReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		Config: store,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Tiny")
+		setupLog.Error(err, "unable to create controller", "controller", "Project")
 		os.Exit(1)
 	}
+	if c.Idle.MetricsSource != "" {
+		var source idle.MetricsSource
+		switch c.Idle.MetricsSource {
+		case "prometheus":
+			source = idle.NewPrometheusSource(c.Idle.PrometheusAddress)
+		case "sidecar":
+			source = idle.NewSidecarSource(int(c.Idle.SidecarPort))
+		}
+		if err = (&idle.IdleReconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor("idle-controller"),
+			Source:   source,
+			Config:   store,
+		}).SetupWithManager(stopCh, mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Idle")
+			os.Exit(1)
+		}
+	}
+	if c.Usage.Sink != "" {
+		// 时区已在加载配置时校验
+		location, _ := time.LoadLocation(c.Usage.Timezone)
+		var sink usage.Sink
+		switch c.Usage.Sink {
+		case "configmap":
+			// 区间直接读写 API Server，避免缓存全部 ConfigMap
+			cmClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+			if err != nil {
+				setupLog.Error(err, "unable to create usage client")
+				os.Exit(1)
+			}
+			sink = &usage.ConfigMapSink{Client: cmClient, Namespace: c.Usage.Namespace}
+		case "sqlite":
+			if sink, err = usage.NewSQLiteSink(c.Usage.Path, location); err != nil {
+				setupLog.Error(err, "unable to open usage database", "path", c.Usage.Path)
+				os.Exit(1)
+			}
+		case "csv":
+			sink = &usage.CSVSink{Path: c.Usage.Path}
+		}
+		if err = (&usage.UsageReconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Sink:     sink,
+			Location: location,
+			Config:   store,
+		}).SetupWithManager(stopCh, mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Usage")
+			os.Exit(1)
+		}
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&webhooks.UnitValidator{
+			Config: store,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Unit")
+			os.Exit(1)
+		}
+		if err = (&webhooks.ProjectValidator{
+			Config: store,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Project")
+			os.Exit(1)
+		}
+		if err = (&webhooks.CreatorAnnotator{
+			Config: store,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Creator")
+			os.Exit(1)
+		}
+		if err = webhooks.SetupConversionWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Conversion")
+			os.Exit(1)
+		}
+		auditor := &webhooks.Auditor{}
+		if c.Audit.Sink != "" {
+			sink, err := audit.NewSink(c.Audit.Sink)
+			if err != nil {
+				setupLog.Error(err, "unable to open audit sink", "sink", c.Audit.Sink)
+				os.Exit(1)
+			}
+			auditor.Logger = audit.NewLogger(sink, audit.DefaultBufferSize)
+		}
+		if err = auditor.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Audit")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
+	if configFile != "" {
+		if err = mgr.Add(store); err != nil {
+			setupLog.Error(err, "unable to watch config", "path", configFile)
+			os.Exit(1)
+		}
+	}
+
 	if err = mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -123,3 +374,14 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// splitList 解析逗号分隔的参数，忽略空项
+func splitList(value string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
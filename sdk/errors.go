@@ -0,0 +1,103 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// ErrQuotaExceeded 超出命名空间配额，Pod 无法创建
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrUnschedulable 没有满足资源要求的节点
+	ErrUnschedulable = errors.New("unschedulable")
+	// ErrImagePullFailed 镜像拉取失败
+	ErrImagePullFailed = errors.New("image pull failed")
+	// ErrUnitFailed Unit 的 Pod 以失败结束
+	ErrUnitFailed = errors.New("unit failed")
+)
+
+// reasons 状态条件的原因与错误的对应关系
+var reasons = map[string]error{
+	corev1.ReasonQuotaExceeded:   ErrQuotaExceeded,
+	corev1.ReasonUnschedulable:   ErrUnschedulable,
+	corev1.ReasonImagePullFailed: ErrImagePullFailed,
+	string(v1.PodFailed):         ErrUnitFailed,
+}
+
+// UnitError Unit 无法运行的原因，可以用 errors.Is 与 ErrQuotaExceeded 等比较
+type UnitError struct {
+	Namespace string
+	Name      string
+	// Reason 状态条件的原因
+	Reason string
+	// Message 状态条件的说明
+	Message string
+
+	err error
+}
+
+func (e *UnitError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("unit %s/%s: %s", e.Namespace, e.Name, e.err)
+	}
+	return fmt.Sprintf("unit %s/%s: %s: %s", e.Namespace, e.Name, e.err, e.Message)
+}
+
+func (e *UnitError) Unwrap() error {
+	return e.err
+}
+
+func IsQuotaExceeded(err error) bool {
+	return errors.Is(err, ErrQuotaExceeded)
+}
+
+func IsUnschedulable(err error) bool {
+	return errors.Is(err, ErrUnschedulable)
+}
+
+func IsImagePullFailed(err error) bool {
+	return errors.Is(err, ErrImagePullFailed)
+}
+
+func IsUnitFailed(err error) bool {
+	return errors.Is(err, ErrUnitFailed)
+}
+
+// UnitStatusError 根据 Unit 的状态条件返回阻止其运行的错误，没有时返回 nil
+func UnitStatusError(unit *corev1.Unit) error {
+	for _, t := range []string{corev1.ConditionPodCreated, corev1.ConditionPodScheduled, corev1.ConditionImagePulled} {
+		if err := conditionError(unit.Namespace, unit.Name, meta.FindStatusCondition(unit.Status.Conditions, t)); err != nil {
+			return err
+		}
+	}
+	if unit.Status.Phase == v1.PodFailed {
+		return &UnitError{Namespace: unit.Namespace, Name: unit.Name, Reason: string(v1.PodFailed), err: ErrUnitFailed}
+	}
+	return nil
+}
+
+// TinyStatusError 根据 Tiny 的 UnitReady 条件返回阻止其 Unit 运行的错误，没有时返回 nil
+func TinyStatusError(tiny *corev1.Tiny) error {
+	return conditionError(tiny.Namespace, tiny.Name, meta.FindStatusCondition(tiny.Status.Conditions, corev1.TinyUnitReady))
+}
+
+func conditionError(namespace, name string, condition *metav1.Condition) error {
+	if condition == nil || condition.Status != metav1.ConditionFalse {
+		return nil
+	}
+	err, ok := reasons[condition.Reason]
+	if !ok {
+		return nil
+	}
+	return &UnitError{Namespace: namespace, Name: name, Reason: condition.Reason, Message: condition.Message, err: err}
+}
+
+// fatal 等待时立即返回的错误，Unschedulable 可能随节点扩容恢复，只在超时时返回
+func fatal(err error) bool {
+	return err != nil && !errors.Is(err, ErrUnschedulable)
+}
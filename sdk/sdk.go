@@ -0,0 +1,121 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sdk 在生成的 clientset 之上提供创建并等待 Tiny、等待与监听 Unit、日志及其归档与执行命令等常用操作
+package sdk
+
+import (
+	"context"
+	"io"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/client/clientset/versioned"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// Client zero 的高层客户端，字段可直接替换为 fake clientset 以便测试
+type Client struct {
+	Zero       versioned.Interface
+	Kubernetes kubernetes.Interface
+	// Config 建立 exec 连接使用的集群配置
+	Config *rest.Config
+}
+
+// New 使用集群配置创建 Client
+func New(config *rest.Config) (*Client, error) {
+	zero, err := versioned.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{Zero: zero, Kubernetes: clientset, Config: config}, nil
+}
+
+// StreamLogs 返回 Unit Pod 的日志流，调用方负责关闭
+func (c *Client) StreamLogs(ctx context.Context, namespace, name string, options *v1.PodLogOptions) (io.ReadCloser, error) {
+	if options == nil {
+		options = &v1.PodLogOptions{}
+	}
+	// Unit 的 Pod 与容器均与其同名
+	if options.Container == "" {
+		options.Container = name
+	}
+	return c.Kubernetes.CoreV1().Pods(namespace).GetLogs(name, options).Stream(ctx)
+}
+
+// LogArchives 返回 Unit 已归档的日志，按归档时间从早到晚排列，Pod 删除后可据此找回日志
+//
+// 位置为 /data 开头的工作区路径时可在同一命名空间的 Unit 中读取，为 s3:// 时使用对象存储的客户端下载。
+func (c *Client) LogArchives(ctx context.Context, namespace, name string) ([]corev1.LogArchive, error) {
+	unit, err := c.Zero.ZeroV1().Units(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return unit.Status.LogArchives, nil
+}
+
+// ExecOptions 在 Unit 中执行命令的输入输出，stdin 为空时不传递标准输入
+type ExecOptions struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	TTY    bool
+}
+
+// ExecInUnit 在 Unit 的容器中执行命令并等待其结束，命令以非零状态退出时返回 exec.CodeExitError
+func (c *Client) ExecInUnit(ctx context.Context, namespace, name string, command []string, options ExecOptions) error {
+	req := c.Kubernetes.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(name).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: name,
+			Command:   command,
+			Stdin:     options.Stdin != nil,
+			Stdout:    options.Stdout != nil,
+			Stderr:    options.Stderr != nil && !options.TTY,
+			TTY:       options.TTY,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(c.Config, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- executor.Stream(remotecommand.StreamOptions{
+			Stdin:  options.Stdin,
+			Stdout: options.Stdout,
+			Stderr: options.Stderr,
+			Tty:    options.TTY,
+		})
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		// client-go 的 Stream 不支持取消，连接在命令结束后由其自行关闭
+		return ctx.Err()
+	}
+}
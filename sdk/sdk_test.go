@@ -0,0 +1,252 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	zerofake "github.com/cokeos/zero/client/clientset/versioned/fake"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestClient(objs ...*corev1.Unit) *Client {
+	zero := zerofake.NewSimpleClientset()
+	for _, obj := range objs {
+		if err := zero.Tracker().Add(obj); err != nil {
+			panic(err)
+		}
+	}
+	return &Client{Zero: zero, Kubernetes: kubefake.NewSimpleClientset()}
+}
+
+func newUnit(phase v1.PodPhase, conditions ...metav1.Condition) *corev1.Unit {
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "train"}}
+	unit.Status.Phase = phase
+	unit.Status.Conditions = conditions
+	return unit
+}
+
+// updateLater 在监听建立后更新 Unit 的状态
+func updateLater(t *testing.T, c *Client, unit *corev1.Unit) {
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		if _, err := c.Zero.ZeroV1().Units(unit.Namespace).UpdateStatus(context.TODO(), unit, metav1.UpdateOptions{}); err != nil {
+			t.Error(err)
+		}
+	}()
+}
+
+func TestWaitForUnitPhase(t *testing.T) {
+	c := newTestClient(newUnit(v1.PodPending))
+	updateLater(t, c, newUnit(v1.PodRunning))
+	unit, err := c.WaitForUnitPhase(context.TODO(), "default", "train", v1.PodRunning, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unit.Status.Phase != v1.PodRunning {
+		t.Errorf("expected Running, got %q", unit.Status.Phase)
+	}
+
+	// 已经处于目标阶段时立即返回
+	if _, err := c.WaitForUnitPhase(context.TODO(), "default", "train", v1.PodRunning, time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitForUnitPhaseErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		unit      *corev1.Unit
+		is        func(error) bool
+		immediate bool
+	}{
+		{
+			name: "quota exceeded",
+			unit: newUnit("", metav1.Condition{Type: corev1.ConditionPodCreated, Status: metav1.ConditionFalse,
+				Reason: corev1.ReasonQuotaExceeded, Message: "exceeded quota: gpu"}),
+			is:        IsQuotaExceeded,
+			immediate: true,
+		},
+		{
+			name: "image pull failed",
+			unit: newUnit(v1.PodPending, metav1.Condition{Type: corev1.ConditionImagePulled, Status: metav1.ConditionFalse,
+				Reason: corev1.ReasonImagePullFailed, Message: "ErrImagePull"}),
+			is:        IsImagePullFailed,
+			immediate: true,
+		},
+		{
+			name:      "failed",
+			unit:      newUnit(v1.PodFailed),
+			is:        IsUnitFailed,
+			immediate: true,
+		},
+		{
+			name: "unschedulable",
+			unit: newUnit(v1.PodPending, metav1.Condition{Type: corev1.ConditionPodScheduled, Status: metav1.ConditionFalse,
+				Reason: corev1.ReasonUnschedulable, Message: "0/3 nodes are available"}),
+			is: IsUnschedulable,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestClient(test.unit)
+			start := time.Now()
+			_, err := c.WaitForUnitPhase(context.TODO(), "default", "train", v1.PodRunning, 200*time.Millisecond)
+			if !test.is(err) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			unitErr := &UnitError{}
+			if !errors.As(err, &unitErr) || unitErr.Name != "train" {
+				t.Errorf("expected a UnitError, got %#v", err)
+			}
+			if elapsed := time.Since(start); test.immediate != (elapsed < 200*time.Millisecond) {
+				t.Errorf("immediate %v, returned after %v", test.immediate, elapsed)
+			}
+		})
+	}
+}
+
+func TestWaitForUnitPhaseTimeout(t *testing.T) {
+	c := newTestClient(newUnit(v1.PodPending))
+	_, err := c.WaitForUnitPhase(context.TODO(), "default", "train", v1.PodRunning, 50*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
+
+func TestWatchUnit(t *testing.T) {
+	c := newTestClient(newUnit(v1.PodPending))
+	ctx, cancel := context.WithCancel(context.TODO())
+	events, err := c.WatchUnit(ctx, "default", "train")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := <-events
+	if e.Type != watch.Added || e.Unit.Status.Phase != v1.PodPending {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	failed := newUnit(v1.PodPending, metav1.Condition{Type: corev1.ConditionImagePulled, Status: metav1.ConditionFalse,
+		Reason: corev1.ReasonImagePullFailed})
+	updateLater(t, c, failed)
+	e = <-events
+	if e.Type != watch.Modified || !IsImagePullFailed(e.Err) {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+// TestWaitForUnitPhaseExpired 第一次监听的版本过期，期间的变化由重新列出补发
+func TestWaitForUnitPhaseExpired(t *testing.T) {
+	c := newTestClient(newUnit(v1.PodPending))
+	zero := c.Zero.(*zerofake.Clientset)
+	var once sync.Once
+	zero.PrependWatchReactor("units", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		handled := false
+		once.Do(func() { handled = true })
+		if !handled {
+			return false, nil, nil
+		}
+		w := watch.NewFake()
+		go func() {
+			if _, err := c.Zero.ZeroV1().Units("default").UpdateStatus(context.TODO(), newUnit(v1.PodRunning), metav1.UpdateOptions{}); err != nil {
+				t.Error(err)
+			}
+			w.Error(&metav1.Status{
+				Status:  metav1.StatusFailure,
+				Code:    http.StatusGone,
+				Reason:  metav1.StatusReasonExpired,
+				Message: "too old resource version",
+			})
+		}()
+		return true, w, nil
+	})
+
+	unit, err := c.WaitForUnitPhase(context.TODO(), "default", "train", v1.PodRunning, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unit.Status.Phase != v1.PodRunning {
+		t.Errorf("expected Running, got %q", unit.Status.Phase)
+	}
+}
+
+func TestCreateTinyAndWait(t *testing.T) {
+	c := newTestClient()
+	tiny := &corev1.Tiny{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "demo"}}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		ready := tiny.DeepCopy()
+		ready.Status.SSHCommand = "ssh -p 30022 root@10.0.0.1"
+		meta.SetStatusCondition(&ready.Status.Conditions, metav1.Condition{
+			Type: corev1.TinyReady, Status: metav1.ConditionTrue, Reason: "Ready",
+		})
+		if _, err := c.Zero.ZeroV1().Tinies("default").UpdateStatus(context.TODO(), ready, metav1.UpdateOptions{}); err != nil {
+			t.Error(err)
+		}
+	}()
+	created, err := c.CreateTinyAndWait(context.TODO(), tiny, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Status.SSHCommand == "" {
+		t.Error("expected the ready tiny to be returned")
+	}
+
+	quota := &corev1.Tiny{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "quota"}}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		blocked := quota.DeepCopy()
+		meta.SetStatusCondition(&blocked.Status.Conditions, metav1.Condition{
+			Type: corev1.TinyUnitReady, Status: metav1.ConditionFalse, Reason: corev1.ReasonQuotaExceeded,
+		})
+		if _, err := c.Zero.ZeroV1().Tinies("default").UpdateStatus(context.TODO(), blocked, metav1.UpdateOptions{}); err != nil {
+			t.Error(err)
+		}
+	}()
+	if _, err := c.CreateTinyAndWait(context.TODO(), quota, 5*time.Second); !IsQuotaExceeded(err) {
+		t.Errorf("expected QuotaExceeded, got %v", err)
+	}
+}
+
+func TestStreamLogs(t *testing.T) {
+	c := newTestClient()
+	stream, err := c.StreamLogs(context.TODO(), "default", "train", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	data, err := ioutil.ReadAll(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "fake logs" {
+		t.Errorf("unexpected logs %q", data)
+	}
+}
+
+func TestLogArchives(t *testing.T) {
+	unit := newUnit(v1.PodSucceeded)
+	unit.Status.LogArchives = []corev1.LogArchive{{Location: "s3://logs/default/train/20211019-090000-1a2b3c4d.log", PodUID: "1a2b3c4d"}}
+	c := newTestClient(unit)
+	archives, err := c.LogArchives(context.TODO(), "default", "train")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archives) != 1 || archives[0].Location != unit.Status.LogArchives[0].Location {
+		t.Errorf("unexpected archives %+v", archives)
+	}
+}
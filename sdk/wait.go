@@ -0,0 +1,305 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// UnitEvent Unit 的变化
+type UnitEvent struct {
+	// Type 为 Added、Modified、Deleted，或监听出错时为 Error
+	Type watch.EventType
+	// Unit 变化后的 Unit，Error 事件时为 nil
+	Unit *corev1.Unit
+	// Err Error 事件的错误，或 Unit 状态条件中阻止其运行的错误
+	Err error
+}
+
+// WatchUnit 监听 Unit 的变化，先发送一次当前状态，ctx 结束或监听出错后关闭 channel
+func (c *Client) WatchUnit(ctx context.Context, namespace, name string) (<-chan UnitEvent, error) {
+	units := c.Zero.ZeroV1().Units(namespace)
+	raw, err := watchNamed(ctx, name,
+		func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+			return units.List(ctx, options)
+		},
+		func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+			return units.Watch(ctx, options)
+		})
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan UnitEvent)
+	go func() {
+		defer close(events)
+		for e := range raw {
+			event := UnitEvent{Type: e.Type}
+			if e.Type == watch.Error {
+				event.Err = apierrors.FromObject(e.Object)
+			} else {
+				event.Unit = e.Object.(*corev1.Unit)
+				event.Err = UnitStatusError(event.Unit)
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// WaitForUnitPhase 等待 Unit 进入 phase
+//
+// 超出配额、镜像拉取失败或 Pod 失败时立即返回对应的 UnitError；
+// 无法调度可能随节点扩容恢复，超时后才返回 ErrUnschedulable。
+func (c *Client) WaitForUnitPhase(ctx context.Context, namespace, name string, phase v1.PodPhase, timeout time.Duration) (*corev1.Unit, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	events, err := c.WatchUnit(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	var last *corev1.Unit
+	for e := range events {
+		switch e.Type {
+		case watch.Error:
+			return last, e.Err
+		case watch.Deleted:
+			return nil, apierrors.NewNotFound(corev1.Resource("units"), name)
+		}
+		last = e.Unit
+		if last.Status.Phase == phase {
+			return last, nil
+		}
+		if fatal(e.Err) {
+			return last, e.Err
+		}
+	}
+	var statusErr error
+	if last != nil {
+		statusErr = UnitStatusError(last)
+	}
+	return last, timeoutError(ctx, fmt.Sprintf("unit %s/%s to be %s", namespace, name, phase), statusErr)
+}
+
+// CreateTinyAndWait 创建 Tiny 并等待其 Ready，返回可以连接的 Tiny
+//
+// 错误的判定与 WaitForUnitPhase 相同，来自 Tiny 的 UnitReady 条件。
+func (c *Client) CreateTinyAndWait(ctx context.Context, tiny *corev1.Tiny, timeout time.Duration) (*corev1.Tiny, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	tinies := c.Zero.ZeroV1().Tinies(tiny.Namespace)
+	created, err := tinies.Create(ctx, tiny, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	raw, err := watchNamed(ctx, created.Name,
+		func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+			return tinies.List(ctx, options)
+		},
+		func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+			return tinies.Watch(ctx, options)
+		})
+	if err != nil {
+		return created, err
+	}
+	last := created
+	for e := range raw {
+		switch e.Type {
+		case watch.Error:
+			return last, apierrors.FromObject(e.Object)
+		case watch.Deleted:
+			return nil, apierrors.NewNotFound(corev1.Resource("tinies"), created.Name)
+		}
+		last = e.Object.(*corev1.Tiny)
+		if meta.IsStatusConditionTrue(last.Status.Conditions, corev1.TinyReady) {
+			return last, nil
+		}
+		if err := TinyStatusError(last); fatal(err) {
+			return last, err
+		}
+	}
+	return last, timeoutError(ctx, fmt.Sprintf("tiny %s/%s to be ready", last.Namespace, last.Name), TinyStatusError(last))
+}
+
+// timeoutError 等待超时时优先返回状态条件中的错误
+func timeoutError(ctx context.Context, waiting string, statusErr error) error {
+	if statusErr != nil {
+		return statusErr
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out waiting for %s: %w", waiting, ctx.Err())
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("watch closed while waiting for %s", waiting)
+}
+
+type listFunc func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error)
+
+type watchFunc func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error)
+
+// watchNamed 监听指定名称的资源，先以 Added 事件发送当前状态，连接断开后从最后的版本继续监听
+//
+// 版本过期（410 Gone）时与 UntilWithSync 一样重新列出并监听，补发期间错过的变化，
+// 不会把错误交给调用方。fake clientset 不支持 FieldSelector，列表与事件都会再按名称过滤。
+func watchNamed(ctx context.Context, name string, list listFunc, watchFn watchFunc) (<-chan watch.Event, error) {
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+	last, resourceVersion, err := listNamed(ctx, name, selector, list)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan watch.Event)
+	send := func(e watch.Event) bool {
+		select {
+		case events <- e:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	go func() {
+		defer close(events)
+		if last != nil && !send(watch.Event{Type: watch.Added, Object: last}) {
+			return
+		}
+		for ctx.Err() == nil {
+			w, err := watchFn(ctx, metav1.ListOptions{
+				FieldSelector:       selector,
+				ResourceVersion:     resourceVersion,
+				AllowWatchBookmarks: true,
+			})
+			if err == nil {
+				err = forward(ctx, w, name, &resourceVersion, &last, send)
+			}
+			if err == nil || ctx.Err() != nil {
+				continue
+			}
+			if !isExpired(err) {
+				status := statusOf(err)
+				send(watch.Event{Type: watch.Error, Object: &status})
+				return
+			}
+
+			current, currentVersion, err := listNamed(ctx, name, selector, list)
+			if err != nil {
+				if ctx.Err() == nil {
+					status := statusOf(err)
+					send(watch.Event{Type: watch.Error, Object: &status})
+				}
+				return
+			}
+			resourceVersion = currentVersion
+			if e, changed := resync(last, current); changed && !send(e) {
+				return
+			}
+			last = current
+		}
+	}()
+	return events, nil
+}
+
+// listNamed 列出指定名称的资源，返回资源（不存在时为 nil）与列表的版本
+func listNamed(ctx context.Context, name, selector string, list listFunc) (runtime.Object, string, error) {
+	current, err := list(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, "", err
+	}
+	resourceVersion, err := meta.NewAccessor().ResourceVersion(current)
+	if err != nil {
+		return nil, "", err
+	}
+	items, err := meta.ExtractList(current)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, item := range items {
+		if accessor, err := meta.Accessor(item); err == nil && accessor.GetName() == name {
+			return item, resourceVersion, nil
+		}
+	}
+	return nil, resourceVersion, nil
+}
+
+// resync 比较重新列出前后的资源，返回补发的事件与是否需要补发
+func resync(last, current runtime.Object) (watch.Event, bool) {
+	switch {
+	case current == nil && last == nil:
+		return watch.Event{}, false
+	case current == nil:
+		return watch.Event{Type: watch.Deleted, Object: last}, true
+	case last == nil:
+		return watch.Event{Type: watch.Added, Object: current}, true
+	}
+	lastVersion, _ := meta.NewAccessor().ResourceVersion(last)
+	currentVersion, _ := meta.NewAccessor().ResourceVersion(current)
+	if lastVersion != "" && lastVersion == currentVersion {
+		return watch.Event{}, false
+	}
+	return watch.Event{Type: watch.Modified, Object: current}, true
+}
+
+// isExpired 监听的版本已被压缩，需要重新列出
+func isExpired(err error) bool {
+	return apierrors.IsResourceExpired(err) || apierrors.IsGone(err)
+}
+
+// forward 转发一次监听的事件并记录最后的版本与资源，连接断开时返回 nil，否则返回监听结束的原因
+func forward(ctx context.Context, w watch.Interface, name string, resourceVersion *string, last *runtime.Object, send func(watch.Event) bool) error {
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return nil
+			}
+			if e.Type == watch.Error {
+				return apierrors.FromObject(e.Object)
+			}
+			accessor, err := meta.Accessor(e.Object)
+			if err != nil {
+				continue
+			}
+			*resourceVersion = accessor.GetResourceVersion()
+			if e.Type == watch.Bookmark || accessor.GetName() != name {
+				continue
+			}
+			*last = e.Object
+			if e.Type == watch.Deleted {
+				*last = nil
+			}
+			if !send(e) {
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+func statusOf(err error) metav1.Status {
+	if status, ok := err.(apierrors.APIStatus); ok {
+		return status.Status()
+	}
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusInternalServerError,
+		Reason:  metav1.StatusReasonInternalError,
+		Message: err.Error(),
+	}
+}
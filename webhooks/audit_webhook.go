@@ -0,0 +1,180 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/audit"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const ValidateAuditPath = "/validate-core-cokeos-io-v1-audit"
+
+//+kubebuilder:webhook:path=/validate-core-cokeos-io-v1-audit,mutating=false,failurePolicy=ignore,sideEffects=NoneOnDryRun,groups=core.cokeos.io,resources=units;tinies;tunnels,verbs=create;update;delete,versions=v1,name=vaudit.cokeos.io,admissionReviewVersions=v1
+
+// Auditor 总是放行请求，同时将 Unit、Tiny 与 Tunnel 的变更写入审计日志
+//
+// 审计 webhook 在所有修改型 webhook 之后调用，因此创建记录中带有创建者注解。
+// 请求随后仍可能被其他校验拒绝，webhook 只暂存请求，informer 观察到对象确实变化后才记为 committed。
+type Auditor struct {
+	// Logger 为空时不记录
+	Logger *audit.Logger
+}
+
+// SetupWithManager registers the validating webhook with the Manager.
+func (a *Auditor) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(ValidateAuditPath, &webhook.Admission{Handler: a})
+	if a.Logger == nil {
+		return nil
+	}
+	// 每个副本都处理准入请求，各自的缓存都能观察到变更
+	for _, obj := range []client.Object{&corev1.Unit{}, &corev1.Tiny{}, &corev1.Tunnel{}} {
+		informer, err := mgr.GetCache().GetInformer(context.TODO(), obj)
+		if err != nil {
+			return err
+		}
+		informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    a.observeAdd,
+			UpdateFunc: a.observeUpdate,
+			DeleteFunc: a.observeDelete,
+		})
+	}
+	return mgr.Add(a.Logger)
+}
+
+func (a *Auditor) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if a.Logger == nil || (req.DryRun != nil && *req.DryRun) {
+		return admission.Allowed("")
+	}
+	record, ok := auditRecord(req)
+	if ok {
+		record.Time = a.Logger.Clock.Now()
+		a.Logger.Attempt(record)
+	}
+	return admission.Allowed("")
+}
+
+func (a *Auditor) observeAdd(obj interface{}) {
+	if o, ok := obj.(client.Object); ok {
+		a.Logger.Commit(o.GetUID(), audit.ActionCreate)
+	}
+}
+
+// observeUpdate 只有 spec 或元数据的变化对应用户的更新，status 的变化不经过审计 webhook
+func (a *Auditor) observeUpdate(oldObj, newObj interface{}) {
+	old, ok := oldObj.(client.Object)
+	if !ok {
+		return
+	}
+	obj, ok := newObj.(client.Object)
+	if !ok {
+		return
+	}
+	// 带有 finalizer 的对象被删除时先设置 deletionTimestamp
+	if old.GetDeletionTimestamp() == nil && obj.GetDeletionTimestamp() != nil {
+		a.Logger.Commit(obj.GetUID(), audit.ActionDelete)
+	}
+	if old.GetGeneration() == obj.GetGeneration() &&
+		equality.Semantic.DeepEqual(old.GetLabels(), obj.GetLabels()) &&
+		equality.Semantic.DeepEqual(old.GetAnnotations(), obj.GetAnnotations()) &&
+		equality.Semantic.DeepEqual(old.GetFinalizers(), obj.GetFinalizers()) &&
+		equality.Semantic.DeepEqual(old.GetOwnerReferences(), obj.GetOwnerReferences()) {
+		return
+	}
+	action := audit.ActionUpdate
+	if oldUnit, ok := old.(*corev1.Unit); ok {
+		action = updateAction(oldUnit.Spec.Suspend, obj.(*corev1.Unit).Spec.Suspend)
+	}
+	a.Logger.Commit(obj.GetUID(), action)
+}
+
+func (a *Auditor) observeDelete(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if o, ok := obj.(client.Object); ok {
+		a.Logger.Commit(o.GetUID(), audit.ActionDelete)
+	}
+}
+
+// updateAction Unit 的 spec.suspend 变化记为挂起或恢复
+func updateAction(wasSuspended, suspend bool) audit.Action {
+	if suspend && !wasSuspended {
+		return audit.ActionSuspend
+	} else if !suspend && wasSuspended {
+		return audit.ActionResume
+	}
+	return audit.ActionUpdate
+}
+
+// auditRecord 删除请求只有 OldObject，Unit 的 spec.suspend 变化记为挂起或恢复
+func auditRecord(req admission.Request) (audit.Record, bool) {
+	record := audit.Record{
+		Kind:       req.Kind.Kind,
+		Namespace:  req.Namespace,
+		Name:       req.Name,
+		User:       req.UserInfo.Username,
+		Groups:     req.UserInfo.Groups,
+		RequestUID: req.UID,
+	}
+	var (
+		obj, old *unstructured.Unstructured
+		err      error
+	)
+	switch req.Operation {
+	case admissionv1.Create:
+		record.Action = audit.ActionCreate
+		obj, err = decodeObject(req.Object.Raw)
+	case admissionv1.Update:
+		record.Action = audit.ActionUpdate
+		if obj, err = decodeObject(req.Object.Raw); err != nil {
+			return record, false
+		}
+		if old, err = decodeObject(req.OldObject.Raw); err != nil {
+			return record, false
+		}
+		if req.Kind.Kind == "Unit" {
+			suspend, _, _ := unstructured.NestedBool(obj.Object, "spec", "suspend")
+			wasSuspended, _, _ := unstructured.NestedBool(old.Object, "spec", "suspend")
+			record.Action = updateAction(wasSuspended, suspend)
+		}
+	case admissionv1.Delete:
+		record.Action = audit.ActionDelete
+		obj, err = decodeObject(req.OldObject.Raw)
+	default:
+		return record, false
+	}
+	if err != nil {
+		return record, false
+	}
+	// 使用 generateName 创建时请求中没有名称
+	if record.Name == "" {
+		record.Name = obj.GetName()
+	}
+	record.UID = obj.GetUID()
+	record.CreatedBy = obj.GetAnnotations()[corev1.CreatedByAnnotation]
+	return record, true
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	corev1 "github.com/cokeos/zero/api/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SetupConversionWithManager 注册 /convert，Unit、Tiny 与 Tunnel 以 v1 存储，通过该 webhook 与 v2 互相转换
+//
+// Manager 的 Scheme 中需要注册 v1 与 v2。
+func SetupConversionWithManager(mgr ctrl.Manager) error {
+	for _, hub := range []client.Object{&corev1.Unit{}, &corev1.Tiny{}, &corev1.Tunnel{}} {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(hub).Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
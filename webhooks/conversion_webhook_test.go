@@ -0,0 +1,73 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	corev1v2 "github.com/cokeos/zero/api/v2"
+	apix "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+func TestConvertTunnel(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1v2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	wh := &conversion.Webhook{}
+	if err := wh.InjectScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	tunnel := &corev1.Tunnel{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "core.cokeos.io/v1", Kind: "Tunnel"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "alice", Name: "train"},
+		Spec:       corev1.TunnelSpec{UnitName: "alice.train"},
+	}
+	data, err := json.Marshal(tunnel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	review := &apix.ConversionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+		Request: &apix.ConversionRequest{
+			UID:               "req",
+			DesiredAPIVersion: "core.cokeos.io/v2",
+			Objects:           []runtime.RawExtension{{Raw: data}},
+		},
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	wh.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/convert", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+
+	result := &apix.ConversionReview{}
+	if err := json.Unmarshal(w.Body.Bytes(), result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Response.Result.Status != metav1.StatusSuccess || len(result.Response.ConvertedObjects) != 1 {
+		t.Fatalf("unexpected response %+v", result.Response)
+	}
+	converted := &corev1v2.Tunnel{}
+	if err := json.Unmarshal(result.Response.ConvertedObjects[0].Raw, converted); err != nil {
+		t.Fatal(err)
+	}
+	if converted.APIVersion != "core.cokeos.io/v2" ||
+		converted.Spec.UnitRef != (corev1v2.UnitReference{Namespace: "alice", Name: "train"}) {
+		t.Errorf("unexpected tunnel %+v", converted)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	configv1alpha1 "github.com/cokeos/zero/api/config/v1alpha1"
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/controllers/config"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	MutateCreatorPath = "/mutate-core-cokeos-io-v1-creator"
+
+	// DefaultTrustedUser 控制器的服务账号，由 Tiny 创建 Unit 与 Tunnel 时沿用 Tiny 的创建者
+	DefaultTrustedUser = configv1alpha1.DefaultTrustedUser
+)
+
+//+kubebuilder:webhook:path=/mutate-core-cokeos-io-v1-creator,mutating=true,failurePolicy=fail,sideEffects=None,groups=core.cokeos.io,resources=units;tinies;tunnels;scheduledunits,verbs=create;update,versions=v1,name=mcreator.cokeos.io,admissionReviewVersions=v1
+
+// CreatorAnnotator 创建 Unit、Tiny、Tunnel 与 ScheduledUnit 时将请求者写入 cokeos.io/created-by 注解，更新时保持原值
+type CreatorAnnotator struct {
+	// Config 当前生效的配置，其中的 TrustedUsers 可以代替其他用户设置创建者，其余用户设置的注解会被覆盖
+	Config *config.Store
+}
+
+// SetupWithManager registers the mutating webhook with the Manager.
+func (a *CreatorAnnotator) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(MutateCreatorPath, &webhook.Admission{Handler: a})
+	return nil
+}
+
+func (a *CreatorAnnotator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj, err := decodeObject(req.Object.Raw)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	current := obj.GetAnnotations()[corev1.CreatedByAnnotation]
+
+	var want string
+	switch req.Operation {
+	case admissionv1.Create:
+		want = req.UserInfo.Username
+		if current != "" && a.trusted(req.UserInfo.Username) {
+			want = current
+		}
+	case admissionv1.Update:
+		old, err := decodeObject(req.OldObject.Raw)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		want = old.GetAnnotations()[corev1.CreatedByAnnotation]
+		// 本功能上线前创建的对象没有创建者，只允许受信任的用户补上
+		if want == "" && current != "" && a.trusted(req.UserInfo.Username) {
+			want = current
+		}
+	default:
+		return admission.Allowed("")
+	}
+	if want == current {
+		return admission.Allowed("")
+	}
+
+	annotations := obj.GetAnnotations()
+	if want == "" {
+		delete(annotations, corev1.CreatedByAnnotation)
+	} else {
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[corev1.CreatedByAnnotation] = want
+	}
+	obj.SetAnnotations(annotations)
+	marshaled, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+func (a *CreatorAnnotator) trusted(user string) bool {
+	for _, trusted := range a.Config.Get().Admission.TrustedUsers {
+		if user == trusted {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeObject 不要求 apiVersion 与 kind，只用于读写 metadata 与少量字段
+func decodeObject(raw []byte) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(raw, &obj.Object); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
@@ -0,0 +1,171 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/audit"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newRequest(t *testing.T, op admissionv1.Operation, user string, obj, old runtime.Object) admission.Request {
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		UID:       "req",
+		Kind:      metav1.GroupVersionKind{Group: "core.cokeos.io", Version: "v1", Kind: "Unit"},
+		Namespace: "alice",
+		Name:      "train",
+		Operation: op,
+		UserInfo:  authenticationv1.UserInfo{Username: user},
+	}}
+	for raw, o := range map[*runtime.RawExtension]runtime.Object{&req.Object: obj, &req.OldObject: old} {
+		if o == nil {
+			continue
+		}
+		data, err := json.Marshal(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw.Raw = data
+	}
+	return req
+}
+
+func newUnit(createdBy string, suspend bool) *corev1.Unit {
+	unit := &corev1.Unit{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "alice",
+		Name:        "train",
+		UID:         "uid",
+		Annotations: map[string]string{"description": "train"},
+	}}
+	if createdBy != "" {
+		unit.Annotations[corev1.CreatedByAnnotation] = createdBy
+	}
+	unit.Spec.Suspend = suspend
+	return unit
+}
+
+func TestCreatorAnnotator(t *testing.T) {
+	a := &CreatorAnnotator{}
+	cases := []struct {
+		name  string
+		req   admission.Request
+		patch string
+	}{
+		{"create", newRequest(t, admissionv1.Create, "alice", newUnit("", false), nil), "alice"},
+		{"spoofed", newRequest(t, admissionv1.Create, "alice", newUnit("bob", false), nil), "alice"},
+		{"on behalf", newRequest(t, admissionv1.Create, DefaultTrustedUser, newUnit("bob", false), nil), ""},
+		{"update keeps", newRequest(t, admissionv1.Update, "bob", newUnit("bob", false), newUnit("alice", false)), "alice"},
+		{"update unchanged", newRequest(t, admissionv1.Update, "bob", newUnit("alice", true), newUnit("alice", false)), ""},
+	}
+	for _, c := range cases {
+		resp := a.Handle(context.TODO(), c.req)
+		if !resp.Allowed {
+			t.Errorf("%s: expected allowed, got %+v", c.name, resp.Result)
+			continue
+		}
+		if c.patch == "" {
+			if len(resp.Patches) != 0 {
+				t.Errorf("%s: expected no patch, got %+v", c.name, resp.Patches)
+			}
+			continue
+		}
+		if len(resp.Patches) != 1 || resp.Patches[0].Value != c.patch {
+			t.Errorf("%s: expected created-by %s, got %+v", c.name, c.patch, resp.Patches)
+		}
+	}
+}
+
+type recordSink struct {
+	records []audit.Record
+}
+
+func (s *recordSink) Write(record audit.Record) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *recordSink) Close() error {
+	return nil
+}
+
+func TestAuditRecord(t *testing.T) {
+	cases := []struct {
+		req    admission.Request
+		action audit.Action
+	}{
+		{newRequest(t, admissionv1.Create, "alice", newUnit("alice", false), nil), audit.ActionCreate},
+		{newRequest(t, admissionv1.Update, "alice", newUnit("alice", true), newUnit("alice", false)), audit.ActionSuspend},
+		{newRequest(t, admissionv1.Update, "alice", newUnit("alice", false), newUnit("alice", true)), audit.ActionResume},
+		{newRequest(t, admissionv1.Update, "alice", newUnit("alice", false), newUnit("alice", false)), audit.ActionUpdate},
+		{newRequest(t, admissionv1.Delete, "admin", nil, newUnit("alice", false)), audit.ActionDelete},
+	}
+	for _, c := range cases {
+		record, ok := auditRecord(c.req)
+		if !ok || record.Action != c.action || record.CreatedBy != "alice" || record.UID != "uid" ||
+			record.User != c.req.UserInfo.Username || record.RequestUID != "req" {
+			t.Errorf("expected %s, got %+v", c.action, record)
+		}
+	}
+
+	// DryRun 请求不记录
+	sink := &recordSink{}
+	logger := audit.NewLogger(sink, 1)
+	dryRun := true
+	req := newRequest(t, admissionv1.Create, "alice", newUnit("alice", false), nil)
+	req.DryRun = &dryRun
+	if resp := (&Auditor{Logger: logger}).Handle(context.TODO(), req); !resp.Allowed {
+		t.Fatalf("expected allowed, got %+v", resp.Result)
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if err := logger.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.records) != 0 {
+		t.Errorf("expected no records for dry run, got %+v", sink.records)
+	}
+}
+
+func TestAuditorObserve(t *testing.T) {
+	sink := &recordSink{}
+	a := &Auditor{Logger: audit.NewLogger(sink, 10)}
+	unit := newUnit("alice", false)
+	suspended := newUnit("alice", true)
+	suspended.Generation = 2
+	for _, req := range []admission.Request{
+		newRequest(t, admissionv1.Create, "alice", unit, nil),
+		newRequest(t, admissionv1.Update, "alice", suspended, unit),
+		newRequest(t, admissionv1.Delete, "admin", nil, suspended),
+	} {
+		if resp := a.Handle(context.TODO(), req); !resp.Allowed {
+			t.Fatalf("expected allowed, got %+v", resp.Result)
+		}
+	}
+
+	a.observeAdd(unit)
+	// 控制器更新 status 不确认用户的请求
+	status := unit.DeepCopy()
+	status.Status.NodeName = "gpu-1"
+	a.observeUpdate(unit, status)
+	a.observeUpdate(status, suspended)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if err := a.Logger.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	outcomes := map[audit.Action]audit.Outcome{}
+	for _, record := range sink.records {
+		outcomes[record.Action] = record.Outcome
+	}
+	if len(sink.records) != 3 || outcomes[audit.ActionCreate] != audit.OutcomeCommitted ||
+		outcomes[audit.ActionSuspend] != audit.OutcomeCommitted || outcomes[audit.ActionDelete] != audit.OutcomeUnknown {
+		t.Errorf("unexpected records %+v", sink.records)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/controllers/config"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	ValidateProjectPath = "/validate-core-cokeos-io-v1-project"
+
+	// ReservedNamespacePrefix Kubernetes 系统组件使用的命名空间前缀
+	ReservedNamespacePrefix = "kube-"
+)
+
+//+kubebuilder:webhook:path=/validate-core-cokeos-io-v1-project,mutating=false,failurePolicy=fail,sideEffects=None,groups=core.cokeos.io,resources=projects,verbs=create;update,versions=v1,name=vproject.cokeos.io,admissionReviewVersions=v1
+
+// ProjectValidator 拒绝使用保留命名空间的 Project，并禁止修改已创建 Project 的命名空间
+type ProjectValidator struct {
+	// Config 当前生效的配置，其中的 ReservedNamespaces 热加载后立即生效
+	Config *config.Store
+
+	decoder *admission.Decoder
+}
+
+// SetupWithManager registers the validating webhook with the Manager.
+func (v *ProjectValidator) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(ValidateProjectPath, &webhook.Admission{Handler: v})
+	return nil
+}
+
+func (v *ProjectValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation == admissionv1.Delete {
+		return admission.Allowed("")
+	}
+	project := &corev1.Project{}
+	if err := v.decoder.Decode(req, project); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	namespace := projectNamespace(project)
+	if req.Operation == admissionv1.Update {
+		old := &corev1.Project{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if namespace != projectNamespace(old) {
+			return admission.Denied("spec.namespace is immutable")
+		}
+		return admission.Allowed("")
+	}
+	if msgs := validation.IsDNS1123Label(namespace); len(msgs) > 0 {
+		return admission.Denied("spec.namespace: " + strings.Join(msgs, ", "))
+	}
+	if reserved(v.Config.Get().Admission.ReservedNamespaces, namespace) {
+		return admission.Denied("namespace " + namespace + " is reserved")
+	}
+	return admission.Allowed("")
+}
+
+func (v *ProjectValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func projectNamespace(project *corev1.Project) string {
+	if project.Spec.Namespace != "" {
+		return project.Spec.Namespace
+	}
+	return project.Name
+}
+
+// reserved 命名空间是否为系统保留
+func reserved(namespaces []string, namespace string) bool {
+	if strings.HasPrefix(namespace, ReservedNamespacePrefix) {
+		return true
+	}
+	for _, n := range namespaces {
+		if n == namespace {
+			return true
+		}
+	}
+	return false
+}
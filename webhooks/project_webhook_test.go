@@ -0,0 +1,54 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newProject(name, namespace string) *corev1.Project {
+	return &corev1.Project{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.ProjectSpec{Namespace: namespace},
+	}
+}
+
+func TestProjectValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &ProjectValidator{}
+	if err := v.InjectDecoder(decoder); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name    string
+		req     admission.Request
+		allowed bool
+	}{
+		{"default namespace", newRequest(t, admissionv1.Create, "alice", newProject("vision", ""), nil), true},
+		{"custom namespace", newRequest(t, admissionv1.Create, "alice", newProject("vision", "cv-lab"), nil), true},
+		{"kube-system", newRequest(t, admissionv1.Create, "alice", newProject("vision", "kube-system"), nil), false},
+		{"kube prefix", newRequest(t, admissionv1.Create, "alice", newProject("kube-vision", ""), nil), false},
+		{"system namespace", newRequest(t, admissionv1.Create, "alice", newProject("vision", "zero-system"), nil), false},
+		{"default", newRequest(t, admissionv1.Create, "alice", newProject("default", ""), nil), false},
+		{"invalid name", newRequest(t, admissionv1.Create, "alice", newProject("vision", "CV_Lab"), nil), false},
+		{"unchanged", newRequest(t, admissionv1.Update, "alice", newProject("vision", "vision"), newProject("vision", "")), true},
+		{"moved", newRequest(t, admissionv1.Update, "alice", newProject("vision", "cv-lab"), newProject("vision", "")), false},
+	}
+	for _, c := range cases {
+		if resp := v.Handle(context.TODO(), c.req); resp.Allowed != c.allowed {
+			t.Errorf("%s: expected allowed=%v, got %+v", c.name, c.allowed, resp.Result)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	"github.com/cokeos/zero/controllers/config"
+	admissionv1 "k8s.io/api/admission/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	ValidateUnitPath = "/validate-core-cokeos-io-v1-unit"
+
+	DefaultRegistry = "docker.io"
+)
+
+//+kubebuilder:webhook:path=/validate-core-cokeos-io-v1-unit,mutating=false,failurePolicy=fail,sideEffects=None,groups=core.cokeos.io,resources=units,verbs=create;update,versions=v1,name=vunit.cokeos.io,admissionReviewVersions=v1
+
+// UnitValidator 校验 Unit 的自定义镜像是否来自允许的仓库，以及挂载的文件是否指定了唯一的来源
+type UnitValidator struct {
+	// Config 当前生效的配置，允许的仓库热加载后立即生效，为空时不限制
+	Config *config.Store
+
+	decoder *admission.Decoder
+}
+
+// SetupWithManager registers the validating webhook with the Manager.
+func (v *UnitValidator) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(ValidateUnitPath, &webhook.Admission{Handler: v})
+	return nil
+}
+
+func (v *UnitValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation == admissionv1.Delete {
+		return admission.Allowed("")
+	}
+	unit := &corev1.Unit{}
+	if err := v.decoder.Decode(req, unit); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := validateFiles(unit.Spec.Execution.Files); err != nil {
+		return admission.Denied(err.Error())
+	}
+	registries := v.Config.Get().Admission.AllowedRegistries
+	if unit.Spec.Image != "" && !allowed(registries, unit.Spec.Image) {
+		return admission.Denied("image " + unit.Spec.Image + " is not from an allowed registry: " +
+			strings.Join(registries, ", "))
+	}
+	return admission.Allowed("")
+}
+
+func (v *UnitValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// validateFiles 每个挂载的文件必须且只能来自一个 Secret 或 ConfigMap
+func validateFiles(files []corev1.FileProjection) error {
+	for i, file := range files {
+		if (file.Secret == nil) == (file.ConfigMap == nil) {
+			return fmt.Errorf("spec.execution.files[%d]: exactly one of secret and configMap must be set", i)
+		}
+	}
+	return nil
+}
+
+// allowed 镜像是否来自 registries 中的仓库，registries 为空时不限制
+func allowed(registries []string, image string) bool {
+	if len(registries) == 0 {
+		return true
+	}
+	image = normalizeImage(image)
+	for _, registry := range registries {
+		registry = strings.TrimSuffix(registry, "/")
+		if registry == "" {
+			continue
+		}
+		if strings.HasPrefix(image, registry+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeImage 为没有仓库地址的镜像补全 docker.io，与容器运行时的解析规则一致
+func normalizeImage(image string) string {
+	i := strings.Index(image, "/")
+	if i < 0 {
+		return DefaultRegistry + "/library/" + image
+	}
+	host := image[:i]
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return DefaultRegistry + "/" + image
+	}
+	return image
+}
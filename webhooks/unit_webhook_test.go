@@ -0,0 +1,76 @@
+package webhooks
+
+import (
+	"testing"
+
+	corev1 "github.com/cokeos/zero/api/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestValidateFiles(t *testing.T) {
+	secret := &v1.SecretProjection{LocalObjectReference: v1.LocalObjectReference{Name: "netrc"}}
+	configMap := &v1.ConfigMapProjection{LocalObjectReference: v1.LocalObjectReference{Name: "settings"}}
+	cases := []struct {
+		name  string
+		file  corev1.FileProjection
+		valid bool
+	}{
+		{"secret", corev1.FileProjection{MountPath: "/root/.netrc", Secret: secret}, true},
+		{"configMap", corev1.FileProjection{MountPath: "/etc/train", ConfigMap: configMap}, true},
+		{"both", corev1.FileProjection{MountPath: "/etc/train", Secret: secret, ConfigMap: configMap}, false},
+		{"neither", corev1.FileProjection{MountPath: "/etc/train"}, false},
+	}
+	for _, c := range cases {
+		err := validateFiles([]corev1.FileProjection{c.file})
+		if (err == nil) != c.valid {
+			t.Errorf("%s: expected valid=%v, got %v", c.name, c.valid, err)
+		}
+	}
+}
+
+func TestNormalizeImage(t *testing.T) {
+	cases := map[string]string{
+		"pytorch":                             "docker.io/library/pytorch",
+		"pytorch:1.9":                         "docker.io/library/pytorch:1.9",
+		"pytorch@sha256:0123":                 "docker.io/library/pytorch@sha256:0123",
+		"cokeos/zero:latest":                  "docker.io/cokeos/zero:latest",
+		"docker.io/cokeos/zero":               "docker.io/cokeos/zero",
+		"localhost/zero":                      "localhost/zero",
+		"localhost:5000/zero:v1":              "localhost:5000/zero:v1",
+		"registry.local/team/zero@sha256:ab":  "registry.local/team/zero@sha256:ab",
+		"registry.local:5000/team/zero:v1.0":  "registry.local:5000/team/zero:v1.0",
+		"ccr.ccs.tencentyun.com/njupt-isl/pt": "ccr.ccs.tencentyun.com/njupt-isl/pt",
+	}
+	for image, expected := range cases {
+		if got := normalizeImage(image); got != expected {
+			t.Errorf("normalizeImage(%q) = %q, expected %q", image, got, expected)
+		}
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	registries := []string{"registry.local/", "localhost:5000", "docker.io/library"}
+	cases := []struct {
+		image   string
+		allowed bool
+	}{
+		{"registry.local/team/zero:v1", true},
+		{"registry.local/team/zero@sha256:ab", true},
+		{"localhost:5000/zero", true},
+		{"pytorch:1.9", true},
+		{"pytorch@sha256:0123", true},
+		{"docker.io/library/pytorch", true},
+		{"cokeos/zero:latest", false},
+		{"registry.local.evil.com/zero", false},
+		{"localhost:5001/zero", false},
+		{"quay.io/cokeos/zero", false},
+	}
+	for _, c := range cases {
+		if got := allowed(registries, c.image); got != c.allowed {
+			t.Errorf("allowed(%q) = %v, expected %v", c.image, got, c.allowed)
+		}
+	}
+	if !allowed(nil, "cokeos/zero") {
+		t.Error("expected every image to be allowed without registries")
+	}
+}